@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// KafkaAdmin manages the cluster administration connection
+type KafkaAdmin struct {
+	client sarama.Client
+	admin  sarama.ClusterAdmin
+	config *Config
+}
+
+// BrokerInfo describes a single broker of the cluster
+type BrokerInfo struct {
+	ID   int32
+	Addr string
+	Rack string
+}
+
+// ClusterInfo summarizes the cluster the application is connected to
+type ClusterInfo struct {
+	ClusterID    string
+	ControllerID int32
+	Brokers      []BrokerInfo
+	APIVersions  []sarama.ApiVersionsResponseKey
+	TopicCount   int
+}
+
+// adminKafkaVersion is the protocol version used by the cluster admin.
+// Incremental config updates require Kafka 2.3 or newer.
+var adminKafkaVersion = sarama.V2_3_0_0
+
+// NewKafkaAdmin creates a new cluster admin using the same connection
+// settings as the producer
+func NewKafkaAdmin(config *Config) (*KafkaAdmin, error) {
+	saramaConfig, err := newSaramaConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	saramaConfig.Version = adminKafkaVersion
+
+	client, err := sarama.NewClient(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to create cluster admin: %w", err)
+	}
+
+	return &KafkaAdmin{
+		client: client,
+		admin:  admin,
+		config: config,
+	}, nil
+}
+
+// Ping checks that the controller of the cluster is reachable
+func (a *KafkaAdmin) Ping() error {
+	_, err := a.client.Controller()
+	return err
+}
+
+// ClusterInfo fetches cluster metadata and the API versions supported by
+// the controller
+func (a *KafkaAdmin) ClusterInfo() (*ClusterInfo, error) {
+	controller, err := a.client.Controller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find controller: %w", err)
+	}
+
+	metadata, err := controller.GetMetadata(sarama.NewMetadataRequest(a.client.Config().Version, nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+	}
+
+	info := &ClusterInfo{ControllerID: metadata.ControllerID}
+	if metadata.ClusterID != nil {
+		info.ClusterID = *metadata.ClusterID
+	}
+
+	for _, broker := range metadata.Brokers {
+		info.Brokers = append(info.Brokers, BrokerInfo{
+			ID:   broker.ID(),
+			Addr: broker.Addr(),
+			Rack: broker.Rack(),
+		})
+	}
+	sort.Slice(info.Brokers, func(i, j int) bool { return info.Brokers[i].ID < info.Brokers[j].ID })
+
+	for _, topic := range metadata.Topics {
+		if !topic.IsInternal {
+			info.TopicCount++
+		}
+	}
+
+	versions, err := controller.ApiVersions(&sarama.ApiVersionsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch API versions: %w", err)
+	}
+	info.APIVersions = versions.ApiKeys
+
+	return info, nil
+}
+
+// Close closes the admin connection and its underlying client
+func (a *KafkaAdmin) Close() error {
+	if a.admin != nil {
+		return a.admin.Close()
+	}
+	if a.client != nil {
+		return a.client.Close()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// newMockCluster starts a single mock broker that leads partition 0 of
+// each given topic and answers metadata and API version requests
+func newMockCluster(t *testing.T, topics ...string) *sarama.MockBroker {
+	t.Helper()
+
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+
+	broker.SetHandlerByMap(mockClusterHandlers(t, broker, topics...))
+
+	return broker
+}
+
+// mockClusterHandlers returns the handlers used by newMockCluster so that
+// tests can add or replace handlers for the requests they exercise
+func mockClusterHandlers(t *testing.T, broker *sarama.MockBroker, topics ...string) map[string]sarama.MockResponse {
+	metadata := sarama.NewMockMetadataResponse(t).
+		SetBroker(broker.Addr(), broker.BrokerID()).
+		SetController(broker.BrokerID())
+	for _, topic := range topics {
+		metadata.SetLeader(topic, 0, broker.BrokerID())
+	}
+
+	return map[string]sarama.MockResponse{
+		"ApiVersionsRequest":             sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest":                metadata,
+		"CreateTopicsRequest":            sarama.NewMockCreateTopicsResponse(t),
+		"DeleteTopicsRequest":            sarama.NewMockDeleteTopicsResponse(t),
+		"CreatePartitionsRequest":        sarama.NewMockCreatePartitionsResponse(t),
+		"DescribeConfigsRequest":         sarama.NewMockDescribeConfigsResponse(t),
+		"IncrementalAlterConfigsRequest": sarama.NewMockIncrementalAlterConfigsResponse(t),
+		"DeleteRecordsRequest":           sarama.NewMockDeleteRecordsResponse(t),
+	}
+}
+
+func TestNewKafkaAdmin_InvalidBrokers(t *testing.T) {
+	_, err := NewKafkaAdmin(&Config{Brokers: []string{"invalid-broker:9999999"}})
+	if err == nil {
+		t.Error("Expected error for invalid broker, got nil")
+	}
+}
+
+func TestNewKafkaAdmin_WithInvalidTLS(t *testing.T) {
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		UseAuth:  true,
+		CertFile: "nonexistent.pem",
+		KeyFile:  "nonexistent.key",
+		CAFile:   "nonexistent.ca",
+	}
+
+	_, err := NewKafkaAdmin(config)
+	if err == nil {
+		t.Error("Expected error for invalid TLS config, got nil")
+	}
+}
+
+func TestKafkaAdmin_ClusterInfo(t *testing.T) {
+	broker := newMockCluster(t, "orders", "payments")
+
+	admin, err := NewKafkaAdmin(&Config{Brokers: []string{broker.Addr()}})
+	if err != nil {
+		t.Fatalf("NewKafkaAdmin() error = %v", err)
+	}
+	defer admin.Close()
+
+	info, err := admin.ClusterInfo()
+	if err != nil {
+		t.Fatalf("ClusterInfo() error = %v", err)
+	}
+
+	if info.ControllerID != broker.BrokerID() {
+		t.Errorf("Expected controller %d, got %d", broker.BrokerID(), info.ControllerID)
+	}
+
+	if len(info.Brokers) != 1 || info.Brokers[0].Addr != broker.Addr() {
+		t.Errorf("Expected broker %s, got %+v", broker.Addr(), info.Brokers)
+	}
+
+	if info.TopicCount != 2 {
+		t.Errorf("Expected 2 topics, got %d", info.TopicCount)
+	}
+
+	if len(info.APIVersions) == 0 {
+		t.Error("Expected supported API versions")
+	}
+}
+
+func TestKafkaAdmin_Close(t *testing.T) {
+	admin := &KafkaAdmin{}
+	if err := admin.Close(); err != nil {
+		t.Errorf("Close() with nil admin error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const configUsage = `Usage:
+  kafka-producer-ui config validate
+  kafka-producer-ui config import [FILE] [--dry-run] [--output PATH]
+  kafka-producer-ui config export [--output PATH]
+
+import reads brokers, TLS and SASL settings from a Java client.properties or
+a librdkafka/kcat config (default: ~/.config/kcat.conf) into the saved
+configuration, keeping topics and serdes. Secrets are not imported. The
+previous file is kept as a .bak copy; --output writes the result to another
+config file instead and --dry-run prints it.
+
+export writes the connection settings as a Java client.properties for the
+Kafka command-line tools (default: stdout). Secrets are not exported.`
+
+// runConfigCommand handles "kafka-producer-ui config <subcommand>"
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, configUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(stdout, stderr)
+	case "import":
+		return runConfigImport(args[1:], stdout, stderr)
+	case "export":
+		return runConfigExport(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "Unknown config subcommand: %s\n", args[0])
+		return 2
+	}
+}
+
+// runConfigImport merges a client.properties or kcat.conf into the saved
+// configuration
+func runConfigImport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, configUsage) }
+	dryRun := fs.Bool("dry-run", false, "")
+	output := fs.String("output", "", "")
+
+	files, err := parseWithPositionals(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) > 1 {
+		fmt.Fprintf(stderr, "Error: expected at most one file\n%s\n", configUsage)
+		return 2
+	}
+
+	path := ""
+	if len(files) == 1 {
+		path = files[0]
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		path = filepath.Join(homeDir, ".config", "kcat.conf")
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	notes, err := importClientPropertiesFile(config, path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	for _, note := range notes {
+		fmt.Fprintf(stderr, "⚠ %s\n", note)
+	}
+
+	if *dryRun {
+		data, err := json.MarshalIndent(config.withoutSecrets(), "", "  ")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+
+	if *output != "" {
+		config.path = *output
+	}
+	backup, err := backupConfig(config.path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: failed to back up %s: %v\n", config.path, err)
+		return 1
+	}
+	if err := SaveConfig(config); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Imported connection settings from %s into %s\n", path, config.path)
+	if backup != "" {
+		fmt.Fprintf(stdout, "The previous version is saved as %s\n", backup)
+	}
+	if *output == "" {
+		fmt.Fprintln(stdout, "Run \"kafka-producer-ui config validate\" to check them")
+	}
+	return 0
+}
+
+// runConfigExport writes the saved connection settings as client.properties
+func runConfigExport(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("config export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, configUsage) }
+	output := fs.String("output", "-", "")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "Error: unexpected argument %q\n%s\n", fs.Arg(0), configUsage)
+		return 2
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	w := stdout
+	if *output != "-" {
+		file, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	if err := writeClientProperties(w, config); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runConfigValidate validates the saved configuration and prints every issue
+func runConfigValidate(stdout, stderr io.Writer) int {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	result := ValidateConfig(config)
+	for _, issue := range result {
+		if issue.Warning {
+			fmt.Fprintf(stdout, "⚠ %s\n", issue)
+		} else {
+			fmt.Fprintf(stdout, "✗ %s\n", issue)
+		}
+	}
+
+	if result.HasErrors() {
+		fmt.Fprintf(stdout, "Configuration is invalid: %d error(s), %d warning(s)\n",
+			result.ErrorCount(), len(result)-result.ErrorCount())
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "✓ Configuration is valid (%d warning(s))\n", len(result))
+	return 0
+}
+
+// runDiagnoseCommand runs the connection diagnostics against the saved
+// configuration and prints a pass/fail checklist
+func runDiagnoseCommand(stdout, stderr io.Writer) int {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	failed := 0
+	lastBroker := ""
+	RunDiagnostics(config, func(step DiagnosticStep) {
+		if step.Broker != lastBroker {
+			fmt.Fprintf(stdout, "%s\n", step.Broker)
+			lastBroker = step.Broker
+		}
+
+		switch step.Status {
+		case diagPassed:
+			fmt.Fprintf(stdout, "  ✓ %-20s %s\n", step.Name, step.Duration.Round(time.Millisecond))
+		case diagFailed:
+			failed++
+			fmt.Fprintf(stdout, "  ✗ %-20s %s\n", step.Name, step.Duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(stdout, "  - %s\n", step.Name)
+		}
+
+		for _, detail := range step.Details {
+			fmt.Fprintf(stdout, "      %s\n", detail)
+		}
+		if step.Err != nil {
+			fmt.Fprintf(stdout, "      error: %v\n", step.Err)
+		}
+	})
+
+	if failed > 0 {
+		fmt.Fprintf(stdout, "%d check(s) failed\n", failed)
+		return 1
+	}
+
+	fmt.Fprintln(stdout, "All checks passed")
+	return 0
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+const consumeUsage = `Usage:
+  kafka-producer-ui consume [TOPIC] [range] [options]
+
+TOPIC defaults to the configured topic. Matching records are printed as
+JSONL (or CSV), a summary goes to stderr.
+
+Range (default: everything currently in TOPIC):
+  --partitions 0,1       read only these partitions
+  --start-offset N       first offset to read
+  --end-offset N         stop before offset N
+  --since TIME           first message at or after TIME (RFC 3339 or Unix ms)
+  --until TIME           stop after TIME
+  --last N               last N messages of every partition
+
+Options:
+  --filter EXPR          only records matching EXPR, e.g.
+                         'key =~ "^user-" and header.source == web and $.total > 100'
+  --follow               keep reading new records until interrupted, starting
+                         with the last N (--last) records of every partition
+  --format FORMAT        jsonl (default) or csv
+  --limit N              stop after N matching records`
+
+// runConsumeCommand handles "kafka-producer-ui consume"
+func runConsumeCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("consume", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, consumeUsage) }
+
+	var rangeFlags fetchRangeFlags
+	rangeFlags.register(fs)
+	filterText := fs.String("filter", "", "")
+	follow := fs.Bool("follow", false, "")
+	format := fs.String("format", exportJSONL, "")
+	limit := fs.Int("limit", 0, "")
+
+	topics, err := parseWithPositionals(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(topics) > 1 {
+		fmt.Fprintf(stderr, "Error: expected at most one topic\n%s\n", consumeUsage)
+		return 2
+	}
+	r, err := rangeFlags.fetchRange()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	filter, err := ParseFilter(*filterText)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: invalid filter: %v\n", err)
+		return 2
+	}
+	if *format != exportJSONL && *format != exportCSV {
+		fmt.Fprintf(stderr, "Error: unknown format %q (use jsonl or csv)\n", *format)
+		return 2
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	topic := config.Topic
+	if len(topics) == 1 {
+		topic = topics[0]
+	}
+
+	exporter, err := NewExporter(*format, stdout, "", config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	consumer, err := NewKafkaConsumer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = consumer.Close() }()
+
+	scanned, matched := 0, 0
+	write := func(msg ConsumedMessage) error {
+		scanned++
+		if !filter.Match(msg) {
+			return nil
+		}
+		matched++
+		if err := exporter.Write(msg); err != nil {
+			return err
+		}
+		if *limit > 0 && matched >= *limit {
+			return errStopFetch
+		}
+		return nil
+	}
+
+	if *follow {
+		stop := make(chan struct{})
+		stopFollow := sync.OnceFunc(func() { close(stop) })
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+		go func() {
+			select {
+			case <-interrupt:
+				stopFollow()
+			case <-stop:
+			}
+		}()
+
+		var writeErr error
+		err = consumer.Tail(topic, r.LastN, stop, func(msg ConsumedMessage) {
+			if writeErr != nil {
+				return
+			}
+			if writeErr = write(msg); writeErr != nil {
+				stopFollow()
+				return
+			}
+			// Flush every record so that output can be piped while following
+			if flushErr := exporter.Flush(); flushErr != nil {
+				writeErr = flushErr
+				stopFollow()
+			}
+		})
+		if err == nil && writeErr != errStopFetch {
+			err = writeErr
+		}
+	} else {
+		err = consumer.Fetch(topic, r, write)
+	}
+
+	if closeErr := exporter.Close(); err == nil {
+		err = closeErr
+	}
+	fmt.Fprintf(stderr, "Matched %d of %d message(s) from %s\n", matched, scanned, topic)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunConsumeCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	tests := [][]string{
+		{"a", "b"},
+		{"--filter", "color == red"},
+		{"--format", "raw"},
+		{"--until", "tomorrow"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runConsumeCommand(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %v, got %d: %s", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunConsumeCommand_Filter(t *testing.T) {
+	useMockCluster(t, newFetchCluster(t))
+
+	var stdout, stderr bytes.Buffer
+	code := runConsumeCommand([]string{"events", "--filter", "$.n >= 3 or key == key-0"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"key":"key-0"`) || !strings.Contains(lines[2], `"key":"key-4"`) {
+		t.Errorf("Expected key-0, key-3 and key-4, got %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Matched 3 of 5 message(s) from events") {
+		t.Errorf("Expected summary, got %q", stderr.String())
+	}
+}
+
+func TestRunConsumeCommand_Follow(t *testing.T) {
+	useMockCluster(t, newFetchCluster(t))
+
+	var stdout, stderr bytes.Buffer
+	code := runConsumeCommand([]string{"events", "--follow", "--last", "3", "--filter", "$.n != 3", "--limit", "2"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"offset":2`) || !strings.Contains(lines[1], `"offset":4`) {
+		t.Errorf("Expected offsets 2 and 4, got %s", stdout.String())
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+const copyUsage = `Usage:
+  kafka-producer-ui copy SOURCE [TARGET] [range] [options]
+
+TARGET defaults to the configured topic.
+
+Range (default: everything currently in SOURCE):
+  --partitions 0,1       read only these partitions
+  --start-offset N       first offset to copy
+  --end-offset N         stop before offset N
+  --since TIME           first message at or after TIME (RFC 3339 or Unix ms)
+  --until TIME           stop after TIME
+  --last N               last N messages of every partition
+
+Options:
+  --filter EXPR          only messages matching EXPR, as in consume, e.g.
+                         'header.source == web and $.user.id == 42'
+  --keep-timestamps      keep the original message timestamps
+  --keep-partitions      write to the same partition numbers
+  --limit N              stop after copying N messages`
+
+// runCopyCommand handles "kafka-producer-ui copy"
+func runCopyCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, copyUsage) }
+
+	var rangeFlags fetchRangeFlags
+	rangeFlags.register(fs)
+	filterText := fs.String("filter", "", "")
+	keepTimestamps := fs.Bool("keep-timestamps", false, "")
+	keepPartitions := fs.Bool("keep-partitions", false, "")
+	limit := fs.Int("limit", 0, "")
+
+	topics, err := parseWithPositionals(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(topics) == 0 || len(topics) > 2 {
+		fmt.Fprintf(stderr, "Error: expected SOURCE and optional TARGET topic\n%s\n", copyUsage)
+		return 2
+	}
+	topics = append(topics, "")
+
+	opts := CopyOptions{
+		Source:         topics[0],
+		Target:         topics[1],
+		KeepTimestamps: *keepTimestamps,
+		KeepPartitions: *keepPartitions,
+		Limit:          *limit,
+	}
+	if opts.Range, err = rangeFlags.fetchRange(); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	if opts.Filter, err = ParseFilter(*filterText); err != nil {
+		fmt.Fprintf(stderr, "Error: invalid filter: %v\n", err)
+		return 2
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	if opts.Target == "" {
+		opts.Target = config.Topic
+	}
+	if opts.Target == opts.Source {
+		fmt.Fprintln(stderr, "Error: source and target topic must be different")
+		return 2
+	}
+
+	consumer, err := NewKafkaConsumer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = consumer.Close() }()
+
+	producer, err := NewKafkaProducer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = producer.Close() }()
+
+	fmt.Fprintf(stdout, "Copying %s → %s\n", opts.Source, opts.Target)
+	stats, err := CopyMessages(consumer, producer, opts, nil)
+	fmt.Fprintf(stdout, "Read %d, copied %d, skipped %d message(s)\n", stats.Read, stats.Copied, stats.Skipped)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// fetchRangeFlags holds the range flags shared by the commands that read
+// from a topic
+type fetchRangeFlags struct {
+	partitions  string
+	startOffset int64
+	endOffset   int64
+	since       string
+	until       string
+	lastN       int64
+}
+
+func (f *fetchRangeFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.partitions, "partitions", "", "")
+	fs.Int64Var(&f.startOffset, "start-offset", -1, "")
+	fs.Int64Var(&f.endOffset, "end-offset", -1, "")
+	fs.StringVar(&f.since, "since", "", "")
+	fs.StringVar(&f.until, "until", "", "")
+	fs.Int64Var(&f.lastN, "last", 0, "")
+}
+
+// fetchRange converts the parsed flags to a FetchRange
+func (f *fetchRangeFlags) fetchRange() (FetchRange, error) {
+	r := FetchRange{StartOffset: f.startOffset, EndOffset: f.endOffset, LastN: f.lastN}
+
+	var err error
+	if r.Partitions, err = parsePartitionList(f.partitions); err != nil {
+		return FetchRange{}, err
+	}
+	if f.since != "" {
+		if r.Since, err = parseTimestamp(f.since); err != nil {
+			return FetchRange{}, err
+		}
+	}
+	if f.until != "" {
+		if r.Until, err = parseTimestamp(f.until); err != nil {
+			return FetchRange{}, err
+		}
+	}
+	return r, nil
+}
+
+// parseWithPositionals parses fs and returns the positional arguments, which
+// may be given before, between or after the flags. Everything after "--" is
+// positional.
+func parseWithPositionals(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positionals []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if parsed := len(args) - len(rest); parsed > 0 && args[parsed-1] == "--" {
+			return append(positionals, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positionals, nil
+		}
+		positionals, args = append(positionals, rest[0]), rest[1:]
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunCopyCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	tests := [][]string{
+		nil,
+		{"a", "b", "c"},
+		{"events", "--since", "yesterday"},
+		{"events", "--partitions", "x"},
+		{"events", "--filter", "key =="},
+		{"events", "--bogus"},
+		{"test-topic"}, // target defaults to the configured topic
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runCopyCommand(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %v, got %d: %s", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunCopyCommand(t *testing.T) {
+	broker := newFetchCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"events", "events", "--last", "2"}
+	if code := runCopyCommand(args, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 when source equals target, got %d", code)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	// The mock broker has no produce handler, so reading works and sending fails
+	args = []string{"events", "--last", "2", "events-copy"}
+	code := runCopyCommand(args, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), "Copying events → events-copy") {
+		t.Errorf("Expected copy header, got %q", stdout.String())
+	}
+	if code != 1 || !strings.Contains(stderr.String(), "failed to copy events/0@3") {
+		t.Errorf("Expected send failure for the first message, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestParseWithPositionals(t *testing.T) {
+	tests := []struct {
+		args        []string
+		positionals []string
+		limit       int
+		filter      string
+	}{
+		{args: []string{"src", "dst", "--limit", "10"}, positionals: []string{"src", "dst"}, limit: 10},
+		{args: []string{"--limit", "10", "src", "dst"}, positionals: []string{"src", "dst"}, limit: 10},
+		{args: []string{"src", "--limit", "10", "dst", "--filter", "x"}, positionals: []string{"src", "dst"}, limit: 10, filter: "x"},
+		{args: []string{"src", "--", "--limit", "dst"}, positionals: []string{"src", "--limit", "dst"}},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		limit := fs.Int("limit", 0, "")
+		filter := fs.String("filter", "", "")
+
+		positionals, err := parseWithPositionals(fs, tt.args)
+		if err != nil {
+			t.Fatalf("parseWithPositionals(%v) error = %v", tt.args, err)
+		}
+		if strings.Join(positionals, " ") != strings.Join(tt.positionals, " ") || *limit != tt.limit || *filter != tt.filter {
+			t.Errorf("parseWithPositionals(%v) = %v, --limit %d, --filter %q", tt.args, positionals, *limit, *filter)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const exportUsage = `Usage:
+  kafka-producer-ui export [TOPIC] [range] [options]
+
+TOPIC defaults to the configured topic.
+
+Range (default: everything currently in TOPIC):
+  --partitions 0,1       read only these partitions
+  --start-offset N       first offset to export
+  --end-offset N         stop before offset N
+  --since TIME           first message at or after TIME (RFC 3339 or Unix ms)
+  --until TIME           stop after TIME
+  --last N               last N messages of every partition
+
+Options:
+  --format FORMAT        jsonl (default), csv or raw
+  --output PATH          output file, "-" for stdout (default); a directory for raw
+  --limit N              stop after N messages
+
+JSONL files can be sent again with "kafka-producer-ui produce --file".`
+
+// runExportCommand handles "kafka-producer-ui export"
+func runExportCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, exportUsage) }
+
+	var rangeFlags fetchRangeFlags
+	rangeFlags.register(fs)
+	format := fs.String("format", exportJSONL, "")
+	output := fs.String("output", "-", "")
+	limit := fs.Int("limit", 0, "")
+
+	topics, err := parseWithPositionals(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(topics) > 1 {
+		fmt.Fprintf(stderr, "Error: expected at most one topic\n%s\n", exportUsage)
+		return 2
+	}
+	r, err := rangeFlags.fetchRange()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	if *format == exportRaw && *output == "-" {
+		fmt.Fprintln(stderr, "Error: raw export needs --output DIR")
+		return 2
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	topic := config.Topic
+	if len(topics) == 1 {
+		topic = topics[0]
+	}
+
+	w, dir := stdout, ""
+	switch {
+	case *format == exportRaw:
+		dir = *output
+	case *output != "-":
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer func() { _ = file.Close() }()
+		w = file
+	}
+
+	exporter, err := NewExporter(*format, w, dir, config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	consumer, err := NewKafkaConsumer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = consumer.Close() }()
+
+	count := 0
+	err = consumer.Fetch(topic, r, func(msg ConsumedMessage) error {
+		if err := exporter.Write(msg); err != nil {
+			return fmt.Errorf("failed to export %s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+		count++
+		if *limit > 0 && count >= *limit {
+			return errStopFetch
+		}
+		return nil
+	})
+	if closeErr := exporter.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Keep stdout clean for the exported data
+	fmt.Fprintf(stderr, "Exported %d message(s) from %s\n", count, topic)
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestRunExportCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	tests := [][]string{
+		{"a", "b"},
+		{"--format", "raw"},
+		{"--since", "yesterday"},
+		{"--format", "xml", "--output", filepath.Join(t.TempDir(), "out")},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runExportCommand(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %v, got %d: %s", args, code, stderr.String())
+		}
+	}
+}
+
+func TestRunExportCommand(t *testing.T) {
+	useMockCluster(t, newFetchCluster(t))
+
+	var stdout, stderr bytes.Buffer
+	code := runExportCommand([]string{"events", "--start-offset", "1", "--limit", "2"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"offset":1`) || !strings.Contains(lines[1], `"key":"key-2"`) {
+		t.Errorf("Expected offsets 1 and 2 as JSONL, got %s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Exported 2 message(s) from events") {
+		t.Errorf("Expected summary on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunProduceCommand(t *testing.T) {
+	broker := newFetchCluster(t)
+	handlers := mockClusterHandlers(t, broker, "events")
+	handlers["ProduceRequest"] = sarama.NewMockProduceResponse(t)
+	broker.SetHandlerByMap(handlers)
+	useMockCluster(t, broker)
+
+	file := filepath.Join(t.TempDir(), "events.jsonl")
+	data := `{"topic":"events","partition":0,"offset":1,"key":"key-1","value":{"n":1}}
+{"value":"plain"}
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runProduceCommand([]string{"--file", file, "--topic", "events"}, nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Sent 2 message(s) to events") {
+		t.Errorf("Expected summary, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	code := runProduceCommand([]string{"--file", "-"}, strings.NewReader(`{"value":"a","value_encoding":"hex"}`), &stdout, &stderr)
+	if code != 1 || !strings.Contains(stderr.String(), "line 1: invalid value") {
+		t.Errorf("Expected error for line 1, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestRunProduceCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	for _, args := range [][]string{nil, {"--file", "a", "extra"}, {"--bogus"}} {
+		var stdout, stderr bytes.Buffer
+		if code := runProduceCommand(args, nil, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %v, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+const offsetsUsage = `Usage:
+  kafka-producer-ui offsets reset --group GROUP [--topic TOPIC] STRATEGY [--execute]
+
+Strategies:
+  --to-earliest          move to the oldest available offset
+  --to-latest            move to the end of the topic
+  --to-offset N          move to offset N
+  --to-datetime TIME     move to the first offset at or after TIME (RFC 3339 or Unix ms)
+  --shift-by N           move by N (negative to rewind)
+
+Without --execute the planned changes are only printed.`
+
+// resetStrategyFlags maps strategy flags to reset strategies
+var resetStrategyFlags = map[string]string{
+	"to-earliest": resetEarliest,
+	"to-latest":   resetLatest,
+	"to-offset":   resetOffset,
+	"to-datetime": resetTimestamp,
+	"shift-by":    resetShift,
+}
+
+// runOffsetsCommand handles "kafka-producer-ui offsets <subcommand>"
+func runOffsetsCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "reset" {
+		fmt.Fprintln(stderr, offsetsUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("offsets reset", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	group := fs.String("group", "", "consumer group to reset")
+	topic := fs.String("topic", "", "topic to reset (defaults to the configured topic)")
+	execute := fs.Bool("execute", false, "apply the changes instead of printing them")
+	fs.Bool("to-earliest", false, "move to the oldest available offset")
+	fs.Bool("to-latest", false, "move to the end of the topic")
+	fs.String("to-offset", "", "move to the given offset")
+	fs.String("to-datetime", "", "move to the first offset at or after the given time")
+	fs.String("shift-by", "", "move by the given number of messages")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	kind, value := "", ""
+	count := 0
+	fs.Visit(func(f *flag.Flag) {
+		if strategy, ok := resetStrategyFlags[f.Name]; ok {
+			kind, value = strategy, f.Value.String()
+			count++
+		}
+	})
+	if *group == "" || count != 1 {
+		fmt.Fprintf(stderr, "Error: --group and exactly one strategy are required\n%s\n", offsetsUsage)
+		return 2
+	}
+
+	strategy, err := ParseResetStrategy(kind, value)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+
+	config, admin, err := connectAdmin()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = admin.Close() }()
+
+	if *topic == "" {
+		*topic = config.Topic
+	}
+
+	if err := resetOffsets(admin, *group, *topic, strategy, *execute, stdout); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func resetOffsets(admin *KafkaAdmin, group, topic string, strategy ResetStrategy, execute bool, stdout io.Writer) error {
+	changes, err := admin.PlanOffsetReset(group, topic, strategy)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Reset of group %q on topic %q to %s:\n", group, topic, strategy)
+	if err := printOffsetChanges(changes, stdout); err != nil {
+		return err
+	}
+
+	members, err := admin.ActiveMembers(group)
+	if err != nil {
+		return err
+	}
+	if members > 0 {
+		return fmt.Errorf("group %q has %d active member(s), stop its consumers before resetting offsets", group, members)
+	}
+
+	if !execute {
+		fmt.Fprintln(stdout, "\nDry run, nothing was changed. Re-run with --execute to apply.")
+		return nil
+	}
+
+	if err := admin.ResetOffsets(group, topic, changes); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "\nReset %d partition(s)\n", len(changes))
+	return nil
+}
+
+func printOffsetChanges(changes []OffsetChange, stdout io.Writer) error {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tCURRENT\tNEW\tCHANGE\tRANGE")
+	for _, change := range changes {
+		current, delta := "-", "-"
+		if change.Current >= 0 {
+			current = fmt.Sprint(change.Current)
+			delta = fmt.Sprintf("%+d", change.Target-change.Current)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%d-%d\n", change.Partition, current, change.Target, delta, change.Low, change.High)
+	}
+	return w.Flush()
+}
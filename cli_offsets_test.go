@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestRunOffsetsCommand_Usage(t *testing.T) {
+	tests := [][]string{
+		nil,
+		{"rewind"},
+		{"reset", "--to-earliest"},
+		{"reset", "--group", "audit"},
+		{"reset", "--group", "audit", "--to-earliest", "--to-latest"},
+		{"reset", "--group", "audit", "--shift-by", "abc"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := runOffsetsCommand(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Expected exit code 2 for %v, got %d", args, code)
+		}
+	}
+}
+
+func TestRunOffsetsCommand_DryRun(t *testing.T) {
+	broker := newGroupsCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"reset", "--group", "audit", "--topic", "orders", "--shift-by", "-5"}
+	if code := runOffsetsCommand(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"shift by -5", "PARTITION", "-5", "Dry run"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output, got %q", want, out)
+		}
+	}
+	if _, ok := lastRequest[*sarama.OffsetCommitRequest](broker); ok {
+		t.Error("Expected no offsets to be committed in a dry run")
+	}
+}
+
+func TestRunOffsetsCommand_Execute(t *testing.T) {
+	broker := newGroupsCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"reset", "--group", "audit", "--topic", "orders", "--to-earliest", "--execute"}
+	if code := runOffsetsCommand(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Reset 2 partition(s)") {
+		t.Errorf("Expected success message, got %q", stdout.String())
+	}
+
+	req, ok := lastRequest[*sarama.OffsetCommitRequest](broker)
+	if !ok {
+		t.Fatal("Expected an OffsetCommitRequest")
+	}
+	if offset, _, _ := req.Offset("orders", 0); offset != 10 {
+		t.Errorf("Expected partition 0 reset to 10, got %d", offset)
+	}
+}
+
+func TestRunOffsetsCommand_ActiveMembers(t *testing.T) {
+	broker := newGroupsCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"reset", "--group", "billing", "--topic", "orders", "--to-latest", "--execute"}
+	if code := runOffsetsCommand(args, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "PARTITION") {
+		t.Errorf("Expected preview before the refusal, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "active member") {
+		t.Errorf("Expected active member error, got %q", stderr.String())
+	}
+	if _, ok := lastRequest[*sarama.OffsetCommitRequest](broker); ok {
+		t.Error("Expected no offsets to be committed")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+const scenarioUsage = `Usage:
+  kafka-producer-ui scenario FILE... [--junit FILE] [--topic TOPIC]
+
+Runs YAML scenarios of send, sleep, expect and set steps against the
+configured cluster. Steps after a failed one are skipped. TOPIC is the
+default topic of send steps (the scenario's topic, then the configured one).
+
+Options:
+  --junit FILE   also write the results as JUnit XML`
+
+// runScenarioCommand handles "kafka-producer-ui scenario"
+func runScenarioCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scenario", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, scenarioUsage) }
+
+	junit := fs.String("junit", "", "")
+	topic := fs.String("topic", "", "")
+
+	files, err := parseWithPositionals(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(stderr, "Error: expected a scenario file\n%s\n", scenarioUsage)
+		return 2
+	}
+
+	scenarios := make([]*Scenario, 0, len(files))
+	for _, file := range files {
+		scenario, err := LoadScenario(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		scenarios = append(scenarios, scenario)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+	if *topic == "" {
+		*topic = config.Topic
+	}
+
+	producer, err := NewKafkaProducer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = producer.Close() }()
+
+	consumer, err := NewKafkaConsumer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = consumer.Close() }()
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	stopOnce := sync.OnceFunc(func() { close(stop) })
+	defer stopOnce()
+	go func() {
+		select {
+		case <-interrupt:
+			stopOnce()
+		case <-stop:
+		}
+	}()
+
+	suites := junitTestSuites{}
+	failed := 0
+	for _, scenario := range scenarios {
+		fmt.Fprintln(stdout, scenario.Name)
+		start := time.Now()
+
+		var results []StepResult
+		run, err := NewScenarioRun(scenario, producer, consumer, *topic)
+		if err == nil {
+			results = run.Run(stop, func(result StepResult) {
+				printStepResult(stdout, result)
+			})
+		} else {
+			fmt.Fprintf(stdout, "  ✗ %v\n", err)
+		}
+
+		suite := newJUnitTestSuite(scenario, results, err, time.Since(start))
+		if suite.Failures > 0 || suite.Errors > 0 {
+			failed++
+		}
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if *junit != "" {
+		if err := writeJUnit(*junit, suites); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stdout, "%d of %d scenario(s) passed\n", len(scenarios)-failed, len(scenarios))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printStepResult(w io.Writer, result StepResult) {
+	switch result.Status {
+	case stepPassed:
+		fmt.Fprintf(w, "  ✓ %-30s %8s  %s\n", result.Name, result.Duration.Round(time.Millisecond), result.Detail)
+	case stepFailed:
+		fmt.Fprintf(w, "  ✗ %-30s %8s  %v\n", result.Name, result.Duration.Round(time.Millisecond), result.Err)
+	default:
+		fmt.Fprintf(w, "  - %-30s %8s\n", result.Name, "skipped")
+	}
+}
+
+// JUnit XML report, as understood by CI servers
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// newJUnitTestSuite reports a scenario as a test suite with one test case
+// per step. setupErr, an error that prevented the steps from running, is
+// reported as an erroneous "setup" test case.
+func newJUnitTestSuite(scenario *Scenario, results []StepResult, setupErr error, elapsed time.Duration) junitTestSuite {
+	suite := junitTestSuite{
+		Name:  scenario.Name,
+		Tests: len(results),
+		Time:  junitSeconds(elapsed),
+	}
+	if setupErr != nil {
+		suite.Tests++
+		suite.Errors++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      "setup",
+			ClassName: scenario.Name,
+			Time:      junitSeconds(0),
+			Error:     &junitMessage{Message: setupErr.Error()},
+		})
+	}
+
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      fmt.Sprintf("%02d %s", result.Index+1, result.Name),
+			ClassName: scenario.Name,
+			Time:      junitSeconds(result.Duration),
+			SystemOut: result.Detail,
+		}
+		switch result.Status {
+		case stepFailed:
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: result.Err.Error()}
+		case stepSkipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: "previous step failed"}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	return suite
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func writeJUnit(path string, suites junitTestSuites) error {
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	report := xml.Header + string(data) + "\n"
+	if err := os.WriteFile(path, []byte(report), 0o644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
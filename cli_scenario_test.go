@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestRunScenarioCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	var stdout, stderr bytes.Buffer
+	if code := runScenarioCommand(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without files, got %d", code)
+	}
+	if code := runScenarioCommand([]string{filepath.Join(t.TempDir(), "missing.yaml")}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 for a missing file, got %d", code)
+	}
+}
+
+func TestRunScenarioCommand_JUnit(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	handlers := replyClusterHandlers(t, broker)
+	handlers["ProduceRequest"] = sarama.NewMockProduceResponse(t)
+	broker.SetHandlerByMap(handlers)
+	useMockCluster(t, broker)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "flow.yaml")
+	data := `name: flow
+steps:
+  - send: {topic: replies, value: ping}
+  - expect: {topic: replies, filter: key == "pong", timeout: 200ms}
+  - sleep: 1ms
+`
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(dir, "junit.xml")
+
+	var stdout, stderr bytes.Buffer
+	code := runScenarioCommand([]string{file, "--junit", report}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("Expected exit code 1 for a failed step, got %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{"✓ send to replies", "✗ expect on replies", "- sleep 1ms", "0 of 1 scenario(s) passed"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, stdout.String())
+		}
+	}
+
+	content, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(content, &suites); err != nil {
+		t.Fatalf("Expected valid JUnit XML, got %v:\n%s", err, content)
+	}
+	suite := suites.Suites[0]
+	if suite.Name != "flow" || suite.Tests != 3 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("Expected 3 tests with 1 failure and 1 skipped, got %+v", suite)
+	}
+	if suite.Cases[1].Failure == nil || !strings.Contains(suite.Cases[1].Failure.Message, "no record matching") {
+		t.Errorf("Expected the expect step to fail, got %+v", suite.Cases[1])
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+const serveUsage = `Usage:
+  kafka-producer-ui serve [--addr ADDR] [--token TOKEN]
+
+Serves an HTTP API that sends messages with the saved configuration:
+
+  POST /topics/{topic}/messages   send a message
+  GET  /topics                    list topics
+  GET  /health                    check the connection to Kafka
+
+The message body is {"key": ..., "value": ..., "headers": [{"key": "...",
+"value": "..."}], "partition": N}. JSON values are sent as-is, strings as
+text; set key_encoding/value_encoding to "base64" for binary data.
+
+Options:
+  --addr ADDR     listen address (default localhost:8080)
+  --token TOKEN   require "Authorization: Bearer TOKEN" on every request
+                  except /health; defaults to $KAFKA_PRODUCER_TOKEN`
+
+// serveShutdownTimeout is how long in-flight requests may take after an
+// interrupt
+const serveShutdownTimeout = 10 * time.Second
+
+// runServeCommand handles "kafka-producer-ui serve"
+func runServeCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprintln(stderr, serveUsage) }
+
+	addr := fs.String("addr", "localhost:8080", "")
+	token := fs.String("token", os.Getenv("KAFKA_PRODUCER_TOKEN"), "")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "Error: unexpected argument %q\n%s\n", fs.Arg(0), serveUsage)
+		return 2
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	producer, err := NewKafkaProducer(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = producer.Close() }()
+
+	admin, err := NewKafkaAdmin(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = admin.Close() }()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           NewServer(producer, admin, *token, stdout),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	auth := "without authentication"
+	if *token != "" {
+		auth = "with bearer token authentication"
+	}
+	fmt.Fprintf(stderr, "Listening on http://%s %s\n", *addr, auth)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setTestHome points the config file lookup at a temporary home directory
+func setTestHome(t *testing.T) string {
+	t.Helper()
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("USERPROFILE", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	return homeDir
+}
+
+func TestRunConfigCommand_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := runConfigCommand(nil, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Usage") {
+		t.Errorf("Expected usage on stderr, got %q", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := runConfigCommand([]string{"unknown"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for unknown subcommand, got %d", code)
+	}
+}
+
+func TestRunConfigValidate_Default(t *testing.T) {
+	setTestHome(t)
+
+	var stdout, stderr bytes.Buffer
+	code := runConfigCommand([]string{"validate"}, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("Expected exit code 0 for default config, got %d: %s", code, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "Configuration is valid") {
+		t.Errorf("Expected success message, got %q", stdout.String())
+	}
+}
+
+func TestRunConfigValidate_Invalid(t *testing.T) {
+	homeDir := setTestHome(t)
+
+	data := []byte(`{"brokers": ["localhost"], "topic": "test", "key_serde": "avro"}`)
+	if err := os.WriteFile(filepath.Join(homeDir, ".kafka-producer.json"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := runConfigCommand([]string{"validate"}, &stdout, &stderr)
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "brokers: broker \"localhost\": missing port") {
+		t.Errorf("Expected broker error in output, got %q", out)
+	}
+	if !strings.Contains(out, "key_serde: unknown serde") {
+		t.Errorf("Expected serde error in output, got %q", out)
+	}
+	if !strings.Contains(out, "2 error(s)") {
+		t.Errorf("Expected error summary, got %q", out)
+	}
+}
+
+func TestRunConfigImport(t *testing.T) {
+	homeDir := setTestHome(t)
+	kcatDir := filepath.Join(homeDir, ".config")
+	if err := os.MkdirAll(kcatDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	kcat := "metadata.broker.list=kafka1:9093\nsecurity.protocol=SSL\nssl.ca.location=/certs/ca.pem\nssl.key.password=s3cret\n"
+	if err := os.WriteFile(filepath.Join(kcatDir, "kcat.conf"), []byte(kcat), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runConfigCommand([]string{"import", "--dry-run"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"ca_file": "/certs/ca.pem"`) {
+		t.Errorf("Expected the imported config on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "ssl.key.password is a secret") {
+		t.Errorf("Expected a note about the secret, got %q", stderr.String())
+	}
+	if _, err := os.Stat(filepath.Join(homeDir, ".kafka-producer.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected --dry-run not to save the config, got %v", err)
+	}
+
+	stdout.Reset()
+	if code := runConfigCommand([]string{"import"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(config.Brokers) != 1 || config.Brokers[0] != "kafka1:9093" || !config.UseTLS || config.Topic != "test-topic" {
+		t.Errorf("Expected the imported brokers and TLS with the default topic, got %+v", config)
+	}
+
+	// A second import keeps the replaced file, and --output leaves it alone
+	saved := filepath.Join(homeDir, ".kafka-producer.json")
+	before, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if code := runConfigCommand([]string{"import"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if backup, err := os.ReadFile(saved + ".bak"); err != nil || !bytes.Equal(backup, before) {
+		t.Errorf("Expected a backup of the previous config, got %q, %v", backup, err)
+	}
+	if !strings.Contains(stdout.String(), saved+".bak") {
+		t.Errorf("Expected the backup path on stdout, got %q", stdout.String())
+	}
+
+	output := filepath.Join(homeDir, "imported.yaml")
+	if code := runConfigCommand([]string{"import", "--output", output}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(output); err != nil || !strings.Contains(string(data), "ca_file: /certs/ca.pem") {
+		t.Errorf("Expected the imported config as YAML in %s, got %q, %v", output, data, err)
+	}
+	if after, _ := os.ReadFile(saved); !bytes.Equal(after, before) {
+		t.Errorf("Expected --output to leave the saved config alone, got %s", after)
+	}
+
+	stdout.Reset()
+	if code := runConfigCommand([]string{"export"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	for _, want := range []string{"bootstrap.servers=kafka1:9093", "security.protocol=SSL", "ssl.truststore.location=/certs/ca.pem"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected exported properties to contain %q, got %q", want, stdout.String())
+		}
+	}
+
+	if code := runConfigCommand([]string{"import", filepath.Join(homeDir, "missing.properties")}, &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 for a missing file, got %d", code)
+	}
+}
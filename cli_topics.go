@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const topicUsage = `Usage:
+  kafka-producer-ui topic list
+  kafka-producer-ui topic create NAME [--partitions N] [--replication-factor N] [--config key=value ...]
+  kafka-producer-ui topic add-partitions NAME --count N
+  kafka-producer-ui topic describe NAME [--all]
+  kafka-producer-ui topic alter NAME --config key=value ... [--reset key ...]
+  kafka-producer-ui topic delete NAME [--confirm NAME]
+  kafka-producer-ui topic truncate [NAME] (--before OFFSET | --all) [--partitions 0,1] [--confirm NAME]
+
+truncate defaults to the configured topic.`
+
+// stringList collects the values of a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// connectAdmin loads the saved configuration and opens a cluster admin
+func connectAdmin() (*Config, *KafkaAdmin, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	admin, err := NewKafkaAdmin(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, admin, nil
+}
+
+// parseNamedFlags parses args of the form "NAME [flags]" or "[flags] NAME".
+// The returned name is empty if none was given.
+func parseNamedFlags(fs *flag.FlagSet, args []string) (string, error) {
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+
+	return name, nil
+}
+
+// confirmTopicName asks the user to type the topic name unless confirm was
+// given on the command line, and fails if the two do not match
+func confirmTopicName(stdin io.Reader, stdout io.Writer, name, confirm, warning string) error {
+	if confirm == "" {
+		fmt.Fprintf(stdout, "%s\nType the topic name to confirm: ", warning)
+		scanner := bufio.NewScanner(stdin)
+		if scanner.Scan() {
+			confirm = strings.TrimSpace(scanner.Text())
+		}
+	}
+	if confirm != name {
+		return fmt.Errorf("confirmation did not match topic %q", name)
+	}
+	return nil
+}
+
+// runTopicCommand handles "kafka-producer-ui topic <subcommand>"
+func runTopicCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, topicUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("topic "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var run func(admin *KafkaAdmin, name string) error
+	switch args[0] {
+	case "list":
+		run = func(admin *KafkaAdmin, _ string) error { return listTopics(admin, stdout) }
+
+	case "create":
+		partitions := fs.Int("partitions", 1, "number of partitions")
+		replicationFactor := fs.Int("replication-factor", 1, "replication factor")
+		var configs stringList
+		fs.Var(&configs, "config", "topic config as key=value (repeatable)")
+		run = func(admin *KafkaAdmin, name string) error {
+			entries, err := parseConfigPairs(configs)
+			if err != nil {
+				return err
+			}
+			if err := admin.CreateTopic(name, int32(*partitions), int16(*replicationFactor), entries); err != nil { // #nosec G115 -- small CLI values
+				return err
+			}
+			fmt.Fprintf(stdout, "Created topic %q with %d partition(s)\n", name, *partitions)
+			return nil
+		}
+
+	case "add-partitions":
+		count := fs.Int("count", 0, "new total number of partitions")
+		run = func(admin *KafkaAdmin, name string) error {
+			if *count <= 0 {
+				return fmt.Errorf("--count must be greater than zero")
+			}
+			if err := admin.AddPartitions(name, int32(*count)); err != nil { // #nosec G115 -- small CLI value
+				return err
+			}
+			fmt.Fprintf(stdout, "Topic %q now has %d partition(s)\n", name, *count)
+			return nil
+		}
+
+	case "describe":
+		all := fs.Bool("all", false, "include configs left at their default value")
+		run = func(admin *KafkaAdmin, name string) error { return describeTopic(admin, name, *all, stdout) }
+
+	case "alter":
+		var configs, resets stringList
+		fs.Var(&configs, "config", "topic config to set as key=value (repeatable)")
+		fs.Var(&resets, "reset", "topic config to reset to the default (repeatable)")
+		run = func(admin *KafkaAdmin, name string) error {
+			changes, err := parseConfigPairs(configs)
+			if err != nil {
+				return err
+			}
+			for _, key := range resets {
+				changes[key] = ""
+			}
+			if len(changes) == 0 {
+				return fmt.Errorf("nothing to change, use --config or --reset")
+			}
+			if err := admin.AlterTopicConfig(name, changes); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Updated %d config(s) of topic %q\n", len(changes), name)
+			return nil
+		}
+
+	case "delete":
+		confirm := fs.String("confirm", "", "topic name, to skip the interactive confirmation")
+		run = func(admin *KafkaAdmin, name string) error {
+			warning := fmt.Sprintf("This permanently deletes topic %q and all of its data.", name)
+			if err := confirmTopicName(stdin, stdout, name, *confirm, warning); err != nil {
+				return fmt.Errorf("%w, topic was not deleted", err)
+			}
+			if err := admin.DeleteTopic(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Deleted topic %q\n", name)
+			return nil
+		}
+
+	case "truncate":
+		before := fs.Int64("before", -1, "delete records before this offset")
+		all := fs.Bool("all", false, "delete all records")
+		partitionList := fs.String("partitions", "", "comma-separated partitions (default all)")
+		confirm := fs.String("confirm", "", "topic name, to skip the interactive confirmation")
+		run = func(admin *KafkaAdmin, name string) error {
+			if (*before < 0) == !*all {
+				return fmt.Errorf("use exactly one of --before OFFSET or --all")
+			}
+			partitions, err := parsePartitionList(*partitionList)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				name = admin.config.Topic
+			}
+			return truncateTopic(admin, name, partitions, *before, *confirm, stdin, stdout)
+		}
+
+	default:
+		fmt.Fprintf(stderr, "Unknown topic subcommand: %s\n%s\n", args[0], topicUsage)
+		return 2
+	}
+
+	name := ""
+	if args[0] == "list" {
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+	} else {
+		var err error
+		if name, err = parseNamedFlags(fs, args[1:]); err != nil {
+			return 2
+		}
+		if name == "" && args[0] != "truncate" {
+			fmt.Fprintln(stderr, "Error: topic name is required")
+			return 2
+		}
+	}
+
+	_, admin, err := connectAdmin()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer func() { _ = admin.Close() }()
+
+	if err := run(admin, name); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func listTopics(admin *KafkaAdmin, stdout io.Writer) error {
+	topics, err := admin.ListTopics()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITIONS\tREPLICATION")
+	for _, topic := range topics {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", topic.Name, topic.Partitions, topic.ReplicationFactor)
+	}
+	return w.Flush()
+}
+
+func describeTopic(admin *KafkaAdmin, name string, all bool, stdout io.Writer) error {
+	entries, err := admin.TopicConfig(name)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CONFIG\tVALUE\tSOURCE")
+	for _, entry := range entries {
+		if entry.Default && !all {
+			continue
+		}
+		value := entry.Value
+		if entry.Sensitive {
+			value = "(sensitive)"
+		}
+		source := "topic"
+		if entry.Default {
+			source = "default"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Name, value, source)
+	}
+	return w.Flush()
+}
+
+func truncateTopic(admin *KafkaAdmin, name string, partitions []int32, before int64, confirm string, stdin io.Reader, stdout io.Writer) error {
+	watermarks, err := admin.Watermarks(name, partitions)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tLOW\tHIGH\tRECORDS")
+	for _, mark := range watermarks {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", mark.Partition, mark.Low, mark.High, mark.High-mark.Low)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	scope := "all records"
+	if before >= 0 {
+		scope = fmt.Sprintf("records before offset %d", before)
+	}
+	warning := fmt.Sprintf("This permanently deletes %s from %d partition(s) of topic %q.", scope, len(watermarks), name)
+	if err := confirmTopicName(stdin, stdout, name, confirm, warning); err != nil {
+		return fmt.Errorf("%w, no records were deleted", err)
+	}
+
+	results, err := admin.TruncateTopic(name, partitions, before)
+	if err != nil {
+		return err
+	}
+
+	w = tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PARTITION\tLOW BEFORE\tLOW AFTER\tHIGH")
+	for _, result := range results {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", result.Partition, result.LowBefore, result.LowAfter, result.High)
+	}
+	return w.Flush()
+}
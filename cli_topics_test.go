@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// useMockCluster saves a config pointing at broker in a temporary home
+func useMockCluster(t *testing.T, broker *sarama.MockBroker) {
+	t.Helper()
+	setTestHome(t)
+
+	config := &Config{Brokers: []string{broker.Addr()}, Topic: testTopic, KeySerde: serdeString, ValueSerde: serdeJSON}
+	if err := SaveConfig(config); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunTopicCommand_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	if code := runTopicCommand(nil, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "Usage") {
+		t.Errorf("Expected usage on stderr, got %q", stderr.String())
+	}
+
+	stderr.Reset()
+	if code := runTopicCommand([]string{"rename"}, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for unknown subcommand, got %d", code)
+	}
+
+	stderr.Reset()
+	if code := runTopicCommand([]string{"create", "--partitions", "3"}, strings.NewReader(""), &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without topic name, got %d", code)
+	}
+}
+
+func TestRunTopicCommand_List(t *testing.T) {
+	useMockCluster(t, newMockCluster(t, "orders", "payments"))
+
+	var stdout, stderr bytes.Buffer
+	if code := runTopicCommand([]string{"list"}, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "TOPIC") || !strings.Contains(out, "orders") || !strings.Contains(out, "payments") {
+		t.Errorf("Expected topic table, got %q", out)
+	}
+	if strings.Index(out, "orders") > strings.Index(out, "payments") {
+		t.Error("Expected topics sorted by name")
+	}
+}
+
+func TestRunTopicCommand_Create(t *testing.T) {
+	broker := newMockCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"create", "orders", "--partitions", "4", "--config", "retention.ms=1000"}
+	if code := runTopicCommand(args, strings.NewReader(""), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `Created topic "orders" with 4 partition(s)`) {
+		t.Errorf("Expected success message, got %q", stdout.String())
+	}
+
+	req, ok := lastRequest[*sarama.CreateTopicsRequest](broker)
+	if !ok || req.TopicDetails["orders"] == nil || req.TopicDetails["orders"].NumPartitions != 4 {
+		t.Errorf("Expected CreateTopicsRequest for orders with 4 partitions, got %+v", req)
+	}
+}
+
+func TestRunTopicCommand_Alter_NothingToChange(t *testing.T) {
+	useMockCluster(t, newMockCluster(t, "orders"))
+
+	var stdout, stderr bytes.Buffer
+	if code := runTopicCommand([]string{"alter", "orders"}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "nothing to change") {
+		t.Errorf("Expected error on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunTopicCommand_Delete_ConfirmationMismatch(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	if code := runTopicCommand([]string{"delete", "orders"}, strings.NewReader("order\n"), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), "Type the topic name to confirm") {
+		t.Errorf("Expected confirmation prompt, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "was not deleted") {
+		t.Errorf("Expected mismatch error, got %q", stderr.String())
+	}
+	if _, ok := lastRequest[*sarama.DeleteTopicsRequest](broker); ok {
+		t.Error("Expected no DeleteTopicsRequest")
+	}
+}
+
+func TestRunTopicCommand_Delete_Confirmed(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	if code := runTopicCommand([]string{"delete", "orders"}, strings.NewReader("orders\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `Deleted topic "orders"`) {
+		t.Errorf("Expected success message, got %q", stdout.String())
+	}
+}
+
+func TestRunTopicCommand_Truncate(t *testing.T) {
+	broker := newGroupsCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"truncate", "orders", "--all", "--partitions", "1"}
+	if code := runTopicCommand(args, strings.NewReader("orders\n"), &stdout, &stderr); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"RECORDS", "deletes all records from 1 partition(s)", "LOW BEFORE"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output, got %q", want, out)
+		}
+	}
+
+	req, ok := lastRequest[*sarama.DeleteRecordsRequest](broker)
+	if !ok || len(req.Topics["orders"].PartitionOffsets) != 1 || req.Topics["orders"].PartitionOffsets[1] != 7 {
+		t.Errorf("Expected deletion of partition 1 up to 7, got %+v", req)
+	}
+}
+
+func TestRunTopicCommand_Truncate_Errors(t *testing.T) {
+	broker := newGroupsCluster(t)
+	useMockCluster(t, broker)
+
+	var stdout, stderr bytes.Buffer
+	if code := runTopicCommand([]string{"truncate", "orders"}, strings.NewReader(""), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1 without --before or --all, got %d", code)
+	}
+
+	stderr.Reset()
+	// The topic defaults to the configured one, which the confirmation must match
+	if code := runTopicCommand([]string{"truncate", "--before", "20"}, strings.NewReader("orders\n"), &stdout, &stderr); code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), testTopic) {
+		t.Errorf("Expected configured topic in error, got %q", stderr.String())
+	}
+	if _, ok := lastRequest[*sarama.DeleteRecordsRequest](broker); ok {
+		t.Error("Expected no records to be deleted")
+	}
+}
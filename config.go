@@ -1,9 +1,14 @@
 package main
 
 import (
-	"encoding/json"
+	"cmp"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
@@ -15,18 +20,228 @@ type Config struct {
 	CAFile     string   `json:"ca_file"`
 	KeySerde   string   `json:"key_serde"`   // "string", "json", "bytearray"
 	ValueSerde string   `json:"value_serde"` // "string", "json", "bytearray"
-	UseAuth    bool     `json:"use_auth"`
+	UseAuth    bool     `json:"use_auth"`    // client certificate authentication (mTLS)
+
+	// TLS without a client certificate; UseAuth turns it on as well. An
+	// empty CAFile verifies the brokers against the system cert pool.
+	UseTLS                bool   `json:"use_tls,omitempty"`
+	TLSServerName         string `json:"tls_server_name,omitempty"`          // overrides the broker host name for SNI and verification
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify,omitempty"` // disables certificate verification, dev clusters only
+
+	// Password of an encrypted key or keystore: a secret reference such as
+	// "cmd:pass show kafka/key", see resolveSecret, or a literal typed in the
+	// config view that is never saved. Empty means KeyPasswordEnv.
+	KeyPassword           string `json:"key_password,omitempty"`
+	KeyPasswordEnv        string `json:"key_password_env,omitempty"`        // default KAFKA_PRODUCER_KEY_PASSWORD
+	TruststorePasswordEnv string `json:"truststore_password_env,omitempty"` // default KAFKA_PRODUCER_TRUSTSTORE_PASSWORD
+
+	// SASL authentication, over TLS or plaintext: "OAUTHBEARER" or "GSSAPI"
+	SASLMechanism string `json:"sasl_mechanism,omitempty"`
+
+	// OAuth2 client-credentials flow that issues the OAUTHBEARER tokens
+	OAuthTokenURL     string            `json:"oauth_token_url,omitempty"`
+	OAuthClientID     string            `json:"oauth_client_id,omitempty"`
+	OAuthClientSecret string            `json:"oauth_client_secret,omitempty"` // secret reference, see resolveSecret
+	OAuthScopes       []string          `json:"oauth_scopes,omitempty"`
+	OAuthAudience     string            `json:"oauth_audience,omitempty"`   // "audience" parameter some providers require
+	OAuthExtensions   map[string]string `json:"oauth_extensions,omitempty"` // SASL extensions, e.g. logicalCluster
+
+	// Kerberos login of SASL/GSSAPI, with a keytab or a password
+	KerberosUsername        string `json:"kerberos_username,omitempty"` // "user" or "user@REALM"
+	KerberosRealm           string `json:"kerberos_realm,omitempty"`
+	KerberosPassword        string `json:"kerberos_password,omitempty"` // secret reference, see resolveSecret
+	KerberosKeytabPath      string `json:"kerberos_keytab_path,omitempty"`
+	KerberosConfigPath      string `json:"kerberos_config_path,omitempty"`        // default $KRB5_CONFIG or /etc/krb5.conf
+	KerberosServiceName     string `json:"kerberos_service_name,omitempty"`       // default "kafka"
+	KerberosDisablePAFXFAST bool   `json:"kerberos_disable_pa_fx_fast,omitempty"` // needed by some Active Directory KDCs
+
+	// Serdes of matching topics, overriding KeySerde and ValueSerde
+	TopicSerdes []TopicSerde `json:"topic_serdes,omitempty"`
+
+	// Request/reply mode
+	ReplyTopic        string `json:"reply_topic,omitempty"`
+	CorrelationHeader string `json:"correlation_header,omitempty"` // default "correlation-id"
+	ReplyTimeout      string `json:"reply_timeout,omitempty"`      // Go duration, default 30s
+
+	// Topics the message view sends every message to instead of Topic
+	FanOut []FanOutTarget `json:"fan_out,omitempty"`
+
+	// Starlark script whose transform(msg) modifies messages before sending
+	TransformScript string `json:"transform_script,omitempty"`
+
+	// Key bindings by action name, e.g. "connect": ["ctrl+k"]; an empty list
+	// unbinds the action
+	Keymap map[string][]string `json:"keymap,omitempty"`
+
+	// path is the file the config was loaded from and is saved to
+	path string
+}
+
+// TopicSerde sets the serdes of the topics matching Pattern: an exact topic
+// name, a glob such as "orders.*" or a regular expression between slashes
+// such as "/^audit-[0-9]+$/". An empty serde keeps the global one.
+type TopicSerde struct {
+	Pattern    string `json:"pattern"`
+	KeySerde   string `json:"key_serde,omitempty"`
+	ValueSerde string `json:"value_serde,omitempty"`
+}
+
+// matches reports whether topic matches the pattern. Invalid patterns are
+// reported by ValidateConfig and match nothing here.
+func (t TopicSerde) matches(topic string) bool {
+	if expr, ok := strings.CutPrefix(t.Pattern, "/"); ok && strings.HasSuffix(expr, "/") {
+		re, err := regexp.Compile(strings.TrimSuffix(expr, "/"))
+		return err == nil && re.MatchString(topic)
+	}
+	matched, err := path.Match(t.Pattern, topic)
+	return err == nil && matched
+}
+
+// topicSerde returns the first mapping that matches topic, or nil
+func (c *Config) topicSerde(topic string) *TopicSerde {
+	for i := range c.TopicSerdes {
+		if c.TopicSerdes[i].matches(topic) {
+			return &c.TopicSerdes[i]
+		}
+	}
+	return nil
+}
+
+// serdesFor returns the effective key and value serdes of topic
+func (c *Config) serdesFor(topic string) (keySerde, valueSerde string) {
+	keySerde, valueSerde = c.KeySerde, c.ValueSerde
+	if mapping := c.topicSerde(topic); mapping != nil {
+		keySerde = cmp.Or(mapping.KeySerde, keySerde)
+		valueSerde = cmp.Or(mapping.ValueSerde, valueSerde)
+	}
+	return keySerde, valueSerde
+}
+
+// Environment variables holding keystore passwords by default
+const (
+	defaultKeyPasswordEnv        = "KAFKA_PRODUCER_KEY_PASSWORD"
+	defaultTruststorePasswordEnv = "KAFKA_PRODUCER_TRUSTSTORE_PASSWORD"
+)
+
+// keyPasswordEnv returns the environment variable with the key password
+func (c *Config) keyPasswordEnv() string {
+	return cmp.Or(c.KeyPasswordEnv, defaultKeyPasswordEnv)
+}
+
+// keyPassword returns the password of an encrypted key or keystore
+func (c *Config) keyPassword() (string, error) {
+	if c.KeyPassword == "" {
+		return os.Getenv(c.keyPasswordEnv()), nil
+	}
+	password, err := resolveSecret(c.KeyPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve key password: %w", err)
+	}
+	return password, nil
+}
+
+// truststorePasswordEnv returns the environment variable with the
+// truststore password
+func (c *Config) truststorePasswordEnv() string {
+	return cmp.Or(c.TruststorePasswordEnv, defaultTruststorePasswordEnv)
+}
+
+// truststorePassword returns the password of a PKCS#12 or JKS truststore,
+// falling back to the key password
+func (c *Config) truststorePassword() (string, error) {
+	if password := os.Getenv(c.truststorePasswordEnv()); password != "" {
+		return password, nil
+	}
+	return c.keyPassword()
+}
+
+// tlsEnabled reports whether connections to the brokers use TLS
+func (c *Config) tlsEnabled() bool {
+	return c.UseTLS || c.UseAuth
+}
+
+// mtlsConfigured reports whether a client certificate is set. A PKCS#12 or
+// JKS keystore holds the key, so no key file is needed with one.
+func mtlsConfigured(certFile, keyFile string) bool {
+	return certFile != "" && (keyFile != "" || isKeystore(certFile))
+}
+
+// Request/reply defaults
+const (
+	defaultCorrelationHeader = "correlation-id"
+	defaultReplyTimeout      = 30 * time.Second
+)
+
+// correlationHeader returns the header that links requests and replies
+func (c *Config) correlationHeader() string {
+	if c.CorrelationHeader == "" {
+		return defaultCorrelationHeader
+	}
+	return c.CorrelationHeader
+}
+
+// replyTimeout returns how long to wait for a reply. Invalid values are
+// reported by ValidateConfig and fall back to the default here.
+func (c *Config) replyTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.ReplyTimeout)
+	if err != nil || timeout <= 0 {
+		return defaultReplyTimeout
+	}
+	return timeout
+}
+
+// Config file locations: $XDG_CONFIG_HOME/kafka-producer-ui/config.* is
+// searched first, then the legacy JSON file in the home directory
+const (
+	configDirName    = "kafka-producer-ui"
+	configBaseName   = "config"
+	legacyConfigName = ".kafka-producer.json"
+)
+
+// configExtensions lists the config file extensions in lookup order
+var configExtensions = []string{".yaml", ".yml", ".toml", ".json"}
+
+// configDir returns the XDG config directory of the application
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, configDirName), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", configDirName), nil
+}
+
+// configPath returns the config file to use: the first existing
+// config.yaml, config.yml, config.toml or config.json in configDir, else
+// the legacy ~/.kafka-producer.json, where new configs are also saved
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, configBaseName+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, legacyConfigName), nil
 }
 
 // LoadConfig loads configuration from file
 func LoadConfig() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := configPath()
 	if err != nil {
 		return nil, err
 	}
 
-	configPath := filepath.Join(homeDir, ".kafka-producer.json")
-
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -37,32 +252,59 @@ func LoadConfig() (*Config, error) {
 				UseAuth:    false,
 				KeySerde:   "json",
 				ValueSerde: "json",
+				path:       configPath,
 			}, nil
 		}
 		return nil, err
 	}
 
 	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	if err := decodeConfig(configPath, data, &config); err != nil {
 		return nil, err
 	}
+	config.path = configPath
 
 	return &config, nil
 }
 
-// SaveConfig saves configuration to file
+// SaveConfig saves configuration to the file it was loaded from, in that
+// file's format. Secret fields are saved only when they hold references.
 func SaveConfig(config *Config) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
+	path := config.path
+	if path == "" {
+		var err error
+		if path, err = configPath(); err != nil {
+			return err
+		}
 	}
 
-	configPath := filepath.Join(homeDir, ".kafka-producer.json")
+	// Comments of a YAML file survive the rewrite
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := encodeConfig(path, config.withoutSecrets(), previous)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// backupConfig copies the config file at path to path.bak before it is
+// replaced. It returns the backup path, or "" if there is no file yet.
+func backupConfig(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	backup := path + ".bak"
+	return backup, os.WriteFile(backup, data, 0600)
 }
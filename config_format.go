@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats, chosen by extension
+const (
+	configJSON = "json"
+	configYAML = "yaml"
+	configTOML = "toml"
+)
+
+// configFormat returns the format of a config file; unknown extensions are JSON
+func configFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return configYAML
+	case ".toml":
+		return configTOML
+	default:
+		return configJSON
+	}
+}
+
+// decodeConfig parses a config file. YAML and TOML documents use the same
+// keys as JSON: they are converted to JSON so that the json tags of Config
+// are the only field mapping.
+func decodeConfig(path string, data []byte, config *Config) error {
+	var raw any
+	switch configFormat(path) {
+	case configYAML:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case configTOML:
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	default:
+		if err := json.Unmarshal(data, config); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return nil
+	}
+	if raw == nil {
+		return nil // empty document
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// encodeConfig formats config for path. For YAML, previous is the current
+// file content: its comments and key order are kept and only the values are
+// updated.
+func encodeConfig(path string, config *Config, previous []byte) ([]byte, error) {
+	switch configFormat(path) {
+	case configYAML:
+		return encodeYAMLConfig(config, previous)
+	case configTOML:
+		return encodeTOMLConfig(config)
+	default:
+		return json.MarshalIndent(config, "", "  ")
+	}
+}
+
+func encodeYAMLConfig(config *Config, previous []byte) ([]byte, error) {
+	// JSON is YAML, so this yields a node tree in struct field order
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	resetYAMLStyle(&doc)
+
+	var old yaml.Node
+	if err := yaml.Unmarshal(previous, &old); err == nil && len(old.Content) == 1 && old.Content[0].Kind == yaml.MappingNode {
+		mergeYAML(old.Content[0], doc.Content[0])
+		doc = old
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resetYAMLStyle turns the flow mappings and quoted strings of parsed JSON
+// into block style; strings that need quotes are still quoted on encoding
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+// mergeYAML updates dst with the values of src, keeping the comments and
+// key order of dst. Keys missing from src are removed and new keys are
+// appended.
+func mergeYAML(dst, src *yaml.Node) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		var content []*yaml.Node
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if value := yamlMappingValue(src, dst.Content[i].Value); value != nil {
+				mergeYAML(dst.Content[i+1], value)
+				content = append(content, dst.Content[i], dst.Content[i+1])
+			}
+		}
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			if yamlMappingValue(dst, src.Content[i].Value) == nil {
+				content = append(content, src.Content[i], src.Content[i+1])
+			}
+		}
+		dst.Content = content
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		content := make([]*yaml.Node, len(src.Content))
+		for i, item := range src.Content {
+			if i < len(dst.Content) {
+				mergeYAML(dst.Content[i], item)
+				content[i] = dst.Content[i]
+			} else {
+				content[i] = item
+			}
+		}
+		dst.Content = content
+
+	default:
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+	}
+}
+
+// yamlMappingValue returns the value of key in a mapping node, or nil
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func encodeTOMLConfig(config *Config) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(dropNulls(raw)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dropNulls removes the null values TOML cannot represent
+func dropNulls(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if item == nil {
+				delete(v, key)
+			} else {
+				v[key] = dropNulls(item)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = dropNulls(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile writes a config file into the XDG config directory
+func writeConfigFile(t *testing.T, xdgDir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(xdgDir, configDirName, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "YAML",
+			file: "config.yaml",
+			content: `# Staging cluster
+brokers:
+  - kafka1:9093
+  - kafka2:9093
+topic: orders
+use_tls: true
+topic_serdes:
+  - pattern: "audit.*"
+    value_serde: string
+`,
+		},
+		{
+			name: "TOML",
+			file: "config.toml",
+			content: `# Staging cluster
+brokers = ["kafka1:9093", "kafka2:9093"]
+topic = "orders"
+use_tls = true
+
+[[topic_serdes]]
+pattern = "audit.*"
+value_serde = "string"
+`,
+		},
+		{
+			name:    "JSON",
+			file:    "config.json",
+			content: `{"brokers": ["kafka1:9093", "kafka2:9093"], "topic": "orders", "use_tls": true, "topic_serdes": [{"pattern": "audit.*", "value_serde": "string"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			homeDir := setTestHome(t)
+			xdgDir := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", xdgDir)
+
+			// The XDG file wins over the legacy one
+			legacy := `{"brokers": ["legacy:9092"], "topic": "legacy"}`
+			if err := os.WriteFile(filepath.Join(homeDir, legacyConfigName), []byte(legacy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			path := writeConfigFile(t, xdgDir, tt.file, tt.content)
+
+			config, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !slices.Equal(config.Brokers, []string{"kafka1:9093", "kafka2:9093"}) || config.Topic != "orders" || !config.UseTLS {
+				t.Errorf("Expected the %s config, got %+v", tt.name, config)
+			}
+			if len(config.TopicSerdes) != 1 || config.TopicSerdes[0].Pattern != "audit.*" || config.TopicSerdes[0].ValueSerde != serdeString {
+				t.Errorf("Expected one topic serde, got %+v", config.TopicSerdes)
+			}
+			if config.path != path {
+				t.Errorf("Expected the config to remember %s, got %s", path, config.path)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_XDGDefaultAndLegacyFallback(t *testing.T) {
+	homeDir := setTestHome(t)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if want := filepath.Join(homeDir, legacyConfigName); config.path != want {
+		t.Errorf("Expected new configs to be saved to %s, got %s", want, config.path)
+	}
+
+	// Without $XDG_CONFIG_HOME the directory is ~/.config
+	path := writeConfigFile(t, filepath.Join(homeDir, ".config"), "config.yml", "topic: from-xdg\n")
+	if config, err = LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Topic != "from-xdg" || config.path != path {
+		t.Errorf("Expected the config from %s, got topic %q from %s", path, config.Topic, config.path)
+	}
+}
+
+func TestLoadConfig_ParseError(t *testing.T) {
+	setTestHome(t)
+	xdgDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgDir)
+	writeConfigFile(t, xdgDir, "config.toml", "brokers = [\n")
+
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "config.toml") {
+		t.Errorf("Expected a parse error naming the file, got %v", err)
+	}
+}
+
+func TestSaveConfig_YAMLKeepsComments(t *testing.T) {
+	setTestHome(t)
+	xdgDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgDir)
+	path := writeConfigFile(t, xdgDir, "config.yaml", `# Kafka producer settings
+brokers:
+  - kafka1:9093 # primary
+topic: orders # default topic
+
+# Serdes
+key_serde: string
+value_serde: json
+`)
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	config.Topic = "payments"
+	config.Brokers = append(config.Brokers, "kafka2:9093")
+	config.KeyPassword = "typed-in-the-ui"
+	config.ReplyTopic = "replies"
+
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+
+	for _, want := range []string{
+		"# Kafka producer settings",
+		"- kafka1:9093 # primary",
+		"- kafka2:9093",
+		"topic: payments # default topic",
+		"# Serdes\nkey_serde: string",
+		"reply_topic: replies",
+	} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("Expected saved YAML to contain %q, got:\n%s", want, saved)
+		}
+	}
+	if strings.Contains(saved, "typed-in-the-ui") {
+		t.Errorf("Expected the literal password not to be saved, got:\n%s", saved)
+	}
+
+	reloaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if reloaded.Topic != "payments" || len(reloaded.Brokers) != 2 || reloaded.ReplyTopic != "replies" {
+		t.Errorf("Expected the saved values to load back, got %+v", reloaded)
+	}
+}
+
+func TestSaveConfig_TOML(t *testing.T) {
+	setTestHome(t)
+	xdgDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdgDir)
+	path := writeConfigFile(t, xdgDir, "config.toml", "topic = \"orders\"\n")
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	config.FanOut = []FanOutTarget{{Topic: "audit", ValueSerde: serdeString}}
+	config.OAuthExtensions = map[string]string{"logicalCluster": "lkc-1"}
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `topic = "orders"`) || !strings.Contains(string(data), "[[fan_out]]") {
+		t.Errorf("Expected TOML output, got:\n%s", data)
+	}
+
+	reloaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(reloaded.FanOut) != 1 || reloaded.FanOut[0].Topic != "audit" || reloaded.OAuthExtensions["logicalCluster"] != "lkc-1" {
+		t.Errorf("Expected the saved values to load back, got %+v", reloaded)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 )
 
 const testTopic = "test-topic"
@@ -18,6 +19,7 @@ func TestLoadConfig_Default(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	origUserProfile := os.Getenv("USERPROFILE")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	os.Setenv("USERPROFILE", homeDir)
 	defer func() {
 		os.Setenv("HOME", origHome)
@@ -79,6 +81,7 @@ func TestLoadConfig_ExistingFile(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	origUserProfile := os.Getenv("USERPROFILE")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	os.Setenv("USERPROFILE", homeDir)
 	defer func() {
 		os.Setenv("HOME", origHome)
@@ -119,6 +122,7 @@ func TestSaveConfig(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	origUserProfile := os.Getenv("USERPROFILE")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	os.Setenv("USERPROFILE", homeDir)
 	defer func() {
 		os.Setenv("HOME", origHome)
@@ -190,6 +194,7 @@ func TestLoadConfig_InvalidJSON(t *testing.T) {
 	origHome := os.Getenv("HOME")
 	origUserProfile := os.Getenv("USERPROFILE")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	os.Setenv("USERPROFILE", homeDir)
 	defer func() {
 		os.Setenv("HOME", origHome)
@@ -201,3 +206,61 @@ func TestLoadConfig_InvalidJSON(t *testing.T) {
 		t.Error("Expected error for invalid JSON, got nil")
 	}
 }
+
+func TestConfig_ReplyDefaults(t *testing.T) {
+	config := &Config{}
+	if config.correlationHeader() != defaultCorrelationHeader {
+		t.Errorf("Expected default correlation header, got %s", config.correlationHeader())
+	}
+	if config.replyTimeout() != defaultReplyTimeout {
+		t.Errorf("Expected default reply timeout, got %s", config.replyTimeout())
+	}
+
+	config = &Config{CorrelationHeader: "x-request-id", ReplyTimeout: "1m30s"}
+	if config.correlationHeader() != "x-request-id" {
+		t.Errorf("Expected x-request-id, got %s", config.correlationHeader())
+	}
+	if config.replyTimeout() != 90*time.Second {
+		t.Errorf("Expected 1m30s, got %s", config.replyTimeout())
+	}
+
+	config.ReplyTimeout = "invalid"
+	if config.replyTimeout() != defaultReplyTimeout {
+		t.Errorf("Expected default for invalid timeout, got %s", config.replyTimeout())
+	}
+}
+
+func TestConfig_SerdesFor(t *testing.T) {
+	config := &Config{
+		KeySerde:   serdeJSON,
+		ValueSerde: serdeJSON,
+		TopicSerdes: []TopicSerde{
+			{Pattern: "payments", KeySerde: serdeString, ValueSerde: serdeByteArray},
+			{Pattern: "orders.*", KeySerde: serdeString},
+			{Pattern: "/^audit-[0-9]+$/", ValueSerde: serdeString},
+			{Pattern: "orders.eu", ValueSerde: serdeByteArray},
+			{Pattern: "/([/", KeySerde: serdeByteArray},
+		},
+	}
+
+	tests := []struct {
+		topic      string
+		keySerde   string
+		valueSerde string
+	}{
+		{topic: "payments", keySerde: serdeString, valueSerde: serdeByteArray},
+		{topic: "orders.eu", keySerde: serdeString, valueSerde: serdeJSON}, // first match wins
+		{topic: "audit-42", keySerde: serdeJSON, valueSerde: serdeString},
+		{topic: "audit-42x", keySerde: serdeJSON, valueSerde: serdeJSON},
+		{topic: "payments-v2", keySerde: serdeJSON, valueSerde: serdeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			keySerde, valueSerde := config.serdesFor(tt.topic)
+			if keySerde != tt.keySerde || valueSerde != tt.valueSerde {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.keySerde, tt.valueSerde, keySerde, valueSerde)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// fetchIdleTimeout ends reading a partition when no message arrives for this
+// long, e.g. when the range ends in transaction markers or compacted records
+var fetchIdleTimeout = 5 * time.Second
+
+// errStopFetch can be returned by a fetch handler to stop reading early
+var errStopFetch = errors.New("stop fetch")
+
+// ConsumedMessage is a record read from a topic
+type ConsumedMessage struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+	Key       []byte
+	Value     []byte
+	Headers   []MessageHeader
+}
+
+// FetchRange selects the records to read from a topic. StartOffset and
+// EndOffset (exclusive) are ignored when negative. Since selects the first
+// record at or after a time, Until stops at the first record after it and
+// LastN reads the last N records of every partition.
+type FetchRange struct {
+	Partitions  []int32
+	StartOffset int64
+	EndOffset   int64
+	Since       time.Time
+	Until       time.Time
+	LastN       int64
+}
+
+// KafkaConsumer reads bounded ranges of records without joining a group
+type KafkaConsumer struct {
+	client   sarama.Client
+	consumer sarama.Consumer
+	config   *Config
+}
+
+// NewKafkaConsumer creates a consumer using the same connection settings as
+// the producer
+func NewKafkaConsumer(config *Config) (*KafkaConsumer, error) {
+	saramaConfig, err := newSaramaConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	saramaConfig.Consumer.Return.Errors = true
+
+	client, err := sarama.NewClient(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	return &KafkaConsumer{
+		client:   client,
+		consumer: consumer,
+		config:   config,
+	}, nil
+}
+
+// Fetch reads the records of topic selected by r partition by partition and
+// passes them to handle. Returning errStopFetch from handle ends the fetch
+// without an error.
+func (c *KafkaConsumer) Fetch(topic string, r FetchRange, handle func(ConsumedMessage) error) error {
+	all, err := c.client.Partitions(topic)
+	if err != nil {
+		return fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+
+	partitions := slices.Clone(r.Partitions)
+	if len(partitions) == 0 {
+		partitions = all
+	}
+	slices.Sort(partitions)
+
+	for _, partition := range partitions {
+		if !slices.Contains(all, partition) {
+			return fmt.Errorf("topic %q has no partition %d", topic, partition)
+		}
+		if err := c.fetchPartition(topic, partition, r, handle); err != nil {
+			if errors.Is(err, errStopFetch) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partitionRange resolves r to the offsets [start, end) of one partition
+func (c *KafkaConsumer) partitionRange(topic string, partition int32, r FetchRange) (start, end int64, err error) {
+	low, err := c.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get low watermark of %s/%d: %w", topic, partition, err)
+	}
+	high, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get high watermark of %s/%d: %w", topic, partition, err)
+	}
+
+	start, end = low, high
+	switch {
+	case r.LastN > 0:
+		start = high - r.LastN
+	case !r.Since.IsZero():
+		offset, err := c.client.GetOffset(topic, partition, r.Since.UnixMilli())
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to look up offset by time on %s/%d: %w", topic, partition, err)
+		}
+		// No record at or after Since
+		if offset < 0 {
+			offset = high
+		}
+		start = offset
+	case r.StartOffset >= 0:
+		start = r.StartOffset
+	}
+	if r.EndOffset >= 0 {
+		end = min(end, r.EndOffset)
+	}
+
+	return max(start, low), end, nil
+}
+
+func (c *KafkaConsumer) fetchPartition(topic string, partition int32, r FetchRange, handle func(ConsumedMessage) error) error {
+	start, end, err := c.partitionRange(topic, partition, r)
+	if err != nil {
+		return err
+	}
+	if start >= end {
+		return nil
+	}
+
+	pc, err := c.consumer.ConsumePartition(topic, partition, start)
+	if err != nil {
+		return fmt.Errorf("failed to consume %s/%d: %w", topic, partition, err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	idle := time.NewTimer(fetchIdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case msg, ok := <-pc.Messages():
+			if !ok {
+				return nil
+			}
+			if msg.Offset >= end || (!r.Until.IsZero() && msg.Timestamp.After(r.Until)) {
+				return nil
+			}
+			if err := handle(newConsumedMessage(msg)); err != nil {
+				return err
+			}
+			if msg.Offset >= end-1 {
+				return nil
+			}
+			idle.Reset(fetchIdleTimeout)
+
+		case err := <-pc.Errors():
+			return fmt.Errorf("failed to read %s/%d: %w", topic, partition, err)
+
+		case <-idle.C:
+			return nil
+		}
+	}
+}
+
+// Tail reads new records of topic, starting with the last lastN records of
+// every partition, and passes them to handle until stop is closed. Records of
+// different partitions are interleaved in arrival order.
+func (c *KafkaConsumer) Tail(topic string, lastN int64, stop <-chan struct{}, handle func(ConsumedMessage)) error {
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+
+	starts := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		starts[partition] = sarama.OffsetNewest
+		if lastN > 0 {
+			if starts[partition], _, err = c.partitionRange(topic, partition, FetchRange{StartOffset: -1, EndOffset: -1, LastN: lastN}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.TailFrom(topic, starts, stop, handle)
+}
+
+// HighWatermarks returns the next offset of every partition of topic
+func (c *KafkaConsumer) HighWatermarks(topic string) (map[int32]int64, error) {
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+
+	offsets := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		if offsets[partition], err = c.client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+			return nil, fmt.Errorf("failed to get high watermark of %s/%d: %w", topic, partition, err)
+		}
+	}
+	return offsets, nil
+}
+
+// TailFrom is Tail starting at the given offset of every partition
+func (c *KafkaConsumer) TailFrom(topic string, starts map[int32]int64, stop <-chan struct{}, handle func(ConsumedMessage)) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	records := make(chan *sarama.ConsumerMessage)
+	errs := make(chan error, len(starts))
+
+	for partition, start := range starts {
+		pc, err := c.consumer.ConsumePartition(topic, partition, start)
+		if err != nil {
+			return fmt.Errorf("failed to consume %s/%d: %w", topic, partition, err)
+		}
+		defer func() { _ = pc.Close() }()
+
+		go func() {
+			for {
+				select {
+				case msg, ok := <-pc.Messages():
+					if !ok {
+						return
+					}
+					select {
+					case records <- msg:
+					case <-done:
+						return
+					}
+				case err, ok := <-pc.Errors():
+					if ok {
+						errs <- fmt.Errorf("failed to read %s/%d: %w", topic, err.Partition, err.Err)
+					}
+					return
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case msg := <-records:
+			handle(newConsumedMessage(msg))
+		case err := <-errs:
+			return err
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func newConsumedMessage(msg *sarama.ConsumerMessage) ConsumedMessage {
+	consumed := ConsumedMessage{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp,
+		Key:       msg.Key,
+		Value:     msg.Value,
+	}
+	for _, header := range msg.Headers {
+		if header == nil {
+			continue
+		}
+		consumed.Headers = append(consumed.Headers, MessageHeader{Key: string(header.Key), Value: string(header.Value)})
+	}
+	return consumed
+}
+
+// Close closes the consumer and its underlying client
+func (c *KafkaConsumer) Close() error {
+	if c.consumer != nil {
+		_ = c.consumer.Close()
+	}
+	if c.client != nil {
+		return c.client.Close()
+	}
+	return nil
+}
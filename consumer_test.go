@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// newFetchCluster starts a mock broker with a single-partition "events"
+// topic holding offsets 0-4 with keys key-N and values {"n":N}
+func newFetchCluster(t *testing.T) *sarama.MockBroker {
+	t.Helper()
+
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+
+	fetch := sarama.NewMockFetchResponse(t, 10).SetHighWaterMark("events", 0, 5)
+	for i := int64(0); i < 5; i++ {
+		fetch.SetMessageWithKey("events", 0, i,
+			sarama.StringEncoder(fmt.Sprintf("key-%d", i)),
+			sarama.StringEncoder(fmt.Sprintf(`{"n":%d}`, i)))
+	}
+
+	handlers := mockClusterHandlers(t, broker, "events")
+	handlers["OffsetRequest"] = sarama.NewMockOffsetResponse(t).
+		SetOffset("events", 0, sarama.OffsetOldest, 0).
+		SetOffset("events", 0, sarama.OffsetNewest, 5)
+	handlers["FetchRequest"] = fetch
+	broker.SetHandlerByMap(handlers)
+
+	return broker
+}
+
+func newTestConsumer(t *testing.T, broker *sarama.MockBroker) *KafkaConsumer {
+	t.Helper()
+
+	consumer, err := NewKafkaConsumer(&Config{Brokers: []string{broker.Addr()}})
+	if err != nil {
+		t.Fatalf("NewKafkaConsumer() error = %v", err)
+	}
+	t.Cleanup(func() { _ = consumer.Close() })
+
+	return consumer
+}
+
+func fetchOffsets(t *testing.T, consumer *KafkaConsumer, r FetchRange) []int64 {
+	t.Helper()
+
+	var offsets []int64
+	err := consumer.Fetch("events", r, func(msg ConsumedMessage) error {
+		offsets = append(offsets, msg.Offset)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	return offsets
+}
+
+func TestKafkaConsumer_Fetch(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	tests := []struct {
+		name string
+		r    FetchRange
+		want []int64
+	}{
+		{name: "everything", r: FetchRange{StartOffset: -1, EndOffset: -1}, want: []int64{0, 1, 2, 3, 4}},
+		{name: "offset range", r: FetchRange{StartOffset: 1, EndOffset: 3}, want: []int64{1, 2}},
+		{name: "last N", r: FetchRange{StartOffset: -1, EndOffset: -1, LastN: 2}, want: []int64{3, 4}},
+		{name: "last N larger than topic", r: FetchRange{StartOffset: -1, EndOffset: -1, LastN: 100}, want: []int64{0, 1, 2, 3, 4}},
+		{name: "empty range", r: FetchRange{StartOffset: 4, EndOffset: 2}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fetchOffsets(t, consumer, tt.r)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected offsets %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKafkaConsumer_Fetch_Message(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	var messages []ConsumedMessage
+	err := consumer.Fetch("events", FetchRange{StartOffset: 2, EndOffset: 3}, func(msg ConsumedMessage) error {
+		messages = append(messages, msg)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.Topic != "events" || string(msg.Key) != "key-2" || string(msg.Value) != `{"n":2}` {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+}
+
+func TestKafkaConsumer_Fetch_Stop(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	count := 0
+	err := consumer.Fetch("events", FetchRange{StartOffset: -1, EndOffset: -1}, func(ConsumedMessage) error {
+		count++
+		if count == 2 {
+			return errStopFetch
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error when stopping early, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 messages, got %d", count)
+	}
+}
+
+func TestKafkaConsumer_Fetch_UnknownPartition(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	err := consumer.Fetch("events", FetchRange{Partitions: []int32{3}, StartOffset: -1, EndOffset: -1}, func(ConsumedMessage) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected error for a partition that does not exist")
+	}
+}
+
+func TestKafkaConsumer_Tail(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	stop := make(chan struct{})
+	var offsets []int64
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Tail("events", 2, stop, func(msg ConsumedMessage) {
+			offsets = append(offsets, msg.Offset)
+			if len(offsets) == 2 {
+				close(stop)
+			}
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Tail() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		close(stop)
+		t.Fatal("Tail() did not stop")
+	}
+
+	if fmt.Sprint(offsets) != "[3 4]" {
+		t.Errorf("Expected the last 2 offsets, got %v", offsets)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import "fmt"
+
+// CopyOptions describes a copy from one topic to another
+type CopyOptions struct {
+	Source         string
+	Target         string
+	Range          FetchRange
+	Filter         *Filter // nil copies every message
+	KeepTimestamps bool
+	KeepPartitions bool
+	Limit          int // maximum number of messages to copy, 0 for no limit
+}
+
+// CopyStats counts the messages processed by a copy
+type CopyStats struct {
+	Read    int
+	Copied  int
+	Skipped int
+}
+
+// CopyMessages reads the selected range of the source topic and re-produces
+// the matching messages to the target topic with their key and headers.
+// progress, if set, is called after every message.
+func CopyMessages(consumer *KafkaConsumer, producer *KafkaProducer, opts CopyOptions, progress func(CopyStats)) (CopyStats, error) {
+	var stats CopyStats
+
+	err := consumer.Fetch(opts.Source, opts.Range, func(msg ConsumedMessage) error {
+		stats.Read++
+		if !opts.Filter.Match(msg) {
+			stats.Skipped++
+		} else {
+			out := OutgoingMessage{
+				Topic:     opts.Target,
+				Key:       msg.Key,
+				Value:     msg.Value,
+				Headers:   msg.Headers,
+				Partition: -1,
+			}
+			if opts.KeepTimestamps {
+				out.Timestamp = msg.Timestamp
+			}
+			if opts.KeepPartitions {
+				out.Partition = msg.Partition
+			}
+			if _, _, err := producer.Send(out); err != nil {
+				return fmt.Errorf("failed to copy %s/%d@%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+			}
+			stats.Copied++
+		}
+
+		if progress != nil {
+			progress(stats)
+		}
+		if opts.Limit > 0 && stats.Copied >= opts.Limit {
+			return errStopFetch
+		}
+		return nil
+	})
+
+	return stats, err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestCopyMessages(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	var sent []*sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{
+			sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+				sent = append(sent, msg)
+				return 0, int64(len(sent)), nil
+			},
+		},
+		config: &Config{Topic: "test-topic"},
+	}
+
+	filter, err := ParseFilter("$.n == 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := CopyOptions{
+		Source:         "events",
+		Target:         "events-copy",
+		Range:          FetchRange{StartOffset: 1, EndOffset: -1},
+		Filter:         filter,
+		KeepPartitions: true,
+	}
+
+	progressCalls := 0
+	stats, err := CopyMessages(consumer, producer, opts, func(CopyStats) { progressCalls++ })
+	if err != nil {
+		t.Fatalf("CopyMessages() error = %v", err)
+	}
+
+	if stats.Read != 4 || stats.Copied != 1 || stats.Skipped != 3 {
+		t.Errorf("Expected 4 read, 1 copied, 3 skipped, got %+v", stats)
+	}
+	if progressCalls != 4 {
+		t.Errorf("Expected progress after every message, got %d calls", progressCalls)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("Expected 1 sent message, got %d", len(sent))
+	}
+
+	msg := sent[0]
+	key, _ := msg.Key.Encode()
+	if msg.Topic != "events-copy" || string(key) != "key-3" {
+		t.Errorf("Expected key-3 sent to events-copy, got %s to %s", key, msg.Topic)
+	}
+	if _, ok := msg.Metadata.(explicitPartition); !ok {
+		t.Error("Expected the source partition to be kept")
+	}
+	if !msg.Timestamp.IsZero() {
+		t.Error("Expected the timestamp to be left to the producer")
+	}
+}
+
+func TestCopyMessages_Limit(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+	producer := &KafkaProducer{producer: &mockSyncProducer{}, config: &Config{}}
+
+	opts := CopyOptions{Source: "events", Target: "events-copy", Range: FetchRange{StartOffset: -1, EndOffset: -1}, Limit: 2}
+	stats, err := CopyMessages(consumer, producer, opts, nil)
+	if err != nil {
+		t.Fatalf("CopyMessages() error = %v", err)
+	}
+	if stats.Copied != 2 {
+		t.Errorf("Expected 2 copied messages, got %d", stats.Copied)
+	}
+}
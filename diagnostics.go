@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Diagnostic step outcomes
+const (
+	diagPassed  = "passed"
+	diagFailed  = "failed"
+	diagSkipped = "skipped"
+)
+
+// diagnosticTimeout bounds every network operation of a diagnostics run
+const diagnosticTimeout = 5 * time.Second
+
+// apiKeyNames names the Kafka APIs most relevant to this tool
+var apiKeyNames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	15: "DescribeGroups",
+	16: "ListGroups",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	20: "DeleteTopics",
+	21: "DeleteRecords",
+	32: "DescribeConfigs",
+	33: "AlterConfigs",
+	36: "SaslAuthenticate",
+	37: "CreatePartitions",
+	60: "DescribeCluster",
+}
+
+// apiKeyName returns a readable name for a Kafka API key
+func apiKeyName(key int16) string {
+	if name, ok := apiKeyNames[key]; ok {
+		return name
+	}
+	return fmt.Sprintf("ApiKey(%d)", key)
+}
+
+// DiagnosticStep is the outcome of a single connection check against one broker
+type DiagnosticStep struct {
+	Broker   string
+	Name     string
+	Status   string
+	Duration time.Duration
+	Details  []string
+	Err      error
+}
+
+// RunDiagnostics checks every configured broker step by step (DNS, TCP, TLS
+// handshake, ApiVersions and Metadata) and calls report after each step, so
+// that network, certificate and ACL problems can be told apart
+func RunDiagnostics(config *Config, report func(DiagnosticStep)) {
+	saramaConfig, configErr := newSaramaConfig(config)
+	if configErr == nil {
+		saramaConfig.Net.DialTimeout = diagnosticTimeout
+		saramaConfig.Net.ReadTimeout = diagnosticTimeout
+		saramaConfig.Net.WriteTimeout = diagnosticTimeout
+	}
+
+	for _, addr := range config.Brokers {
+		d := &brokerDiagnosis{
+			addr:         strings.TrimSpace(addr),
+			topic:        config.Topic,
+			saramaConfig: saramaConfig,
+			configErr:    configErr,
+			report:       report,
+		}
+		d.run()
+	}
+}
+
+type brokerDiagnosis struct {
+	addr         string
+	topic        string
+	saramaConfig *sarama.Config
+	configErr    error
+	report       func(DiagnosticStep)
+	failed       bool
+}
+
+// errSkipped is returned by a step that does not apply to the current config
+type errSkipped struct{ reason string }
+
+func (e errSkipped) Error() string { return e.reason }
+
+func (d *brokerDiagnosis) run() {
+	d.step("DNS lookup", d.checkDNS)
+	d.step("TCP connect", d.checkTCP)
+	d.step("TLS handshake", d.checkTLS)
+
+	if d.failed {
+		d.step("ApiVersions request", nil)
+		d.step("Metadata request", nil)
+		return
+	}
+
+	broker := sarama.NewBroker(d.addr)
+	openErr := broker.Open(d.saramaConfig)
+	defer func() { _ = broker.Close() }()
+
+	d.step("ApiVersions request", func() ([]string, error) {
+		if openErr != nil {
+			return nil, openErr
+		}
+		return d.checkAPIVersions(broker)
+	})
+	d.step("Metadata request", func() ([]string, error) { return d.checkMetadata(broker) })
+}
+
+// step runs check and reports its outcome; once a step has failed the
+// remaining steps for the broker are reported as skipped
+func (d *brokerDiagnosis) step(name string, check func() ([]string, error)) {
+	result := DiagnosticStep{Broker: d.addr, Name: name}
+
+	if d.failed || check == nil {
+		result.Status = diagSkipped
+		result.Details = []string{"skipped after an earlier failure"}
+		d.report(result)
+		return
+	}
+
+	start := time.Now()
+	details, err := check()
+	result.Duration = time.Since(start)
+	result.Details = details
+
+	var skipped errSkipped
+	switch {
+	case errors.As(err, &skipped):
+		result.Status = diagSkipped
+		result.Details = append(result.Details, skipped.reason)
+	case err != nil:
+		result.Status = diagFailed
+		result.Err = err
+		d.failed = true
+	default:
+		result.Status = diagPassed
+	}
+
+	d.report(result)
+}
+
+func (d *brokerDiagnosis) checkDNS() ([]string, error) {
+	host, _, err := net.SplitHostPort(d.addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid broker address %q: %w", d.addr, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{"resolved to " + strings.Join(addrs, ", ")}, nil
+}
+
+func (d *brokerDiagnosis) checkTCP() ([]string, error) {
+	conn, err := net.DialTimeout("tcp", d.addr, diagnosticTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	return []string{fmt.Sprintf("connected %s → %s", conn.LocalAddr(), conn.RemoteAddr())}, nil
+}
+
+func (d *brokerDiagnosis) checkTLS() ([]string, error) {
+	if d.configErr != nil {
+		return nil, d.configErr
+	}
+	if !d.saramaConfig.Net.TLS.Enable {
+		return nil, errSkipped{"TLS is disabled"}
+	}
+
+	host, _, _ := net.SplitHostPort(d.addr)
+	tlsConfig := d.saramaConfig.Net.TLS.Config.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+
+	dialer := &net.Dialer{Timeout: diagnosticTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", d.addr, tlsConfig)
+	if err != nil {
+		details := []string{tlsFailureHint(err)}
+		// Repeat the handshake without verification to show what the broker presents
+		tlsConfig.InsecureSkipVerify = true // #nosec G402 -- only used to display the chain after verification failed
+		if insecureConn, insecureErr := tls.DialWithDialer(dialer, "tcp", d.addr, tlsConfig); insecureErr == nil {
+			details = append(details, describeCertificateChain(insecureConn.ConnectionState().PeerCertificates)...)
+			_ = insecureConn.Close()
+		}
+		return details, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	state := conn.ConnectionState()
+	details := []string{fmt.Sprintf("%s, %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))}
+	details = append(details, describeCertificateChain(state.PeerCertificates)...)
+	if tlsConfig.InsecureSkipVerify {
+		details = append(details, "certificate verification is disabled (tls_insecure_skip_verify), the chain above is not trusted")
+	}
+
+	return details, nil
+}
+
+// tlsFailureHint explains the most common TLS handshake failures
+func tlsFailureHint(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "server certificate is not signed by the configured CA"
+	case errors.As(err, &hostnameErr):
+		return "server certificate does not match the broker host name"
+	case errors.As(err, &invalidCert):
+		return "server certificate is not valid (expired or not yet valid?)"
+	case strings.Contains(err.Error(), "bad certificate"), strings.Contains(err.Error(), "certificate required"):
+		return "broker rejected the client certificate"
+	default:
+		return "TLS handshake failed"
+	}
+}
+
+// describeCertificateChain summarizes the certificates presented by a broker
+func describeCertificateChain(chain []*x509.Certificate) []string {
+	var lines []string
+	for i, cert := range chain {
+		lines = append(lines, fmt.Sprintf("[%d] %s (issuer: %s, expires %s)",
+			i, cert.Subject, cert.Issuer, cert.NotAfter.Format(time.DateOnly)))
+
+		if i == 0 {
+			sans := append([]string{}, cert.DNSNames...)
+			for _, ip := range cert.IPAddresses {
+				sans = append(sans, ip.String())
+			}
+			if len(sans) > 0 {
+				lines = append(lines, "SANs: "+strings.Join(sans, ", "))
+			}
+		}
+	}
+	return lines
+}
+
+func (d *brokerDiagnosis) checkAPIVersions(broker *sarama.Broker) ([]string, error) {
+	resp, err := broker.ApiVersions(&sarama.ApiVersionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if kerr := sarama.KError(resp.ErrorCode); kerr != sarama.ErrNoError {
+		return nil, kerr
+	}
+
+	details := []string{fmt.Sprintf("broker supports %d APIs", len(resp.ApiKeys))}
+	var versions []string
+	for _, key := range resp.ApiKeys {
+		if key.ApiKey <= 3 { // Produce, Fetch, ListOffsets, Metadata
+			versions = append(versions, fmt.Sprintf("%s v%d-v%d", apiKeyName(key.ApiKey), key.MinVersion, key.MaxVersion))
+		}
+	}
+	if len(versions) > 0 {
+		details = append(details, strings.Join(versions, ", "))
+	}
+
+	return details, nil
+}
+
+func (d *brokerDiagnosis) checkMetadata(broker *sarama.Broker) ([]string, error) {
+	var topics []string
+	if d.topic != "" {
+		topics = []string{d.topic}
+	}
+
+	req := sarama.NewMetadataRequest(d.saramaConfig.Version, topics)
+	req.AllowAutoTopicCreation = false
+
+	resp, err := broker.GetMetadata(req)
+	if err != nil {
+		return nil, err
+	}
+
+	details := []string{fmt.Sprintf("%d broker(s), controller %d", len(resp.Brokers), resp.ControllerID)}
+	if resp.ClusterID != nil {
+		details = append(details, "cluster ID "+*resp.ClusterID)
+	}
+
+	for _, topic := range resp.Topics {
+		if topic.Name != d.topic {
+			continue
+		}
+		switch topic.Err {
+		case sarama.ErrNoError:
+			details = append(details, fmt.Sprintf("topic %q: %d partition(s)", topic.Name, len(topic.Partitions)))
+		case sarama.ErrTopicAuthorizationFailed:
+			return append(details, "not authorized to describe the topic, check the ACLs for this principal"), topic.Err
+		case sarama.ErrUnknownTopicOrPartition:
+			return append(details, fmt.Sprintf("topic %q does not exist", topic.Name)), topic.Err
+		default:
+			return details, topic.Err
+		}
+	}
+
+	return details, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func collectDiagnostics(config *Config) []DiagnosticStep {
+	var steps []DiagnosticStep
+	RunDiagnostics(config, func(step DiagnosticStep) {
+		steps = append(steps, step)
+	})
+	return steps
+}
+
+func findStep(steps []DiagnosticStep, name string) *DiagnosticStep {
+	for i := range steps {
+		if steps[i].Name == name {
+			return &steps[i]
+		}
+	}
+	return nil
+}
+
+func TestRunDiagnostics_MockBroker(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetController(broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+	})
+
+	steps := collectDiagnostics(&Config{Brokers: []string{broker.Addr()}, Topic: testTopic})
+
+	if len(steps) != 5 {
+		t.Fatalf("Expected 5 steps, got %d", len(steps))
+	}
+
+	for _, step := range steps {
+		if step.Broker != broker.Addr() {
+			t.Errorf("Expected broker %s, got %s", broker.Addr(), step.Broker)
+		}
+		if step.Status == diagFailed {
+			t.Errorf("Step %s failed: %v", step.Name, step.Err)
+		}
+	}
+
+	if tlsStep := findStep(steps, "TLS handshake"); tlsStep == nil || tlsStep.Status != diagSkipped {
+		t.Error("Expected TLS handshake to be skipped without mTLS")
+	}
+
+	metadata := findStep(steps, "Metadata request")
+	if metadata == nil || !strings.Contains(strings.Join(metadata.Details, "\n"), "1 partition(s)") {
+		t.Errorf("Expected topic partitions in metadata details, got %+v", metadata)
+	}
+}
+
+func TestRunDiagnostics_TopicAuthorizationFailed(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetError(testTopic, sarama.ErrTopicAuthorizationFailed),
+	})
+
+	steps := collectDiagnostics(&Config{Brokers: []string{broker.Addr()}, Topic: testTopic})
+
+	metadata := findStep(steps, "Metadata request")
+	if metadata == nil || metadata.Status != diagFailed {
+		t.Fatalf("Expected metadata step to fail, got %+v", metadata)
+	}
+	if !strings.Contains(strings.Join(metadata.Details, "\n"), "ACLs") {
+		t.Errorf("Expected ACL hint, got %v", metadata.Details)
+	}
+}
+
+func TestRunDiagnostics_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	steps := collectDiagnostics(&Config{Brokers: []string{addr}, Topic: testTopic})
+
+	if dns := findStep(steps, "DNS lookup"); dns == nil || dns.Status != diagPassed {
+		t.Errorf("Expected DNS lookup to pass, got %+v", dns)
+	}
+
+	if tcp := findStep(steps, "TCP connect"); tcp == nil || tcp.Status != diagFailed {
+		t.Errorf("Expected TCP connect to fail, got %+v", tcp)
+	}
+
+	for _, name := range []string{"TLS handshake", "ApiVersions request", "Metadata request"} {
+		if step := findStep(steps, name); step == nil || step.Status != diagSkipped {
+			t.Errorf("Expected %s to be skipped, got %+v", name, step)
+		}
+	}
+}
+
+func TestRunDiagnostics_InvalidAddress(t *testing.T) {
+	steps := collectDiagnostics(&Config{Brokers: []string{"localhost"}})
+
+	dns := findStep(steps, "DNS lookup")
+	if dns == nil || dns.Status != diagFailed {
+		t.Fatalf("Expected DNS lookup to fail for address without port, got %+v", dns)
+	}
+	if !strings.Contains(dns.Err.Error(), "invalid broker address") {
+		t.Errorf("Expected invalid address error, got %v", dns.Err)
+	}
+}
+
+func TestRunDiagnostics_UntrustedServerCertificate(t *testing.T) {
+	dir := t.TempDir()
+	validity := time.Now().Add(24 * time.Hour)
+	serverCert, serverKey := writeTestCertificate(t, dir, "server", time.Now().Add(-time.Hour), validity)
+	clientCert, clientKey := writeTestCertificate(t, dir, "client", time.Now().Add(-time.Hour), validity)
+
+	cert, err := tls.LoadX509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	config := &Config{
+		Brokers:  []string{net.JoinHostPort("localhost", port)},
+		CertFile: clientCert,
+		KeyFile:  clientKey,
+		CAFile:   clientCert, // not the server's issuer
+		UseAuth:  true,
+	}
+
+	steps := collectDiagnostics(config)
+
+	tlsStep := findStep(steps, "TLS handshake")
+	if tlsStep == nil || tlsStep.Status != diagFailed {
+		t.Fatalf("Expected TLS handshake to fail, got %+v", tlsStep)
+	}
+
+	details := strings.Join(tlsStep.Details, "\n")
+	if !strings.Contains(details, "not signed by the configured CA") {
+		t.Errorf("Expected unknown authority hint, got %q", details)
+	}
+	if !strings.Contains(details, "CN=server") {
+		t.Errorf("Expected server certificate chain in details, got %q", details)
+	}
+	if !strings.Contains(details, "SANs: localhost") {
+		t.Errorf("Expected SANs in details, got %q", details)
+	}
+}
+
+func TestAPIKeyName(t *testing.T) {
+	if name := apiKeyName(0); name != "Produce" {
+		t.Errorf("Expected Produce, got %s", name)
+	}
+	if name := apiKeyName(999); name != "ApiKey(999)" {
+		t.Errorf("Expected ApiKey(999), got %s", name)
+	}
+}
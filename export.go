@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Export formats
+const (
+	exportJSONL = "jsonl"
+	exportCSV   = "csv"
+	exportRaw   = "raw"
+)
+
+// encodingBase64 marks exported keys and values that are not text
+const encodingBase64 = "base64"
+
+// ExportRecord is one line of a JSONL export and of a file for produce
+// --file. Key and Value hold the decoded JSON document for the json serde, a
+// string for text and a base64 string with KeyEncoding/ValueEncoding set to
+// "base64" for binary data.
+type ExportRecord struct {
+	Topic         string          `json:"topic,omitempty"`
+	Partition     *int32          `json:"partition,omitempty"`
+	Offset        *int64          `json:"offset,omitempty"`
+	Timestamp     *time.Time      `json:"timestamp,omitempty"`
+	Key           json.RawMessage `json:"key,omitempty"`
+	KeyEncoding   string          `json:"key_encoding,omitempty"`
+	Value         json.RawMessage `json:"value"`
+	ValueEncoding string          `json:"value_encoding,omitempty"`
+	Headers       []ExportHeader  `json:"headers,omitempty"`
+}
+
+// ExportHeader is a record header in an export
+type ExportHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// decodeField converts message bytes to their export form according to
+// serde. Compact JSON documents are embedded byte for byte; JSON strings and
+// documents with insignificant whitespace, which a JSONL line cannot keep,
+// are exported as text so that the value round-trips unchanged. So is a
+// literal null, which would otherwise read back as a tombstone.
+func decodeField(data []byte, serde string) (json.RawMessage, string) {
+	if data == nil {
+		return json.RawMessage("null"), ""
+	}
+
+	if serde != serdeByteArray && utf8.Valid(data) {
+		if serde == serdeJSON && len(data) > 0 && data[0] != '"' && !bytes.Equal(data, []byte("null")) && json.Valid(data) {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, data); err == nil && bytes.Equal(compact.Bytes(), data) {
+				return json.RawMessage(data), ""
+			}
+		}
+		text, _ := json.Marshal(string(data))
+		return text, ""
+	}
+
+	encoded, _ := json.Marshal(base64.StdEncoding.EncodeToString(data))
+	return encoded, encodingBase64
+}
+
+// encodeField is the inverse of decodeField
+func encodeField(raw json.RawMessage, encoding string) ([]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	if raw[0] != '"' {
+		if encoding != "" {
+			return nil, fmt.Errorf("%s encoded field must be a string", encoding)
+		}
+		return raw, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return nil, err
+	}
+
+	switch encoding {
+	case "":
+		return []byte(text), nil
+	case encodingBase64:
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return nil, fmt.Errorf("unknown encoding %q", encoding)
+}
+
+// fieldText returns the export form of a field as a single CSV cell
+func fieldText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	if bytes.Equal(raw, []byte("null")) {
+		return ""
+	}
+	return string(raw)
+}
+
+// newExportRecord converts a consumed message using the configured serdes
+func newExportRecord(msg ConsumedMessage, keySerde, valueSerde string) ExportRecord {
+	partition, offset := msg.Partition, msg.Offset
+	record := ExportRecord{
+		Topic:     msg.Topic,
+		Partition: &partition,
+		Offset:    &offset,
+	}
+	if !msg.Timestamp.IsZero() {
+		timestamp := msg.Timestamp.UTC()
+		record.Timestamp = &timestamp
+	}
+	if msg.Key != nil {
+		record.Key, record.KeyEncoding = decodeField(msg.Key, keySerde)
+	}
+	record.Value, record.ValueEncoding = decodeField(msg.Value, valueSerde)
+	for _, header := range msg.Headers {
+		record.Headers = append(record.Headers, ExportHeader(header))
+	}
+	return record
+}
+
+// OutgoingMessage converts a record back into a message for Send
+func (r ExportRecord) OutgoingMessage() (OutgoingMessage, error) {
+	msg := OutgoingMessage{Partition: -1}
+
+	var err error
+	if msg.Key, err = encodeField(r.Key, r.KeyEncoding); err != nil {
+		return OutgoingMessage{}, fmt.Errorf("invalid key: %w", err)
+	}
+	if msg.Value, err = encodeField(r.Value, r.ValueEncoding); err != nil {
+		return OutgoingMessage{}, fmt.Errorf("invalid value: %w", err)
+	}
+	if r.Partition != nil {
+		msg.Partition = *r.Partition
+	}
+	if r.Timestamp != nil {
+		msg.Timestamp = *r.Timestamp
+	}
+	for _, header := range r.Headers {
+		msg.Headers = append(msg.Headers, MessageHeader(header))
+	}
+	return msg, nil
+}
+
+// Exporter writes consumed messages in one of the export formats. Flush
+// writes out buffered records and may be called any number of times; Close
+// flushes and releases the exporter.
+type Exporter interface {
+	Write(msg ConsumedMessage) error
+	Flush() error
+	Close() error
+}
+
+// NewExporter creates an exporter for format. JSONL and CSV are written to
+// w; raw exports write one file per message into the directory dir.
+func NewExporter(format string, w io.Writer, dir string, config *Config) (Exporter, error) {
+	switch format {
+	case exportJSONL:
+		return &jsonlExporter{w: bufio.NewWriter(w), config: config}, nil
+	case exportCSV:
+		exporter := &csvExporter{w: csv.NewWriter(w), config: config}
+		if err := exporter.w.Write(csvExportHeader); err != nil {
+			return nil, err
+		}
+		return exporter, nil
+	case exportRaw:
+		if dir == "" {
+			return nil, fmt.Errorf("raw export needs an output directory")
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		return &rawExporter{dir: dir}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q (use jsonl, csv or raw)", format)
+}
+
+type jsonlExporter struct {
+	w      *bufio.Writer
+	config *Config
+}
+
+func (e *jsonlExporter) Write(msg ConsumedMessage) error {
+	keySerde, valueSerde := e.config.serdesFor(msg.Topic)
+	// Without HTML escaping, embedded documents keep their bytes
+	encoder := json.NewEncoder(e.w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(newExportRecord(msg, keySerde, valueSerde))
+}
+
+func (e *jsonlExporter) Flush() error {
+	return e.w.Flush()
+}
+
+func (e *jsonlExporter) Close() error {
+	return e.Flush()
+}
+
+var csvExportHeader = []string{"topic", "partition", "offset", "timestamp", "key", "key_encoding", "value", "value_encoding", "headers"}
+
+type csvExporter struct {
+	w      *csv.Writer
+	config *Config
+}
+
+func (e *csvExporter) Write(msg ConsumedMessage) error {
+	keySerde, valueSerde := e.config.serdesFor(msg.Topic)
+	record := newExportRecord(msg, keySerde, valueSerde)
+
+	timestamp := ""
+	if record.Timestamp != nil {
+		timestamp = record.Timestamp.Format(time.RFC3339Nano)
+	}
+	headers := ""
+	if len(record.Headers) > 0 {
+		encoded, err := json.Marshal(record.Headers)
+		if err != nil {
+			return err
+		}
+		headers = string(encoded)
+	}
+
+	return e.w.Write([]string{
+		record.Topic,
+		strconv.Itoa(int(msg.Partition)),
+		strconv.FormatInt(msg.Offset, 10),
+		timestamp,
+		fieldText(record.Key),
+		record.KeyEncoding,
+		fieldText(record.Value),
+		record.ValueEncoding,
+		headers,
+	})
+}
+
+func (e *csvExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) Close() error {
+	return e.Flush()
+}
+
+// rawExporter writes the value of every message to TOPIC-PARTITION-OFFSET.bin
+// and its key, if any, to TOPIC-PARTITION-OFFSET.key
+type rawExporter struct {
+	dir string
+}
+
+func (e *rawExporter) Write(msg ConsumedMessage) error {
+	base := filepath.Join(e.dir, fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset))
+	if msg.Key != nil {
+		if err := os.WriteFile(base+".key", msg.Key, 0o644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(base+".bin", msg.Value, 0o644)
+}
+
+func (e *rawExporter) Flush() error {
+	return nil // every message is written to its own file right away
+}
+
+func (e *rawExporter) Close() error {
+	return nil
+}
+
+// ReadRecords reads a JSONL file of ExportRecords, as written by the jsonl
+// export, and passes them to handle with their 1-based line number. Blank
+// lines are skipped.
+func ReadRecords(r io.Reader, handle func(line int, record ExportRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var record ExportRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := handle(line, record); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
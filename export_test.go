@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeField_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		serde    string
+		want     string
+		encoding string
+	}{
+		{name: "json object", data: []byte(`{"id":1,"html":"<b>"}`), serde: serdeJSON, want: `{"id":1,"html":"<b>"}`},
+		{name: "formatted json", data: []byte("{\"id\": 1}\n"), serde: serdeJSON, want: `"{\"id\": 1}\n"`},
+		{name: "json string", data: []byte(`"abc"`), serde: serdeJSON, want: `"\"abc\""`},
+		{name: "invalid json", data: []byte(`not json`), serde: serdeJSON, want: `"not json"`},
+		{name: "text", data: []byte(`{"id":1}`), serde: serdeString, want: `"{\"id\":1}"`},
+		{name: "bytearray", data: []byte{0x00, 0xff}, serde: serdeByteArray, want: `"AP8="`, encoding: encodingBase64},
+		{name: "invalid utf-8", data: []byte{0xff}, serde: serdeString, want: `"/w=="`, encoding: encodingBase64},
+		{name: "null", data: nil, serde: serdeJSON, want: `null`},
+		{name: "json null", data: []byte("null"), serde: serdeJSON, want: `"null"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, encoding := decodeField(tt.data, tt.serde)
+			if string(raw) != tt.want || encoding != tt.encoding {
+				t.Errorf("Expected %s (%q), got %s (%q)", tt.want, tt.encoding, raw, encoding)
+			}
+
+			data, err := encodeField(raw, encoding)
+			if err != nil {
+				t.Fatalf("encodeField() error = %v", err)
+			}
+			if !bytes.Equal(data, tt.data) {
+				t.Errorf("Expected round trip to %q, got %q", tt.data, data)
+			}
+		})
+	}
+}
+
+func TestEncodeField_Invalid(t *testing.T) {
+	if _, err := encodeField([]byte(`{"a":1}`), encodingBase64); err == nil {
+		t.Error("Expected error for base64 field that is not a string")
+	}
+	if _, err := encodeField([]byte(`"!!"`), encodingBase64); err == nil {
+		t.Error("Expected error for invalid base64")
+	}
+	if _, err := encodeField([]byte(`"x"`), "hex"); err == nil {
+		t.Error("Expected error for unknown encoding")
+	}
+}
+
+func testExportMessages() []ConsumedMessage {
+	return []ConsumedMessage{
+		{
+			Topic:     "events",
+			Partition: 1,
+			Offset:    7,
+			Timestamp: time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC),
+			Key:       []byte("user-1"),
+			Value:     []byte(`{"n":1,"tag":"<b>"}`),
+			Headers:   []MessageHeader{{Key: "source", Value: "web"}},
+		},
+		{Topic: "events", Partition: 0, Offset: 3, Value: []byte{0xff, 0xfe}},
+	}
+}
+
+func TestJSONLExport_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := NewExporter(exportJSONL, &buf, "", &Config{KeySerde: serdeString, ValueSerde: serdeJSON})
+	if err != nil {
+		t.Fatalf("NewExporter() error = %v", err)
+	}
+	for _, msg := range testExportMessages() {
+		if err := exporter.Write(msg); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+	want := `{"topic":"events","partition":1,"offset":7,"timestamp":"2024-12-01T10:00:00Z","key":"user-1","value":{"n":1,"tag":"<b>"},"headers":[{"key":"source","value":"web"}]}`
+	if lines[0] != want {
+		t.Errorf("Expected %s, got %s", want, lines[0])
+	}
+
+	var messages []OutgoingMessage
+	err = ReadRecords(&buf, func(_ int, record ExportRecord) error {
+		msg, err := record.OutgoingMessage()
+		messages = append(messages, msg)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ReadRecords() error = %v", err)
+	}
+
+	for i, original := range testExportMessages() {
+		msg := messages[i]
+		if !bytes.Equal(msg.Key, original.Key) || !bytes.Equal(msg.Value, original.Value) {
+			t.Errorf("Message %d: expected %q=%q, got %q=%q", i, original.Key, original.Value, msg.Key, msg.Value)
+		}
+		if msg.Partition != original.Partition || len(msg.Headers) != len(original.Headers) {
+			t.Errorf("Message %d: expected partition %d and %d header(s), got %+v", i, original.Partition, len(original.Headers), msg)
+		}
+	}
+	if !messages[0].Timestamp.Equal(testExportMessages()[0].Timestamp) {
+		t.Errorf("Expected timestamp to be kept, got %v", messages[0].Timestamp)
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	input := `{"value":"a"}
+
+{"key":"k","value":{"n":1}}
+not json`
+
+	var values []string
+	err := ReadRecords(strings.NewReader(input), func(_ int, record ExportRecord) error {
+		msg, err := record.OutgoingMessage()
+		values = append(values, string(msg.Value))
+		if msg.Partition != -1 {
+			t.Errorf("Expected no partition, got %d", msg.Partition)
+		}
+		return err
+	})
+
+	if err == nil || !strings.HasPrefix(err.Error(), "line 4:") {
+		t.Errorf("Expected error on line 4, got %v", err)
+	}
+	if strings.Join(values, " ") != `a {"n":1}` {
+		t.Errorf("Expected values before the error, got %v", values)
+	}
+}
+
+func TestCSVExport(t *testing.T) {
+	var buf bytes.Buffer
+	exporter, err := NewExporter(exportCSV, &buf, "", &Config{KeySerde: serdeString, ValueSerde: serdeJSON})
+	if err != nil {
+		t.Fatalf("NewExporter() error = %v", err)
+	}
+	for _, msg := range testExportMessages() {
+		if err := exporter.Write(msg); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(csvExportHeader, ",") {
+		t.Fatalf("Expected header and 2 rows, got %v", rows)
+	}
+
+	want := []string{"events", "1", "7", "2024-12-01T10:00:00Z", "user-1", "", `{"n":1,"tag":"<b>"}`, "", `[{"key":"source","value":"web"}]`}
+	if strings.Join(rows[1], "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %v, got %v", want, rows[1])
+	}
+	if rows[2][4] != "" || rows[2][3] != "" || rows[2][6] != "//4=" || rows[2][7] != encodingBase64 {
+		t.Errorf("Expected binary value as base64, got %v", rows[2])
+	}
+}
+
+func TestExporter_Flush(t *testing.T) {
+	for _, format := range []string{exportJSONL, exportCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			exporter, err := NewExporter(format, &buf, "", &Config{KeySerde: serdeString, ValueSerde: serdeJSON})
+			if err != nil {
+				t.Fatalf("NewExporter() error = %v", err)
+			}
+			for _, msg := range testExportMessages() {
+				if err := exporter.Write(msg); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+				if err := exporter.Flush(); err != nil {
+					t.Fatalf("Flush() error = %v", err)
+				}
+				if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+					t.Fatalf("Expected a flushed record, got %q", buf.String())
+				}
+			}
+			if err := exporter.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRawExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "raw")
+
+	exporter, err := NewExporter(exportRaw, nil, dir, &Config{})
+	if err != nil {
+		t.Fatalf("NewExporter() error = %v", err)
+	}
+	for _, msg := range testExportMessages() {
+		if err := exporter.Write(msg); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	files := map[string]string{
+		"events-1-7.key": "user-1",
+		"events-1-7.bin": `{"n":1,"tag":"<b>"}`,
+		"events-0-3.bin": "\xff\xfe",
+	}
+	for name, want := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("Expected %s to contain %q, got %q (%v)", name, want, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "events-0-3.key")); !os.IsNotExist(err) {
+		t.Error("Expected no key file for a message without key")
+	}
+}
+
+func TestNewExporter_Invalid(t *testing.T) {
+	if _, err := NewExporter("xml", &bytes.Buffer{}, "", &Config{}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := NewExporter(exportRaw, nil, "", &Config{}); err == nil {
+		t.Error("Expected error for raw export without directory")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// FanOutTarget is one of several topics the message view sends every message
+// to. Empty serdes fall back to those configured for the topic.
+// ValueTemplate, if set, replaces the typed value; it may reference {{key}},
+// {{value}}, {{topic}}, {{fanout_id}} (shared by all messages of one send)
+// and the scenario built-ins {{uuid}}, {{now}} and {{unix_ms}}.
+type FanOutTarget struct {
+	Topic         string `json:"topic"`
+	KeySerde      string `json:"key_serde,omitempty"`
+	ValueSerde    string `json:"value_serde,omitempty"`
+	ValueTemplate string `json:"value_template,omitempty"`
+}
+
+// FanOutResult is the outcome of sending to one fan-out target
+type FanOutResult struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Sent      OutgoingMessage
+	Err       error
+}
+
+// parseFanOutTargets parses the fan-out field of the config view, a comma
+// separated list of topic[:key_serde[:value_serde]]. Value templates can
+// only be set in the config file; existing ones are kept for topics that
+// are still listed.
+func parseFanOutTargets(text string, existing []FanOutTarget) []FanOutTarget {
+	var targets []FanOutTarget
+	for _, item := range strings.Split(text, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if parts[0] == "" {
+			continue
+		}
+		target := FanOutTarget{Topic: strings.TrimSpace(parts[0])}
+		if len(parts) > 1 {
+			target.KeySerde = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			target.ValueSerde = strings.TrimSpace(strings.Join(parts[2:], ":"))
+		}
+		for _, old := range existing {
+			if old.Topic == target.Topic {
+				target.ValueTemplate = old.ValueTemplate
+				break
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// formatFanOutTargets is the inverse of parseFanOutTargets
+func formatFanOutTargets(targets []FanOutTarget) string {
+	items := make([]string, 0, len(targets))
+	for _, target := range targets {
+		item := target.Topic
+		switch {
+		case target.ValueSerde != "":
+			item += ":" + target.KeySerde + ":" + target.ValueSerde
+		case target.KeySerde != "":
+			item += ":" + target.KeySerde
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ", ")
+}
+
+// fanOutTopics lists the topics of targets
+func fanOutTopics(targets []FanOutTarget) []string {
+	topics := make([]string, 0, len(targets))
+	for _, target := range targets {
+		topics = append(topics, target.Topic)
+	}
+	return topics
+}
+
+// render returns the value to send to the target
+func (t FanOutTarget) render(key, value, fanOutID string) (string, error) {
+	if t.ValueTemplate == "" {
+		return value, nil
+	}
+	return expandVariables(t.ValueTemplate, map[string]string{
+		"key":       key,
+		"value":     value,
+		"topic":     t.Topic,
+		"fanout_id": fanOutID,
+	})
+}
+
+// FanOut sends key and value to every target in turn. A failure for one
+// target does not stop the others; every target gets a result.
+func (p *KafkaProducer) FanOut(targets []FanOutTarget, key, value string) []FanOutResult {
+	fanOutID := newCorrelationID()
+	results := make([]FanOutResult, 0, len(targets))
+	for _, target := range targets {
+		result := FanOutResult{Topic: target.Topic}
+		result.Err = func() error {
+			rendered, err := target.render(key, value, fanOutID)
+			if err != nil {
+				return fmt.Errorf("value template: %w", err)
+			}
+			keySerde, valueSerde := p.config.serdesFor(target.Topic)
+			msg, err := p.prepare(target.Topic, key, rendered,
+				cmp.Or(target.KeySerde, keySerde), cmp.Or(target.ValueSerde, valueSerde))
+			if err != nil {
+				return err
+			}
+			result.Sent = msg
+			result.Partition, result.Offset, err = p.Send(msg)
+			return err
+		}()
+		results = append(results, result)
+	}
+	return results
+}
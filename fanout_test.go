@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestParseFanOutTargets(t *testing.T) {
+	existing := []FanOutTarget{{Topic: "audit", ValueTemplate: `{"order":{{value}}}`}}
+
+	tests := []struct {
+		name string
+		text string
+		want []FanOutTarget
+	}{
+		{name: "empty", text: " ", want: nil},
+		{name: "topics", text: "orders, audit", want: []FanOutTarget{{Topic: "orders"}, {Topic: "audit", ValueTemplate: `{"order":{{value}}}`}}},
+		{name: "serdes", text: "orders:string:json,audit::bytearray", want: []FanOutTarget{
+			{Topic: "orders", KeySerde: "string", ValueSerde: "json"},
+			{Topic: "audit", ValueSerde: "bytearray", ValueTemplate: `{"order":{{value}}}`},
+		}},
+		{name: "trailing comma", text: "orders,", want: []FanOutTarget{{Topic: "orders"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseFanOutTargets(tt.text, existing)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+			if tt.want != nil {
+				if again := parseFanOutTargets(formatFanOutTargets(got), existing); !reflect.DeepEqual(again, got) {
+					t.Errorf("Expected %q to parse back to %+v, got %+v", formatFanOutTargets(got), got, again)
+				}
+			}
+		})
+	}
+}
+
+func TestKafkaProducer_FanOut(t *testing.T) {
+	var sent []*sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			if msg.Topic == "missing" {
+				return 0, 0, sarama.ErrUnknownTopicOrPartition
+			}
+			sent = append(sent, msg)
+			return 1, int64(len(sent)), nil
+		}},
+		config: &Config{Topic: "orders", KeySerde: serdeJSON, ValueSerde: serdeJSON},
+	}
+
+	targets := []FanOutTarget{
+		{Topic: "orders"},
+		{Topic: "missing"},
+		{Topic: "audit", ValueSerde: serdeString, ValueTemplate: `{"topic":"{{topic}}","key":"{{key}}","id":"{{fanout_id}}","order":{{value}}}`},
+		{Topic: "broken", ValueTemplate: "{{nope}}"},
+	}
+	results := producer.FanOut(targets, "order-1", `{"n":1}`)
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	if results[0].Err != nil || results[0].Offset != 1 || string(results[0].Sent.Value) != `{"n":1}` {
+		t.Errorf("Expected orders to get the typed value, got %+v", results[0])
+	}
+	if !errors.Is(results[1].Err, sarama.ErrUnknownTopicOrPartition) {
+		t.Errorf("Expected the missing topic to fail, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || !strings.HasPrefix(string(results[2].Sent.Value), `{"topic":"audit","key":"order-1","id":"`) {
+		t.Errorf("Expected the audit template to be rendered, got %+v", results[2])
+	}
+	if results[3].Err == nil || !strings.Contains(results[3].Err.Error(), `undefined variable "nope"`) {
+		t.Errorf("Expected a template error, got %v", results[3].Err)
+	}
+
+	if len(sent) != 2 || string(sent[1].Key.(sarama.ByteEncoder)) != "order-1" {
+		t.Fatalf("Expected 2 sent messages with the key, got %d", len(sent))
+	}
+
+	// The messages of one send share a fan-out ID, the next send gets a new one
+	idTargets := []FanOutTarget{{Topic: "a", ValueTemplate: "{{fanout_id}}"}, {Topic: "b", ValueTemplate: "{{fanout_id}}"}}
+	first := producer.FanOut(idTargets, "", "v")
+	second := producer.FanOut(idTargets, "", "v")
+	if string(first[0].Sent.Value) != string(first[1].Sent.Value) {
+		t.Errorf("Expected one fan-out ID per send, got %s and %s", first[0].Sent.Value, first[1].Sent.Value)
+	}
+	if string(first[0].Sent.Value) == string(second[0].Sent.Value) {
+		t.Error("Expected a new fan-out ID for every send")
+	}
+}
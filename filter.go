@@ -0,0 +1,475 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter is a compiled filter expression over consumed records, e.g.
+//
+//	key =~ "^user-" and header.source == web and $.total >= 100
+//
+// Fields are key, value, partition, offset, header.NAME and JSON paths into
+// the value ($.user.id, $.items[0].sku). Operators are ==, !=, =~, !~, <, <=,
+// > and >=, combined with and/&&, or/|| and not/!. A field on its own tests
+// that it is present. Comparisons are numeric when both sides are numbers;
+// comparisons on a missing field are false.
+type Filter struct {
+	text string
+	root filterNode
+}
+
+// ParseFilter compiles a filter expression. An empty expression matches
+// every record.
+func ParseFilter(text string) (*Filter, error) {
+	filter := &Filter{text: strings.TrimSpace(text)}
+	if filter.text == "" {
+		return filter, nil
+	}
+
+	tokens, err := lexFilter(filter.text)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	if filter.root, err = p.parseOr(); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos+1)
+	}
+	return filter, nil
+}
+
+// Match reports whether msg passes the filter. A nil filter matches
+// everything.
+func (f *Filter) Match(msg ConsumedMessage) bool {
+	if f == nil || f.root == nil {
+		return true
+	}
+	return f.root.eval(&filterContext{msg: &msg})
+}
+
+func (f *Filter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.text
+}
+
+// filterContext decodes the JSON value of a record at most once per match
+type filterContext struct {
+	msg     *ConsumedMessage
+	doc     any
+	decoded bool
+	isJSON  bool
+}
+
+func (c *filterContext) jsonPath(path string) (string, bool) {
+	if !c.decoded {
+		c.doc, c.isJSON = decodeJSON(c.msg.Value)
+		c.decoded = true
+	}
+	if !c.isJSON {
+		return "", false
+	}
+	return walkJSONPath(c.doc, path)
+}
+
+// decodeJSON decodes a JSON document keeping numbers as json.Number
+func decodeJSON(data []byte) (any, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// walkJSONPath returns the value at a dotted path (user.id, items[0].sku or
+// items.0.sku) of a decoded JSON document. Strings are returned unquoted,
+// other values as compact JSON.
+func walkJSONPath(current any, path string) (string, bool) {
+	path = strings.ReplaceAll(strings.ReplaceAll(path, "[", "."), "]", "")
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			continue
+		}
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[part]
+			if !ok {
+				return "", false
+			}
+			current = value
+		case []any:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			current = node[index]
+		default:
+			return "", false
+		}
+	}
+
+	if s, ok := current.(string); ok {
+		return s, true
+	}
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+type filterNode interface {
+	eval(c *filterContext) bool
+}
+
+type andNode struct{ left, right filterNode }
+
+func (n andNode) eval(c *filterContext) bool { return n.left.eval(c) && n.right.eval(c) }
+
+type orNode struct{ left, right filterNode }
+
+func (n orNode) eval(c *filterContext) bool { return n.left.eval(c) || n.right.eval(c) }
+
+type notNode struct{ node filterNode }
+
+func (n notNode) eval(c *filterContext) bool { return !n.node.eval(c) }
+
+// Filter fields
+const (
+	filterKey = iota
+	filterValue
+	filterPartition
+	filterOffset
+	filterHeader
+	filterJSON
+)
+
+type filterField struct {
+	kind int
+	name string // header name or JSON path
+}
+
+func parseFilterField(text string) (filterField, bool) {
+	lower := strings.ToLower(text)
+	switch {
+	case lower == "key":
+		return filterField{kind: filterKey}, true
+	case lower == "value":
+		return filterField{kind: filterValue}, true
+	case lower == "partition":
+		return filterField{kind: filterPartition}, true
+	case lower == "offset":
+		return filterField{kind: filterOffset}, true
+	case strings.HasPrefix(lower, "header.") && len(text) > len("header."):
+		return filterField{kind: filterHeader, name: text[len("header."):]}, true
+	case strings.HasPrefix(lower, "headers.") && len(text) > len("headers."):
+		return filterField{kind: filterHeader, name: text[len("headers."):]}, true
+	case strings.HasPrefix(text, "$"):
+		return filterField{kind: filterJSON, name: strings.TrimPrefix(text[1:], ".")}, true
+	case strings.HasPrefix(lower, "value.") && len(text) > len("value."):
+		return filterField{kind: filterJSON, name: text[len("value."):]}, true
+	}
+	return filterField{}, false
+}
+
+// resolve returns the value of the field in the record and whether it is
+// present
+func (f filterField) resolve(c *filterContext) (string, bool) {
+	switch f.kind {
+	case filterKey:
+		return string(c.msg.Key), c.msg.Key != nil
+	case filterValue:
+		return string(c.msg.Value), c.msg.Value != nil
+	case filterPartition:
+		return strconv.Itoa(int(c.msg.Partition)), true
+	case filterOffset:
+		return strconv.FormatInt(c.msg.Offset, 10), true
+	case filterHeader:
+		for _, header := range c.msg.Headers {
+			if header.Key == f.name {
+				return header.Value, true
+			}
+		}
+		return "", false
+	case filterJSON:
+		return c.jsonPath(f.name)
+	}
+	return "", false
+}
+
+type comparisonNode struct {
+	field   filterField
+	op      string // empty for a presence test
+	value   string
+	number  float64
+	numeric bool
+	re      *regexp.Regexp
+}
+
+func (n comparisonNode) eval(c *filterContext) bool {
+	actual, ok := n.field.resolve(c)
+	if !ok {
+		return false
+	}
+
+	switch n.op {
+	case "":
+		return true
+	case "=~":
+		return n.re.MatchString(actual)
+	case "!~":
+		return !n.re.MatchString(actual)
+	}
+
+	cmp := strings.Compare(actual, n.value)
+	if n.numeric {
+		if number, err := strconv.ParseFloat(actual, 64); err == nil {
+			switch {
+			case number < n.number:
+				cmp = -1
+			case number > n.number:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// Filter tokens
+const (
+	tokEOF = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+var filterOperators = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "<", ">", "!", "="}
+
+func lexFilter(text string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokLParen, text: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokRParen, text: ")", pos: i})
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			var value strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				// Only quotes and backslashes are escaped so that regular
+				// expressions like "\d+" can be written as-is
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == r || runes[i+1] == '\\') {
+					i++
+				}
+				value.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			i++
+			tokens = append(tokens, filterToken{kind: tokString, text: value.String(), pos: start})
+
+		case strings.ContainsRune("=!<>&|", r):
+			op := ""
+			for _, candidate := range filterOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, i+1)
+			}
+			tokens = append(tokens, filterToken{kind: tokOp, text: op, pos: i})
+			i += len(op)
+			if op == "=" {
+				tokens[len(tokens)-1].text = "=="
+			}
+
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()\"'=!<>&|", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: tokWord, text: string(runes[start:i]), pos: start})
+		}
+	}
+
+	return append(tokens, filterToken{kind: tokEOF, pos: len(runes)}), nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword reports whether the next token is the operator op or the word
+// keyword and consumes it
+func (p *filterParser) keyword(op, keyword string) bool {
+	tok := p.peek()
+	if (tok.kind == tokOp && tok.text == op) || (tok.kind == tokWord && strings.EqualFold(tok.text, keyword)) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("&&", "and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.keyword("!", "not") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+
+	if p.peek().kind == tokLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d", tok.pos+1)
+		}
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	tok := p.next()
+	if tok.kind != tokWord {
+		if tok.kind == tokEOF {
+			return nil, fmt.Errorf("unexpected end of filter, expected a field")
+		}
+		return nil, fmt.Errorf("expected a field at position %d, got %q", tok.pos+1, tok.text)
+	}
+	field, ok := parseFilterField(tok.text)
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d (use key, value, partition, offset, header.NAME or $.path)", tok.text, tok.pos+1)
+	}
+
+	node := comparisonNode{field: field}
+	op := p.peek()
+	if op.kind != tokOp || op.text == "!" || op.text == "&&" || op.text == "||" {
+		// Presence test
+		return node, nil
+	}
+	p.next()
+	node.op = op.text
+
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, fmt.Errorf("expected a value after %q at position %d", op.text, op.pos+1)
+	}
+	node.value = value.text
+
+	switch node.op {
+	case "=~", "!~":
+		re, err := regexp.Compile(node.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", node.value, err)
+		}
+		node.re = re
+	default:
+		number, err := strconv.ParseFloat(node.value, 64)
+		node.number, node.numeric = number, err == nil
+		if (field.kind == filterPartition || field.kind == filterOffset) && !node.numeric {
+			return nil, fmt.Errorf("%s must be compared with a number, got %q", tok.text, node.value)
+		}
+	}
+
+	return node, nil
+}
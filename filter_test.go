@@ -0,0 +1,136 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFilter_Match(t *testing.T) {
+	msg := ConsumedMessage{
+		Partition: 2,
+		Offset:    150,
+		Key:       []byte("user-42"),
+		Value:     []byte(`{"status":"paid","total":120.5,"items":[{"sku":"a-1"}],"user":{"id":42}}`),
+		Headers:   []MessageHeader{{Key: "source", Value: "web"}, {Key: "trace-id", Value: "abc"}},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "", want: true},
+		{expr: "key == user-42", want: true},
+		{expr: `key = "user-42"`, want: true},
+		{expr: "key != user-42", want: false},
+		{expr: `key =~ "^user-\d+$"`, want: true},
+		{expr: `key !~ '^admin'`, want: true},
+		{expr: "header.source", want: true},
+		{expr: "header.missing", want: false},
+		{expr: "header.source == web", want: true},
+		{expr: "headers.trace-id == abc", want: true},
+		{expr: "header.source == app", want: false},
+		{expr: "partition == 2", want: true},
+		{expr: "offset >= 100 and offset < 200", want: true},
+		{expr: "offset > 150", want: false},
+		{expr: "$.total > 100", want: true},
+		{expr: "$.total <= 100", want: false},
+		{expr: "$.status == paid && $.user.id == 42", want: true},
+		{expr: "value.items[0].sku == a-1", want: true},
+		{expr: "$.items.0.sku =~ ^a", want: true},
+		{expr: "$.missing", want: false},
+		{expr: "$.missing != x", want: false},
+		{expr: "not $.missing", want: true},
+		{expr: "!(partition == 1 || partition == 3)", want: true},
+		{expr: "partition == 1 or header.source == web and key == user-42", want: true},
+		{expr: "(partition == 1 or header.source == web) and key == nobody", want: false},
+		{expr: `value =~ "paid"`, want: true},
+		{expr: "$.status > p", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if got := filter.Match(msg); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_MatchNonJSON(t *testing.T) {
+	filter, err := ParseFilter("$.id == 1 or key")
+	if err != nil {
+		t.Fatalf("ParseFilter() error = %v", err)
+	}
+
+	if filter.Match(ConsumedMessage{Value: []byte("plain text")}) {
+		t.Error("Expected no match for a value that is not JSON and a record without key")
+	}
+	if !filter.Match(ConsumedMessage{Key: []byte{}, Value: []byte("plain text")}) {
+		t.Error("Expected an empty key to be present")
+	}
+
+	var nilFilter *Filter
+	if !nilFilter.Match(ConsumedMessage{}) {
+		t.Error("Expected a nil filter to match everything")
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []string{
+		"color == red",
+		"key ==",
+		"key == 'open",
+		"(key == a",
+		"key == a)",
+		"key == a and",
+		"partition == one",
+		"offset > x",
+		"key =~ '('",
+		"key & value",
+		"== a",
+	}
+
+	for _, expr := range tests {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestWalkJSONPath(t *testing.T) {
+	data := []byte(`{"user":{"id":42,"name":"ann"},"items":[{"sku":"a-1"},{"sku":"b-2"}],"ok":true}`)
+
+	tests := []struct {
+		path  string
+		want  string
+		found bool
+	}{
+		{path: "user.id", want: "42", found: true},
+		{path: "user.name", want: "ann", found: true},
+		{path: "items[1].sku", want: "b-2", found: true},
+		{path: "items.0.sku", want: "a-1", found: true},
+		{path: "ok", want: "true", found: true},
+		{path: "user", want: `{"id":42,"name":"ann"}`, found: true},
+		{path: "user.email", found: false},
+		{path: "items[5].sku", found: false},
+		{path: "user.id.value", found: false},
+	}
+
+	doc, ok := decodeJSON(data)
+	if !ok {
+		t.Fatal("Expected valid JSON")
+	}
+	for _, tt := range tests {
+		got, found := walkJSONPath(doc, tt.path)
+		if found != tt.found || got != tt.want {
+			t.Errorf("walkJSONPath(%q) = %q, %v; want %q, %v", tt.path, got, found, tt.want, tt.found)
+		}
+	}
+
+	if _, ok := decodeJSON([]byte("not json")); ok {
+		t.Error("Expected invalid JSON to fail decoding")
+	}
+}
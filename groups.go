@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// GroupMember describes a member of a consumer group
+type GroupMember struct {
+	ID         string
+	ClientID   string
+	Host       string
+	Partitions []int32 // partitions of the inspected topic assigned to the member
+}
+
+// GroupSummary describes a consumer group
+type GroupSummary struct {
+	ID      string
+	State   string
+	Members []GroupMember
+}
+
+// PartitionLag compares the committed offset of a group with the high
+// watermark of a partition. Committed is -1 when the group has no commit for
+// the partition; Lag is -1 in that case.
+type PartitionLag struct {
+	Partition     int32
+	Committed     int64
+	HighWatermark int64
+	Lag           int64
+}
+
+// TotalLag sums the lag over partitions that have a committed offset
+func TotalLag(lags []PartitionLag) int64 {
+	var total int64
+	for _, lag := range lags {
+		if lag.Lag > 0 {
+			total += lag.Lag
+		}
+	}
+	return total
+}
+
+// ListGroups returns all consumer groups sorted by ID. Member partitions are
+// filtered to the given topic.
+func (a *KafkaAdmin) ListGroups(topic string) ([]GroupSummary, error) {
+	listed, err := a.admin.ListConsumerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	if len(listed) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(listed))
+	for id := range listed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	descriptions, err := a.admin.DescribeConsumerGroups(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe consumer groups: %w", err)
+	}
+
+	groups := make([]GroupSummary, 0, len(descriptions))
+	for _, description := range descriptions {
+		group := GroupSummary{ID: description.GroupId, State: description.State}
+		for memberID, member := range description.Members {
+			info := GroupMember{ID: memberID, ClientID: member.ClientId, Host: member.ClientHost}
+			if assignment, err := member.GetMemberAssignment(); err == nil && assignment != nil {
+				info.Partitions = assignment.Topics[topic]
+			}
+			group.Members = append(group.Members, info)
+		}
+		sort.Slice(group.Members, func(i, j int) bool { return group.Members[i].ID < group.Members[j].ID })
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+
+	return groups, nil
+}
+
+// GroupLag returns the committed offset, high watermark and lag of a group
+// for every partition of topic
+func (a *KafkaAdmin) GroupLag(group, topic string) ([]PartitionLag, error) {
+	partitions, err := a.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+
+	offsets, err := a.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets of group %q: %w", group, err)
+	}
+
+	lags := make([]PartitionLag, 0, len(partitions))
+	for _, partition := range partitions {
+		highWatermark, err := a.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get high watermark of %s/%d: %w", topic, partition, err)
+		}
+
+		lag := PartitionLag{Partition: partition, Committed: -1, HighWatermark: highWatermark, Lag: -1}
+		if block := offsets.GetBlock(topic, partition); block != nil && block.Err == sarama.ErrNoError && block.Offset >= 0 {
+			lag.Committed = block.Offset
+			lag.Lag = max(highWatermark-block.Offset, 0)
+		}
+		lags = append(lags, lag)
+	}
+	sort.Slice(lags, func(i, j int) bool { return lags[i].Partition < lags[j].Partition })
+
+	return lags, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// encodeAssignment encodes a consumer protocol member assignment the way a
+// consumer group leader sends it in SyncGroup
+func encodeAssignment(topic string, partitions ...int32) []byte {
+	buf := binary.BigEndian.AppendUint16(nil, 0) // version
+	buf = binary.BigEndian.AppendUint32(buf, 1)  // topic count
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(topic)))
+	buf = append(buf, topic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(partitions)))
+	for _, p := range partitions {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(p))
+	}
+	return binary.BigEndian.AppendUint32(buf, 0xFFFFFFFF) // null user data
+}
+
+// testResetTimestamp is a timestamp the groups mock cluster resolves to
+// offset 45 on partition 0 and to no offset on partition 1
+const testResetTimestamp = 1700000000000
+
+// newGroupsCluster starts a mock broker with a two-partition "orders" topic,
+// an active group "billing" and an empty, fully caught up group "audit"
+func newGroupsCluster(t *testing.T) *sarama.MockBroker {
+	t.Helper()
+
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+
+	handlers := mockClusterHandlers(t, broker, "orders")
+	handlers["MetadataRequest"].(*sarama.MockMetadataResponse).SetLeader("orders", 1, broker.BrokerID())
+	handlers["ListGroupsRequest"] = sarama.NewMockListGroupsResponse(t).
+		AddGroup("billing", "consumer").
+		AddGroup("audit", "consumer")
+	handlers["DescribeGroupsRequest"] = sarama.NewMockDescribeGroupsResponse(t).
+		AddGroupDescription("billing", &sarama.GroupDescription{
+			GroupId: "billing",
+			State:   "Stable",
+			Members: map[string]*sarama.GroupMemberDescription{
+				"member-1": {ClientId: "billing-service", ClientHost: "/10.0.0.1", MemberAssignment: encodeAssignment("orders", 0, 1)},
+			},
+		}).
+		AddGroupDescription("audit", &sarama.GroupDescription{GroupId: "audit", State: "Empty"})
+	handlers["FindCoordinatorRequest"] = sarama.NewMockFindCoordinatorResponse(t).
+		SetCoordinator(sarama.CoordinatorGroup, "billing", broker).
+		SetCoordinator(sarama.CoordinatorGroup, "audit", broker)
+	handlers["OffsetFetchRequest"] = sarama.NewMockOffsetFetchResponse(t).
+		SetOffset("billing", "orders", 0, 40, "", sarama.ErrNoError).
+		SetOffset("billing", "orders", 1, -1, "", sarama.ErrNoError).
+		SetOffset("audit", "orders", 0, 50, "", sarama.ErrNoError).
+		SetOffset("audit", "orders", 1, 7, "", sarama.ErrNoError)
+	handlers["OffsetRequest"] = sarama.NewMockOffsetResponse(t).
+		SetOffset("orders", 0, sarama.OffsetOldest, 10).
+		SetOffset("orders", 0, sarama.OffsetNewest, 50).
+		SetOffset("orders", 0, testResetTimestamp, 45).
+		SetOffset("orders", 1, sarama.OffsetOldest, 0).
+		SetOffset("orders", 1, sarama.OffsetNewest, 7).
+		SetOffset("orders", 1, testResetTimestamp, -1)
+	handlers["OffsetCommitRequest"] = sarama.NewMockOffsetCommitResponse(t)
+	broker.SetHandlerByMap(handlers)
+
+	return broker
+}
+
+func TestKafkaAdmin_ListGroups(t *testing.T) {
+	admin := newTestAdmin(t, newGroupsCluster(t))
+
+	groups, err := admin.ListGroups("orders")
+	if err != nil {
+		t.Fatalf("ListGroups() error = %v", err)
+	}
+
+	if len(groups) != 2 || groups[0].ID != "audit" || groups[1].ID != "billing" {
+		t.Fatalf("Expected groups audit and billing sorted by ID, got %+v", groups)
+	}
+
+	billing := groups[1]
+	if billing.State != "Stable" {
+		t.Errorf("Expected state Stable, got %s", billing.State)
+	}
+	if len(billing.Members) != 1 {
+		t.Fatalf("Expected 1 member, got %d", len(billing.Members))
+	}
+	member := billing.Members[0]
+	if member.ClientID != "billing-service" || len(member.Partitions) != 2 {
+		t.Errorf("Expected billing-service assigned to 2 partitions, got %+v", member)
+	}
+
+	if len(groups[0].Members) != 0 {
+		t.Errorf("Expected no members in audit, got %d", len(groups[0].Members))
+	}
+}
+
+func TestKafkaAdmin_GroupLag(t *testing.T) {
+	admin := newTestAdmin(t, newGroupsCluster(t))
+
+	lags, err := admin.GroupLag("billing", "orders")
+	if err != nil {
+		t.Fatalf("GroupLag() error = %v", err)
+	}
+
+	if len(lags) != 2 {
+		t.Fatalf("Expected 2 partitions, got %d", len(lags))
+	}
+	if lags[0].Committed != 40 || lags[0].HighWatermark != 50 || lags[0].Lag != 10 {
+		t.Errorf("Expected partition 0 committed 40, high watermark 50, lag 10, got %+v", lags[0])
+	}
+	if lags[1].Committed != -1 || lags[1].HighWatermark != 7 || lags[1].Lag != -1 {
+		t.Errorf("Expected partition 1 without commit, got %+v", lags[1])
+	}
+	if total := TotalLag(lags); total != 10 {
+		t.Errorf("Expected total lag 10, got %d", total)
+	}
+}
+
+func TestKafkaAdmin_GroupLag_UnknownTopic(t *testing.T) {
+	admin := newTestAdmin(t, newGroupsCluster(t))
+
+	if _, err := admin.GroupLag("billing", "missing"); err == nil {
+		t.Error("Expected error for unknown topic")
+	}
+}
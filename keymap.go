@@ -0,0 +1,256 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyMap holds the key bindings of the global actions and of the sub-screens.
+// The defaults can be overridden per action in the keymap section of the config.
+type keyMap struct {
+	Switch    key.Binding
+	Cluster   key.Binding
+	Topics    key.Binding
+	Connect   key.Binding
+	Diagnose  key.Binding
+	Groups    key.Binding
+	Consume   key.Binding
+	Scenario  key.Binding
+	Save      key.Binding
+	Format    key.Binding
+	Reply     key.Binding
+	Verify    key.Binding
+	Send      key.Binding
+	Quit      key.Binding
+	NextField key.Binding
+	PrevField key.Binding
+
+	// Sub-screens: lists, forms and the scenario view
+	Up            key.Binding
+	Down          key.Binding
+	Refresh       key.Binding
+	CreateTopic   key.Binding
+	TopicConfigs  key.Binding
+	AddPartitions key.Binding
+	DeleteTopic   key.Binding
+	TruncateTopic key.Binding
+	ResetOffsets  key.Binding
+	AutoRefresh   key.Binding
+	Filter        key.Binding
+	Follow        key.Binding
+	Clear         key.Binding
+	Apply         key.Binding
+	Cancel        key.Binding
+	RunScenario   key.Binding
+	StopScenario  key.Binding
+}
+
+// keyAction is a rebindable action: its name in the keymap config section,
+// its help bar icon and its binding
+type keyAction struct {
+	name    string
+	icon    string
+	binding *key.Binding
+	hidden  bool // not shown in the help bar
+	command bool // global command that works on every screen
+	screen  bool // handled by sub-screens only, see keyScreens
+}
+
+// keyScreen is a sub-screen that handles its own keys. Its actions must not
+// share keys with each other or with the global commands.
+type keyScreen struct {
+	name    string
+	actions []string
+	inputs  bool // has text inputs that printable keys are typed into
+}
+
+// keyScreens lists the sub-screens and the actions they handle
+var keyScreens = []keyScreen{
+	{name: "topics", actions: []string{"up", "down", "refresh", "create_topic", "topic_configs", "add_partitions", "delete_topic", "truncate_topic"}},
+	{name: "groups", actions: []string{"up", "down", "refresh", "reset_offsets", "auto_refresh"}},
+	{name: "consume", actions: []string{"up", "down", "filter", "follow", "clear"}},
+	{name: "form", actions: []string{"apply", "cancel", "next_field", "prev_field"}, inputs: true},
+	{name: "scenario", actions: []string{"run_scenario", "stop_scenario"}, inputs: true},
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Switch:    key.NewBinding(key.WithKeys("f2"), key.WithHelp("F2", "Switch")),
+		Cluster:   key.NewBinding(key.WithKeys("f3"), key.WithHelp("F3", "Cluster")),
+		Topics:    key.NewBinding(key.WithKeys("f4"), key.WithHelp("F4", "Topics")),
+		Connect:   key.NewBinding(key.WithKeys("f5"), key.WithHelp("F5", "Connect")),
+		Diagnose:  key.NewBinding(key.WithKeys("f6"), key.WithHelp("F6", "Diagnose")),
+		Groups:    key.NewBinding(key.WithKeys("f7"), key.WithHelp("F7", "Groups")),
+		Consume:   key.NewBinding(key.WithKeys("f8"), key.WithHelp("F8", "Consume")),
+		Scenario:  key.NewBinding(key.WithKeys("f12"), key.WithHelp("F12", "Scenario")),
+		Save:      key.NewBinding(key.WithKeys("f9"), key.WithHelp("F9", "Save")),
+		Format:    key.NewBinding(key.WithKeys("f10"), key.WithHelp("F10", "Format")),
+		Reply:     key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("^R", "Reply")),
+		Verify:    key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("^O", "Verify")),
+		Send:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("Enter", "Send")),
+		Quit:      key.NewBinding(key.WithKeys("ctrl+c", "esc"), key.WithHelp("Esc", "Quit")),
+		NextField: key.NewBinding(key.WithKeys("tab"), key.WithHelp("Tab", "Next field")),
+		PrevField: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("Shift+Tab", "Previous field")),
+
+		Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑", "Up")),
+		Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓", "Down")),
+		Refresh:       key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "Refresh")),
+		CreateTopic:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Create")),
+		TopicConfigs:  key.NewBinding(key.WithKeys("enter", "e"), key.WithHelp("e/Enter", "Configs")),
+		AddPartitions: key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "Add partitions")),
+		DeleteTopic:   key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "Delete")),
+		TruncateTopic: key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "Truncate")),
+		ResetOffsets:  key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "Reset offsets")),
+		AutoRefresh:   key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "Toggle auto-refresh")),
+		Filter:        key.NewBinding(key.WithKeys("/", "f"), key.WithHelp("/", "Filter")),
+		Follow:        key.NewBinding(key.WithKeys("end", "G"), key.WithHelp("End", "Follow")),
+		Clear:         key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "Clear")),
+		Apply:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("Enter", "Apply")),
+		Cancel:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("Esc", "Cancel")),
+		RunScenario:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("Enter", "Run")),
+		StopScenario:  key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("^X", "Stop")),
+	}
+}
+
+// actions lists the actions in help bar order, followed by the sub-screen ones
+func (k *keyMap) actions() []keyAction {
+	return []keyAction{
+		{name: "switch", icon: "󰌌", binding: &k.Switch, command: true},
+		{name: "cluster", icon: "󰒍", binding: &k.Cluster, command: true},
+		{name: "topics", icon: "󰓩", binding: &k.Topics, command: true},
+		{name: "connect", icon: "󰛐", binding: &k.Connect, command: true},
+		{name: "diagnose", icon: "󰓅", binding: &k.Diagnose, command: true},
+		{name: "groups", icon: "󰡨", binding: &k.Groups, command: true},
+		{name: "consume", icon: "󰍉", binding: &k.Consume, command: true},
+		{name: "scenario", icon: "󰐊", binding: &k.Scenario, command: true},
+		{name: "save", icon: "󰆓", binding: &k.Save, command: true},
+		{name: "format", icon: "󰉢", binding: &k.Format, command: true},
+		{name: "reply", icon: "⇄", binding: &k.Reply},
+		{name: "verify", icon: "✓", binding: &k.Verify},
+		{name: "send", binding: &k.Send},
+		{name: "quit", icon: "󰩈", binding: &k.Quit},
+		{name: "next_field", binding: &k.NextField, hidden: true},
+		{name: "prev_field", binding: &k.PrevField, hidden: true},
+
+		{name: "up", binding: &k.Up, screen: true},
+		{name: "down", binding: &k.Down, screen: true},
+		{name: "refresh", binding: &k.Refresh, screen: true},
+		{name: "create_topic", binding: &k.CreateTopic, screen: true},
+		{name: "topic_configs", binding: &k.TopicConfigs, screen: true},
+		{name: "add_partitions", binding: &k.AddPartitions, screen: true},
+		{name: "delete_topic", binding: &k.DeleteTopic, screen: true},
+		{name: "truncate_topic", binding: &k.TruncateTopic, screen: true},
+		{name: "reset_offsets", binding: &k.ResetOffsets, screen: true},
+		{name: "auto_refresh", binding: &k.AutoRefresh, screen: true},
+		{name: "filter", binding: &k.Filter, screen: true},
+		{name: "follow", binding: &k.Follow, screen: true},
+		{name: "clear", binding: &k.Clear, screen: true},
+		{name: "apply", binding: &k.Apply, screen: true},
+		{name: "cancel", binding: &k.Cancel, screen: true},
+		{name: "run_scenario", binding: &k.RunScenario, screen: true},
+		{name: "stop_scenario", binding: &k.StopScenario, screen: true},
+	}
+}
+
+// keyActionNames returns the names accepted in the keymap config section
+func keyActionNames() []string {
+	var k keyMap
+	var names []string
+	for _, action := range k.actions() {
+		names = append(names, action.name)
+	}
+	return names
+}
+
+// newKeyMap returns the default bindings with overrides applied. An empty
+// key list unbinds the action; unknown actions are left to ValidateConfig.
+func newKeyMap(overrides map[string][]string) keyMap {
+	k := defaultKeyMap()
+	for _, action := range k.actions() {
+		keys, ok := overrides[action.name]
+		if !ok {
+			continue
+		}
+		if len(keys) == 0 {
+			action.binding.SetEnabled(false)
+			continue
+		}
+		action.binding.SetKeys(keys...)
+		action.binding.SetHelp(keyHelp(keys), action.binding.Help().Desc)
+	}
+	return k
+}
+
+// keyHelp formats key names for display: "ctrl+r" becomes "^R" and
+// "shift+f5" becomes "Shift+F5"
+func keyHelp(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, name := range keys {
+		if rest, ok := strings.CutPrefix(name, "ctrl+"); ok && utf8.RuneCountInString(rest) == 1 {
+			labels[i] = "^" + strings.ToUpper(rest)
+			continue
+		}
+		parts := strings.Split(name, "+")
+		for j, part := range parts {
+			if part != "" {
+				parts[j] = strings.ToUpper(part[:1]) + part[1:]
+			}
+		}
+		labels[i] = strings.Join(parts, "+")
+	}
+	return strings.Join(labels, "/")
+}
+
+// isCommand reports whether msg triggers one of the global commands. Screens
+// with their own inputs let these keys fall through to the global handlers.
+func (k *keyMap) isCommand(msg tea.KeyMsg) bool {
+	for _, action := range k.actions() {
+		if action.command && key.Matches(msg, *action.binding) {
+			return true
+		}
+	}
+	return false
+}
+
+// leavesForm reports whether a form lets msg fall through to the global
+// handlers: the commands, and the quit keys its Cancel binding does not take
+func (k *keyMap) leavesForm(msg tea.KeyMsg) bool {
+	return k.isCommand(msg) || key.Matches(msg, k.Quit)
+}
+
+// helpView renders the help bar from the active bindings
+func (k *keyMap) helpView() string {
+	var items []string
+	for _, action := range k.actions() {
+		if action.hidden || action.screen || !action.binding.Enabled() {
+			continue
+		}
+		help := action.binding.Help()
+		items = append(items, fmt.Sprintf("%s %s: %s", action.icon, help.Key, help.Desc))
+	}
+	return strings.Join(items, " │ ")
+}
+
+// back returns the Switch binding described as the way back from a sub-screen
+func (k *keyMap) back() key.Binding {
+	back := k.Switch
+	back.SetHelp(back.Help().Key, "Back")
+	return back
+}
+
+// hintView renders the key hints of a sub-screen from the active bindings
+func hintView(bindings ...key.Binding) string {
+	var items []string
+	for _, binding := range bindings {
+		if !binding.Enabled() {
+			continue
+		}
+		help := binding.Help()
+		items = append(items, help.Key+": "+help.Desc)
+	}
+	return strings.Join(items, " │ ")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyHelp(t *testing.T) {
+	tests := map[string]string{
+		"ctrl+r":    "^R",
+		"f5":        "F5",
+		"enter":     "Enter",
+		"shift+tab": "Shift+Tab",
+		"alt+s":     "Alt+S",
+		"ctrl+up":   "Ctrl+Up",
+	}
+	for name, want := range tests {
+		if got := keyHelp([]string{name}); got != want {
+			t.Errorf("keyHelp(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if got := keyHelp([]string{"ctrl+q", "f10"}); got != "^Q/F10" {
+		t.Errorf("Expected keys joined with /, got %q", got)
+	}
+}
+
+func TestKeyMap_DefaultHelp(t *testing.T) {
+	keys := newKeyMap(nil)
+	want := "󰌌 F2: Switch │ 󰒍 F3: Cluster │ 󰓩 F4: Topics │ 󰛐 F5: Connect │ 󰓅 F6: Diagnose │ 󰡨 F7: Groups │ 󰍉 F8: Consume │ 󰐊 F12: Scenario │ 󰆓 F9: Save │ 󰉢 F10: Format │ ⇄ ^R: Reply │ ✓ ^O: Verify │  Enter: Send │ 󰩈 Esc: Quit"
+	if got := keys.helpView(); got != want {
+		t.Errorf("helpView() = %q, want %q", got, want)
+	}
+}
+
+func TestKeyMap_Overrides(t *testing.T) {
+	keys := newKeyMap(map[string][]string{
+		"connect": {"ctrl+k"},
+		"quit":    {"ctrl+q", "ctrl+c"},
+		"format":  {},
+	})
+
+	help := keys.helpView()
+	for _, want := range []string{"󰛐 ^K: Connect", "󰩈 ^Q/^C: Quit", "󰌌 F2: Switch"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("Expected help bar to contain %q, got %q", want, help)
+		}
+	}
+	if strings.Contains(help, "Format") {
+		t.Errorf("Expected unbound actions to be hidden, got %q", help)
+	}
+
+	if keys.isCommand(tea.KeyMsg{Type: tea.KeyF5}) {
+		t.Error("Expected F5 to no longer be a command")
+	}
+	if !keys.isCommand(tea.KeyMsg{Type: tea.KeyCtrlK}) {
+		t.Error("Expected ^K to be a command")
+	}
+	if keys.isCommand(tea.KeyMsg{Type: tea.KeyF10}) {
+		t.Error("Expected the unbound F10 not to be a command")
+	}
+}
+
+func TestUpdate_ReboundKeys(t *testing.T) {
+	config := &Config{
+		Brokers: []string{"localhost:9092"},
+		Topic:   testTopic,
+		Keymap:  map[string][]string{"switch": {"ctrl+n"}, "connect": {"ctrl+k"}, "topics": {"alt+t"}},
+	}
+	m := initialModel(config)
+
+	// The old binding is typed into the focused input instead
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyF4})
+	if m = newModel.(model); m.statusMessage != "" {
+		t.Errorf("Expected F4 to be ignored, got status %q", m.statusMessage)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}, Alt: true})
+	if m = newModel.(model); m.statusMessage != "Please connect to Kafka first (^K)" {
+		t.Errorf("Expected the status to name the connect key, got %q", m.statusMessage)
+	}
+
+	m.connected = true
+	m.width, m.height = 200, 50
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m = newModel.(model); m.currentView != messageView {
+		t.Errorf("Expected ^N to switch to the message view, got view %d", m.currentView)
+	}
+	if !strings.Contains(m.View(), "^N: Switch") {
+		t.Error("Expected the help bar to show the new binding")
+	}
+}
+
+func TestUpdateConsumer_ReboundKeys(t *testing.T) {
+	m := consumerModel(t)
+	m.keys = newKeyMap(map[string][]string{"clear": {"x"}, "filter": {"ctrl+f"}})
+	m.consumer.records = []ConsumedMessage{testRecord(0, "a")}
+
+	// The old bindings do nothing
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if m = newModel.(model); len(m.consumer.records) != 1 {
+		t.Fatal("Expected c to no longer clear the records")
+	}
+	if !strings.Contains(m.View(), "X: Clear") || !strings.Contains(m.View(), "^F: Filter") {
+		t.Errorf("Expected the hints to show the new bindings, got %q", m.View())
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if m = newModel.(model); len(m.consumer.records) != 0 {
+		t.Error("Expected x to clear the records")
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if m = newModel.(model); !m.consumer.filterInput.Focused() {
+		t.Error("Expected ^F to focus the filter")
+	}
+}
+
+func TestUpdate_QuitFromForm(t *testing.T) {
+	m := topicAdminModel(t, "orders")
+	m, _ = pressKeys(t, m, runes("c"))
+	if m.topicAdmin.mode != topicCreateMode {
+		t.Fatal("Expected the create form to open")
+	}
+
+	_, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	if cmd == nil {
+		t.Fatal("Expected ^C to quit from the form")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("Expected a quit command")
+	}
+
+	// Esc stays bound to Cancel
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.topicAdmin.mode != topicListMode {
+		t.Error("Expected Esc to close the form")
+	}
+}
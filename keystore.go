@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1" //nolint:gosec // required by the JKS format
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf16"
+
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Certificate file formats, recognised by content
+const (
+	formatPEM    = "PEM"
+	formatPKCS12 = "PKCS#12"
+	formatJKS    = "JKS"
+)
+
+// jksMagic starts every Java KeyStore file
+const jksMagic = 0xFEEDFEED
+
+// errPasswordRequired is returned for encrypted keys when no password is set
+var errPasswordRequired = errors.New("password required")
+
+// certificateFormat tells PEM files from PKCS#12 and JKS keystores. Files
+// that are neither are treated as PEM, which reports them as invalid.
+func certificateFormat(data []byte) string {
+	switch {
+	case len(data) >= 4 && binary.BigEndian.Uint32(data) == jksMagic:
+		return formatJKS
+	case len(data) > 0 && data[0] == 0x30: // DER SEQUENCE
+		return formatPKCS12
+	default:
+		return formatPEM
+	}
+}
+
+// isKeystore reports whether path is a PKCS#12 or JKS keystore, which holds
+// the client key next to the certificate
+func isKeystore(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && certificateFormat(data) != formatPEM
+}
+
+// loadClientCertificate loads the client certificate and key from a PEM
+// certificate and key file, or from a PKCS#12 or JKS keystore in certFile.
+// password decrypts encrypted PEM keys and keystores.
+func loadClientCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	switch certificateFormat(data) {
+	case formatPKCS12:
+		key, cert, chain, err := pkcs12.DecodeChain(data, password)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read PKCS#12 keystore %s: %w", certFile, err)
+		}
+		return newTLSCertificate(key, append([]*x509.Certificate{cert}, chain...)), nil
+	case formatJKS:
+		if password == "" {
+			return tls.Certificate{}, fmt.Errorf("JKS keystore %s: %w", certFile, errPasswordRequired)
+		}
+		keystore, err := readJKS(data, password)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read JKS keystore %s: %w", certFile, err)
+		}
+		if len(keystore.keys) == 0 {
+			return tls.Certificate{}, fmt.Errorf("JKS keystore %s has no private key entry", certFile)
+		}
+		entry := keystore.keys[0]
+		return newTLSCertificate(entry.key, entry.chain), nil
+	}
+
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if keyPEM, err = decryptKeyPEM(keyPEM, password); err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to decrypt %s: %w", keyFile, err)
+	}
+	return tls.X509KeyPair(data, keyPEM)
+}
+
+func newTLSCertificate(key crypto.PrivateKey, chain []*x509.Certificate) tls.Certificate {
+	cert := tls.Certificate{PrivateKey: key, Leaf: chain[0]}
+	for _, c := range chain {
+		cert.Certificate = append(cert.Certificate, c.Raw)
+	}
+	return cert
+}
+
+// decryptKeyPEM returns keyPEM with encrypted keys (PKCS#8 "ENCRYPTED
+// PRIVATE KEY" or legacy OpenSSL "Proc-Type: 4,ENCRYPTED") replaced by
+// their unencrypted PKCS#8 form. Unencrypted keys are returned unchanged.
+func decryptKeyPEM(keyPEM []byte, password string) ([]byte, error) {
+	for rest := keyPEM; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			return keyPEM, nil
+		}
+
+		var key any
+		var err error
+		switch {
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			if password == "" {
+				return nil, errPasswordRequired
+			}
+			key, err = pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(password))
+		case x509.IsEncryptedPEMBlock(block): //nolint:staticcheck // legacy keys are still issued
+			if password == "" {
+				return nil, errPasswordRequired
+			}
+			var der []byte
+			if der, err = x509.DecryptPEMBlock(block, []byte(password)); err == nil { //nolint:staticcheck // see above
+				key, err = parsePrivateKey(block.Type, der)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wrong password or unsupported key: %w", err)
+		}
+
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	}
+}
+
+func parsePrivateKey(blockType string, der []byte) (any, error) {
+	switch blockType {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(der)
+	}
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+// loadCACertificates reads the trusted certificates of a PEM file or a
+// PKCS#12 or JKS truststore. JKS truststores can be read without password,
+// their integrity is then not checked.
+func loadCACertificates(caFile, password string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	var certs []*x509.Certificate
+	switch certificateFormat(data) {
+	case formatPEM:
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificate found in %s", caFile)
+		}
+		return pool, nil
+	case formatPKCS12:
+		if certs, err = pkcs12.DecodeTrustStore(data, password); err != nil {
+			return nil, fmt.Errorf("failed to read PKCS#12 truststore %s: %w", caFile, err)
+		}
+	case formatJKS:
+		keystore, err := readJKS(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JKS truststore %s: %w", caFile, err)
+		}
+		certs = keystore.trusted
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no trusted certificate found in %s", caFile)
+	}
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+// jksKeystore is the content of a Java KeyStore file
+type jksKeystore struct {
+	keys    []jksKeyEntry
+	trusted []*x509.Certificate
+}
+
+type jksKeyEntry struct {
+	alias string
+	key   crypto.PrivateKey
+	chain []*x509.Certificate
+}
+
+// jksKeyProtector identifies the proprietary Sun key protection algorithm
+var jksKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+type jksEncryptedKey struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.RawValue `asn1:"optional"`
+	}
+	Data []byte
+}
+
+// readJKS parses a JKS file. Private keys are decrypted with password, which
+// also checks the integrity of the file. Without password only the trusted
+// certificates are read.
+func readJKS(data []byte, password string) (*jksKeystore, error) {
+	if len(data) < 12+sha1.Size {
+		return nil, fmt.Errorf("file is too short")
+	}
+	body, digest := data[:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	if password != "" && !bytes.Equal(jksDigest(body, password), digest) {
+		return nil, fmt.Errorf("wrong password or corrupted keystore")
+	}
+
+	r := &jksReader{r: bytes.NewReader(body)}
+	if magic := r.uint32(); magic != jksMagic {
+		return nil, fmt.Errorf("not a JKS keystore")
+	}
+	version := r.uint32()
+	if r.err == nil && version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported JKS version %d", version)
+	}
+
+	keystore := &jksKeystore{}
+	for count := r.uint32(); count > 0 && r.err == nil; count-- {
+		tag := r.uint32()
+		alias := r.utf()
+		r.read(8) // creation date
+
+		switch tag {
+		case 1:
+			encrypted := r.bytes()
+			chain := make([]*x509.Certificate, 0, 1)
+			for n := r.uint32(); n > 0 && r.err == nil; n-- {
+				if cert := r.certificate(version); cert != nil {
+					chain = append(chain, cert)
+				}
+			}
+			if r.err != nil {
+				break
+			}
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("key %q has no certificate", alias)
+			}
+			if password == "" {
+				continue // read as a truststore
+			}
+			key, err := decryptJKSKey(encrypted, password)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", alias, err)
+			}
+			keystore.keys = append(keystore.keys, jksKeyEntry{alias: alias, key: key, chain: chain})
+		case 2:
+			if cert := r.certificate(version); cert != nil {
+				keystore.trusted = append(keystore.trusted, cert)
+			}
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("unsupported entry type %d", tag)
+			}
+		}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("invalid keystore: %w", r.err)
+	}
+	return keystore, nil
+}
+
+// jksPassword encodes a password like Java does for keystore hashing
+func jksPassword(password string) []byte {
+	var encoded []byte
+	for _, c := range utf16.Encode([]rune(password)) {
+		encoded = append(encoded, byte(c>>8), byte(c))
+	}
+	return encoded
+}
+
+func jksDigest(body []byte, password string) []byte {
+	h := sha1.New() //nolint:gosec // required by the JKS format
+	h.Write(jksPassword(password))
+	h.Write([]byte("Mighty Aphrodite"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// decryptJKSKey reverses the Sun key protector: the key is XORed with a
+// SHA-1 keystream seeded with a salt and followed by a check digest
+func decryptJKSKey(encrypted []byte, password string) (crypto.PrivateKey, error) {
+	var info jksEncryptedKey
+	if _, err := asn1.Unmarshal(encrypted, &info); err != nil {
+		return nil, fmt.Errorf("invalid encrypted key: %w", err)
+	}
+	if !info.Algorithm.Algorithm.Equal(jksKeyProtector) {
+		return nil, fmt.Errorf("unsupported key protection %s", info.Algorithm.Algorithm)
+	}
+	if len(info.Data) < 2*sha1.Size {
+		return nil, fmt.Errorf("encrypted key is too short")
+	}
+
+	pw := jksPassword(password)
+	salt := info.Data[:sha1.Size]
+	ciphertext := info.Data[sha1.Size : len(info.Data)-sha1.Size]
+	check := info.Data[len(info.Data)-sha1.Size:]
+
+	plain := make([]byte, len(ciphertext))
+	stream := salt
+	for i := range ciphertext {
+		if i%sha1.Size == 0 {
+			sum := sha1.Sum(append(append([]byte{}, pw...), stream...)) //nolint:gosec // see import
+			stream = sum[:]
+		}
+		plain[i] = ciphertext[i] ^ stream[i%sha1.Size]
+	}
+
+	if sum := sha1.Sum(append(append([]byte{}, pw...), plain...)); !bytes.Equal(sum[:], check) { //nolint:gosec // see import
+		return nil, fmt.Errorf("wrong key password")
+	}
+	return x509.ParsePKCS8PrivateKey(plain)
+}
+
+// jksReader reads the big-endian structures of a JKS file and remembers
+// the first error
+type jksReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *jksReader) read(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		r.err = err
+		return nil
+	}
+	return buf
+}
+
+func (r *jksReader) uint32() uint32 {
+	if buf := r.read(4); buf != nil {
+		return binary.BigEndian.Uint32(buf)
+	}
+	return 0
+}
+
+func (r *jksReader) bytes() []byte {
+	n := r.uint32()
+	if n > 1<<24 {
+		r.err = fmt.Errorf("entry of %d bytes is too large", n)
+		return nil
+	}
+	return r.read(int(n))
+}
+
+func (r *jksReader) utf() string {
+	buf := r.read(2)
+	if buf == nil {
+		return ""
+	}
+	return string(r.read(int(binary.BigEndian.Uint16(buf))))
+}
+
+func (r *jksReader) certificate(version uint32) *x509.Certificate {
+	if version == 2 {
+		if certType := r.utf(); r.err == nil && certType != "X.509" {
+			r.err = fmt.Errorf("unsupported certificate type %q", certType)
+		}
+	}
+	der := r.bytes()
+	if r.err != nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	return cert
+}
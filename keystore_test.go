@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by the JKS format
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const testPassword = "s3cret"
+
+// newTestIdentity creates a self-signed certificate and its key
+func newTestIdentity(t *testing.T) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// encodeJKS writes a JKS keystore with an optional key entry and trusted
+// certificates, protecting the key like the Sun key protector
+func encodeJKS(t *testing.T, password string, key *ecdsa.PrivateKey, chain []*x509.Certificate, trusted []*x509.Certificate) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	write := func(v any) { _ = binary.Write(&buf, binary.BigEndian, v) }
+	writeUTF := func(s string) { write(uint16(len(s))); buf.WriteString(s) }
+	writeCert := func(cert *x509.Certificate) { writeUTF("X.509"); write(uint32(len(cert.Raw))); buf.Write(cert.Raw) }
+
+	count := len(trusted)
+	if key != nil {
+		count++
+	}
+	write(uint32(jksMagic))
+	write(uint32(2))
+	write(uint32(count))
+
+	if key != nil {
+		plain, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pw := jksPassword(password)
+		salt := bytes.Repeat([]byte{7}, sha1.Size)
+		data := append([]byte{}, salt...)
+		stream := salt
+		for i := range plain {
+			if i%sha1.Size == 0 {
+				sum := sha1.Sum(append(append([]byte{}, pw...), stream...))
+				stream = sum[:]
+			}
+			data = append(data, plain[i]^stream[i%sha1.Size])
+		}
+		check := sha1.Sum(append(append([]byte{}, pw...), plain...))
+		data = append(data, check[:]...)
+
+		var info jksEncryptedKey
+		info.Algorithm.Algorithm = jksKeyProtector
+		info.Algorithm.Parameters = asn1.NullRawValue
+		info.Data = data
+		encrypted, err := asn1.Marshal(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		write(uint32(1))
+		writeUTF("client")
+		write(time.Now().UnixMilli())
+		write(uint32(len(encrypted)))
+		buf.Write(encrypted)
+		write(uint32(len(chain)))
+		for _, cert := range chain {
+			writeCert(cert)
+		}
+	}
+	for i, cert := range trusted {
+		write(uint32(2))
+		writeUTF("ca" + string(rune('0'+i)))
+		write(time.Now().UnixMilli())
+		writeCert(cert)
+	}
+
+	buf.Write(jksDigest(buf.Bytes(), password))
+	return buf.Bytes()
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	key, cert := newTestIdentity(t)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+
+	plainDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedDER, err := pkcs8.MarshalPrivateKey(key, []byte(testPassword), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", ecDER, []byte(testPassword), x509.PEMCipherAES256) //nolint:staticcheck // legacy format under test
+	if err != nil {
+		t.Fatal(err)
+	}
+	p12, err := pkcs12.Modern.Encode(key, cert, nil, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jks := encodeJKS(t, testPassword, key, []*x509.Certificate{cert}, nil)
+
+	tests := []struct {
+		name     string
+		certFile []byte
+		keyFile  []byte
+		password string
+		err      string
+	}{
+		{name: "PEM", certFile: certPEM, keyFile: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: plainDER})},
+		{name: "encrypted PKCS#8", certFile: certPEM, keyFile: pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encryptedDER}), password: testPassword},
+		{name: "encrypted PKCS#8 without password", certFile: certPEM, keyFile: pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encryptedDER}), err: "password required"},
+		{name: "encrypted PKCS#8 wrong password", certFile: certPEM, keyFile: pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encryptedDER}), password: "wrong", err: "wrong password"},
+		{name: "legacy encrypted PEM", certFile: certPEM, keyFile: pem.EncodeToMemory(legacy), password: testPassword},
+		{name: "PKCS#12", certFile: p12, password: testPassword},
+		{name: "PKCS#12 wrong password", certFile: p12, password: "wrong", err: "failed to read PKCS#12 keystore"},
+		{name: "JKS", certFile: jks, password: testPassword},
+		{name: "JKS without password", certFile: jks, err: "password required"},
+		{name: "JKS wrong password", certFile: jks, password: "wrong", err: "wrong password"},
+		{name: "JKS without key", certFile: encodeJKS(t, testPassword, nil, nil, []*x509.Certificate{cert}), password: testPassword, err: "no private key entry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certFile := writeTestFile(t, "client.crt", tt.certFile)
+			keyFile := ""
+			if tt.keyFile != nil {
+				keyFile = writeTestFile(t, "client.key", tt.keyFile)
+			}
+
+			got, err := loadClientCertificate(certFile, keyFile, tt.password)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadClientCertificate() error = %v", err)
+			}
+			if len(got.Certificate) != 1 || !bytes.Equal(got.Certificate[0], cert.Raw) {
+				t.Error("Expected the client certificate")
+			}
+			if signer, ok := got.PrivateKey.(*ecdsa.PrivateKey); !ok || !signer.Equal(key) {
+				t.Errorf("Expected the client key, got %T", got.PrivateKey)
+			}
+		})
+	}
+
+	if _, err := loadClientCertificate(writeTestFile(t, "client.crt", certPEM), writeTestFile(t, "client.key", pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encryptedDER})), ""); !errors.Is(err, errPasswordRequired) {
+		t.Errorf("Expected errPasswordRequired, got %v", err)
+	}
+}
+
+func TestLoadCACertificates(t *testing.T) {
+	_, ca := newTestIdentity(t)
+	truststore, err := pkcs12.Modern.EncodeTrustStore([]*x509.Certificate{ca}, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jks := encodeJKS(t, testPassword, nil, nil, []*x509.Certificate{ca})
+
+	tests := []struct {
+		name     string
+		data     []byte
+		password string
+		err      string
+	}{
+		{name: "PEM", data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw})},
+		{name: "PKCS#12", data: truststore, password: testPassword},
+		{name: "PKCS#12 wrong password", data: truststore, password: "wrong", err: "failed to read PKCS#12 truststore"},
+		{name: "JKS", data: jks, password: testPassword},
+		{name: "JKS without password", data: jks},
+		{name: "JKS wrong password", data: jks, password: "wrong", err: "wrong password or corrupted keystore"},
+		{name: "JKS without certificates", data: encodeJKS(t, testPassword, nil, nil, nil), err: "no trusted certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := loadCACertificates(writeTestFile(t, "ca", tt.data), tt.password)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadCACertificates() error = %v", err)
+			}
+			if _, err := ca.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+				t.Errorf("Expected the CA to be trusted: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfig_KeyPassword(t *testing.T) {
+	t.Setenv(defaultKeyPasswordEnv, "from-env")
+	t.Setenv("TEAM_TRUSTSTORE_PASSWORD", "changeit")
+
+	config := &Config{}
+	if got, _ := config.keyPassword(); got != "from-env" {
+		t.Errorf("Expected the password from the environment, got %q", got)
+	}
+	if got, _ := config.truststorePassword(); got != "from-env" {
+		t.Errorf("Expected the truststore password to fall back to the key password, got %q", got)
+	}
+
+	config = &Config{KeyPassword: "typed", TruststorePasswordEnv: "TEAM_TRUSTSTORE_PASSWORD"}
+	if got, _ := config.keyPassword(); got != "typed" {
+		t.Errorf("Expected the typed password to win, got %q", got)
+	}
+	if got, _ := config.truststorePassword(); got != "changeit" {
+		t.Errorf("Expected the truststore password, got %q", got)
+	}
+
+	config = &Config{KeyPassword: "env:TEAM_TRUSTSTORE_PASSWORD"}
+	if got, _ := config.keyPassword(); got != "changeit" {
+		t.Errorf("Expected the referenced password, got %q", got)
+	}
+	config = &Config{KeyPassword: "env:MISSING_KEY_PASSWORD"}
+	if _, err := config.keyPassword(); err == nil || !strings.Contains(err.Error(), "failed to resolve key password") {
+		t.Errorf("Expected a resolution error, got %v", err)
+	}
+}
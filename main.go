@@ -19,12 +19,44 @@ func main() {
 		case "--help", "-h":
 			fmt.Println("Kafka Producer UI - Terminal UI for Apache Kafka")
 			fmt.Println("\nUsage:")
-			fmt.Println("  kafka-producer-ui          Start the interactive UI")
-			fmt.Println("  kafka-producer-ui --version Show version")
-			fmt.Println("  kafka-producer-ui --help    Show this help")
-			fmt.Println("\nConfiguration file: ~/.kafka-producer.json")
+			fmt.Println("  kafka-producer-ui                  Start the interactive UI")
+			fmt.Println("  kafka-producer-ui config validate  Check the configuration file")
+			fmt.Println("  kafka-producer-ui config import    Import client.properties or kcat.conf")
+			fmt.Println("  kafka-producer-ui config export    Write client.properties for the Kafka tools")
+			fmt.Println("  kafka-producer-ui diagnose         Run connection diagnostics")
+			fmt.Println("  kafka-producer-ui topic ...        Create, alter and delete topics")
+			fmt.Println("  kafka-producer-ui offsets reset    Reset consumer group offsets")
+			fmt.Println("  kafka-producer-ui copy SRC [DST]   Copy messages between topics")
+			fmt.Println("  kafka-producer-ui consume [TOPIC]  Print messages matching a filter")
+			fmt.Println("  kafka-producer-ui export [TOPIC]   Export messages to JSONL, CSV or raw files")
+			fmt.Println("  kafka-producer-ui produce --file   Send messages from a JSONL file")
+			fmt.Println("  kafka-producer-ui serve            Serve an HTTP API for sending messages")
+			fmt.Println("  kafka-producer-ui scenario FILE    Run a YAML scenario and report JUnit XML")
+			fmt.Println("  kafka-producer-ui --version        Show version")
+			fmt.Println("  kafka-producer-ui --help           Show this help")
+			fmt.Println("\nConfiguration file: ~/.config/kafka-producer-ui/config.{yaml,toml,json} or ~/.kafka-producer.json")
 			fmt.Println("Documentation: https://github.com/seredavin/kafka-test")
 			os.Exit(0)
+		case "config":
+			os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "diagnose":
+			os.Exit(runDiagnoseCommand(os.Stdout, os.Stderr))
+		case "topic":
+			os.Exit(runTopicCommand(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "offsets":
+			os.Exit(runOffsetsCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "copy":
+			os.Exit(runCopyCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "consume":
+			os.Exit(runConsumeCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "export":
+			os.Exit(runExportCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "produce":
+			os.Exit(runProduceCommand(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+		case "serve":
+			os.Exit(runServeCommand(os.Args[2:], os.Stdout, os.Stderr))
+		case "scenario":
+			os.Exit(runScenarioCommand(os.Args[2:], os.Stdout, os.Stderr))
 		}
 	}
 
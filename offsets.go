@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Offset reset strategies
+const (
+	resetEarliest  = "earliest"
+	resetLatest    = "latest"
+	resetOffset    = "offset"
+	resetTimestamp = "timestamp"
+	resetShift     = "shift"
+)
+
+// ResetStrategy describes where consumer group offsets are moved to. Value
+// is the target offset, the timestamp in milliseconds or the shift amount
+// depending on Kind.
+type ResetStrategy struct {
+	Kind  string
+	Value int64
+}
+
+func (s ResetStrategy) String() string {
+	switch s.Kind {
+	case resetOffset:
+		return fmt.Sprintf("offset %d", s.Value)
+	case resetTimestamp:
+		return "timestamp " + time.UnixMilli(s.Value).UTC().Format(time.RFC3339)
+	case resetShift:
+		return fmt.Sprintf("shift by %+d", s.Value)
+	}
+	return s.Kind
+}
+
+// parseTimestamp parses a time given as RFC 3339 or Unix milliseconds
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		return ts, nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms < 0 {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC 3339 or Unix milliseconds", value)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// ParseResetStrategy parses a strategy name and its value. Timestamps are
+// accepted as RFC 3339 or Unix milliseconds.
+func ParseResetStrategy(kind, value string) (ResetStrategy, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	value = strings.TrimSpace(value)
+
+	switch kind {
+	case resetEarliest, resetLatest:
+		return ResetStrategy{Kind: kind}, nil
+
+	case resetOffset, resetShift:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return ResetStrategy{}, fmt.Errorf("invalid %s value %q, expected a number", kind, value)
+		}
+		if kind == resetOffset && n < 0 {
+			return ResetStrategy{}, fmt.Errorf("offset must not be negative")
+		}
+		return ResetStrategy{Kind: kind, Value: n}, nil
+
+	case resetTimestamp:
+		ts, err := parseTimestamp(value)
+		if err != nil {
+			return ResetStrategy{}, err
+		}
+		return ResetStrategy{Kind: kind, Value: ts.UnixMilli()}, nil
+	}
+
+	return ResetStrategy{}, fmt.Errorf("unknown reset strategy %q (use earliest, latest, offset, timestamp or shift)", kind)
+}
+
+// OffsetChange is the planned offset reset of one partition. Current is -1
+// when the group has no committed offset for the partition.
+type OffsetChange struct {
+	Partition int32
+	Current   int64
+	Target    int64
+	Low       int64
+	High      int64
+}
+
+// ActiveMembers returns the number of members currently in a consumer group
+func (a *KafkaAdmin) ActiveMembers(group string) (int, error) {
+	descriptions, err := a.admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		return 0, fmt.Errorf("failed to describe group %q: %w", group, err)
+	}
+	if len(descriptions) == 0 {
+		return 0, nil
+	}
+	return len(descriptions[0].Members), nil
+}
+
+// PlanOffsetReset computes the new offset of every partition of topic for
+// group. Targets are clamped to the available range [low, high]. A shift
+// from a partition without a committed offset starts at the high watermark.
+func (a *KafkaAdmin) PlanOffsetReset(group, topic string, strategy ResetStrategy) ([]OffsetChange, error) {
+	partitions, err := a.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+
+	offsets, err := a.admin.ListConsumerGroupOffsets(group, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch offsets of group %q: %w", group, err)
+	}
+
+	changes := make([]OffsetChange, 0, len(partitions))
+	for _, partition := range partitions {
+		change := OffsetChange{Partition: partition, Current: -1}
+		if block := offsets.GetBlock(topic, partition); block != nil && block.Err == sarama.ErrNoError {
+			change.Current = block.Offset
+		}
+
+		if change.Low, err = a.client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+			return nil, fmt.Errorf("failed to get low watermark of %s/%d: %w", topic, partition, err)
+		}
+		if change.High, err = a.client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+			return nil, fmt.Errorf("failed to get high watermark of %s/%d: %w", topic, partition, err)
+		}
+
+		switch strategy.Kind {
+		case resetEarliest:
+			change.Target = change.Low
+		case resetLatest:
+			change.Target = change.High
+		case resetOffset:
+			change.Target = strategy.Value
+		case resetShift:
+			base := change.Current
+			if base < 0 {
+				base = change.High
+			}
+			change.Target = base + strategy.Value
+		case resetTimestamp:
+			offset, err := a.client.GetOffset(topic, partition, strategy.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up offset by timestamp on %s/%d: %w", topic, partition, err)
+			}
+			// No message at or after the timestamp: move to the end
+			if offset < 0 {
+				offset = change.High
+			}
+			change.Target = offset
+		default:
+			return nil, fmt.Errorf("unknown reset strategy %q", strategy.Kind)
+		}
+		change.Target = min(max(change.Target, change.Low), change.High)
+
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Partition < changes[j].Partition })
+
+	return changes, nil
+}
+
+// ResetOffsets commits the planned offsets for group. It refuses to run while
+// the group has active members, since they would overwrite the new offsets
+// with their next commit.
+func (a *KafkaAdmin) ResetOffsets(group, topic string, changes []OffsetChange) error {
+	members, err := a.ActiveMembers(group)
+	if err != nil {
+		return err
+	}
+	if members > 0 {
+		return fmt.Errorf("group %q has %d active member(s), stop its consumers before resetting offsets", group, members)
+	}
+
+	coordinator, err := a.client.Coordinator(group)
+	if err != nil {
+		return fmt.Errorf("failed to find coordinator of group %q: %w", group, err)
+	}
+
+	req := &sarama.OffsetCommitRequest{
+		Version:                 2,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: -1,
+		RetentionTime:           -1,
+	}
+	for _, change := range changes {
+		req.AddBlock(topic, change.Partition, change.Target, 0, "")
+	}
+
+	resp, err := coordinator.CommitOffset(req)
+	if err != nil {
+		return fmt.Errorf("failed to commit offsets of group %q: %w", group, err)
+	}
+	for _, partitions := range resp.Errors {
+		for partition, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				return fmt.Errorf("failed to commit offset of %s/%d: %w", topic, partition, kerr)
+			}
+		}
+	}
+
+	return nil
+}
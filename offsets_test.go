@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func TestParseResetStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		value   string
+		want    ResetStrategy
+		wantErr bool
+	}{
+		{name: "earliest", kind: "earliest", want: ResetStrategy{Kind: resetEarliest}},
+		{name: "latest ignores value", kind: " Latest ", value: "5", want: ResetStrategy{Kind: resetLatest}},
+		{name: "offset", kind: "offset", value: "42", want: ResetStrategy{Kind: resetOffset, Value: 42}},
+		{name: "negative offset", kind: "offset", value: "-1", wantErr: true},
+		{name: "shift back", kind: "shift", value: "-10", want: ResetStrategy{Kind: resetShift, Value: -10}},
+		{name: "shift without value", kind: "shift", wantErr: true},
+		{name: "timestamp RFC 3339", kind: "timestamp", value: "2023-11-14T22:13:20Z", want: ResetStrategy{Kind: resetTimestamp, Value: testResetTimestamp}},
+		{name: "timestamp millis", kind: "timestamp", value: "1700000000000", want: ResetStrategy{Kind: resetTimestamp, Value: testResetTimestamp}},
+		{name: "invalid timestamp", kind: "timestamp", value: "yesterday", wantErr: true},
+		{name: "unknown", kind: "middle", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseResetStrategy(tt.kind, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseResetStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestKafkaAdmin_PlanOffsetReset(t *testing.T) {
+	admin := newTestAdmin(t, newGroupsCluster(t))
+
+	// billing has committed 40 on partition 0 and nothing on partition 1;
+	// partition 0 holds offsets 10-50, partition 1 holds 0-7
+	tests := []struct {
+		name     string
+		strategy ResetStrategy
+		want     [2]int64
+	}{
+		{name: "earliest", strategy: ResetStrategy{Kind: resetEarliest}, want: [2]int64{10, 0}},
+		{name: "latest", strategy: ResetStrategy{Kind: resetLatest}, want: [2]int64{50, 7}},
+		{name: "offset clamped to range", strategy: ResetStrategy{Kind: resetOffset, Value: 20}, want: [2]int64{20, 7}},
+		{name: "shift back", strategy: ResetStrategy{Kind: resetShift, Value: -5}, want: [2]int64{35, 2}},
+		{name: "shift past the start", strategy: ResetStrategy{Kind: resetShift, Value: -100}, want: [2]int64{10, 0}},
+		{name: "timestamp", strategy: ResetStrategy{Kind: resetTimestamp, Value: testResetTimestamp}, want: [2]int64{45, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := admin.PlanOffsetReset("billing", "orders", tt.strategy)
+			if err != nil {
+				t.Fatalf("PlanOffsetReset() error = %v", err)
+			}
+			if len(changes) != 2 {
+				t.Fatalf("Expected 2 partitions, got %d", len(changes))
+			}
+			for i, change := range changes {
+				if change.Target != tt.want[i] {
+					t.Errorf("Partition %d: expected target %d, got %d", change.Partition, tt.want[i], change.Target)
+				}
+			}
+			if changes[0].Current != 40 || changes[1].Current != -1 {
+				t.Errorf("Expected current offsets 40 and -1, got %d and %d", changes[0].Current, changes[1].Current)
+			}
+		})
+	}
+}
+
+func TestKafkaAdmin_ResetOffsets_ActiveMembers(t *testing.T) {
+	broker := newGroupsCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	err := admin.ResetOffsets("billing", "orders", []OffsetChange{{Partition: 0, Target: 10}})
+	if err == nil {
+		t.Fatal("Expected error for a group with active members")
+	}
+	if _, ok := lastRequest[*sarama.OffsetCommitRequest](broker); ok {
+		t.Error("Expected no offsets to be committed")
+	}
+}
+
+func TestKafkaAdmin_ResetOffsets(t *testing.T) {
+	broker := newGroupsCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	changes := []OffsetChange{{Partition: 0, Target: 10}, {Partition: 1, Target: 3}}
+	if err := admin.ResetOffsets("audit", "orders", changes); err != nil {
+		t.Fatalf("ResetOffsets() error = %v", err)
+	}
+
+	req, ok := lastRequest[*sarama.OffsetCommitRequest](broker)
+	if !ok {
+		t.Fatal("Expected an OffsetCommitRequest")
+	}
+	if req.ConsumerGroup != "audit" {
+		t.Errorf("Expected group audit, got %s", req.ConsumerGroup)
+	}
+	for _, change := range changes {
+		offset, _, err := req.Offset("orders", change.Partition)
+		if err != nil || offset != change.Target {
+			t.Errorf("Partition %d: expected offset %d, got %d (%v)", change.Partition, change.Target, offset, err)
+		}
+	}
+}
@@ -2,9 +2,7 @@ package main
 
 import (
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -19,20 +17,97 @@ const (
 
 // KafkaProducer manages Kafka producer connection
 type KafkaProducer struct {
-	producer sarama.SyncProducer
-	config   *Config
+	producer  sarama.SyncProducer
+	config    *Config
+	transform *Transformer
+}
+
+// MessageHeader is a Kafka record header
+type MessageHeader struct {
+	Key   string
+	Value string
+}
+
+// OutgoingMessage is a fully specified record for Send. Key and Value are
+// sent as-is without serde encoding. Topic defaults to the configured topic,
+// a negative Partition lets the partitioner choose and a zero Timestamp is
+// set by the producer.
+type OutgoingMessage struct {
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   []MessageHeader
+	Partition int32
+	Timestamp time.Time
+}
+
+// explicitPartition marks producer messages whose Partition was chosen by
+// the caller
+type explicitPartition struct{}
+
+// partitioner hashes keys like sarama's default partitioner but keeps the
+// partition of messages marked with explicitPartition
+type partitioner struct {
+	hash sarama.Partitioner
+}
+
+func newPartitioner(topic string) sarama.Partitioner {
+	return &partitioner{hash: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *partitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if _, ok := msg.Metadata.(explicitPartition); ok {
+		if msg.Partition < 0 || msg.Partition >= numPartitions {
+			return -1, fmt.Errorf("partition %d does not exist, topic %q has %d partition(s)", msg.Partition, msg.Topic, numPartitions)
+		}
+		return msg.Partition, nil
+	}
+	return p.hash.Partition(msg, numPartitions)
+}
+
+func (p *partitioner) RequiresConsistency() bool {
+	return true
 }
 
 // NewKafkaProducer creates a new Kafka producer with mTLS support
 func NewKafkaProducer(config *Config) (*KafkaProducer, error) {
+	saramaConfig, err := newSaramaConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	saramaConfig.Producer.Partitioner = newPartitioner
+
+	var transform *Transformer
+	if config.TransformScript != "" {
+		transform, err = LoadTransformer(config.TransformScript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transform script: %w", err)
+		}
+	}
+
+	producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+
+	return &KafkaProducer{
+		producer:  producer,
+		config:    config,
+		transform: transform,
+	}, nil
+}
+
+// newSaramaConfig builds the sarama client configuration shared by the
+// producer and every other Kafka client the application creates
+func newSaramaConfig(config *Config) (*sarama.Config, error) {
 	saramaConfig := sarama.NewConfig()
 	saramaConfig.Producer.Return.Successes = true
 	saramaConfig.Producer.Timeout = 10 * time.Second
 	saramaConfig.Producer.Retry.Max = 3
 
-	// Configure mTLS if enabled
-	if config.UseAuth {
-		tlsConfig, err := createTLSConfig(config.CertFile, config.KeyFile, config.CAFile)
+	// Configure TLS, with a client certificate when mTLS is enabled
+	if config.tlsEnabled() {
+		tlsConfig, err := createTLSConfig(config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create TLS config: %w", err)
 		}
@@ -41,40 +116,48 @@ func NewKafkaProducer(config *Config) (*KafkaProducer, error) {
 		saramaConfig.Net.TLS.Config = tlsConfig
 	}
 
-	producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create producer: %w", err)
+	if err := configureSASL(saramaConfig, config); err != nil {
+		return nil, err
 	}
 
-	return &KafkaProducer{
-		producer: producer,
-		config:   config,
-	}, nil
+	return saramaConfig, nil
 }
 
-// createTLSConfig creates TLS configuration for mTLS
-func createTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
-	// Load client certificate and key
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load client certificate: %w", err)
-	}
-
-	// Load CA certificate
-	caCert, err := os.ReadFile(caFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+// createTLSConfig creates TLS configuration for the broker connections. The
+// client certificate is only loaded for mTLS, and without a CA file the
+// brokers are verified against the system cert pool. Both may be PEM files
+// or PKCS#12/JKS keystores.
+func createTLSConfig(config *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.TLSServerName,
+		InsecureSkipVerify: config.TLSInsecureSkipVerify, // #nosec G402 -- opt-in for dev clusters, ValidateConfig warns about it
+		MinVersion:         tls.VersionTLS12,
 	}
 
-	caCertPool := x509.NewCertPool()
-	if !caCertPool.AppendCertsFromPEM(caCert) {
-		return nil, fmt.Errorf("failed to parse CA certificate")
+	// Load client certificate and key
+	if config.UseAuth {
+		password, err := config.keyPassword()
+		if err != nil {
+			return nil, err
+		}
+		cert, err := loadClientCertificate(config.CertFile, config.KeyFile, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool,
-		MinVersion:   tls.VersionTLS12,
+	// Load CA certificate; nil RootCAs means the system cert pool
+	if config.CAFile != "" {
+		password, err := config.truststorePassword()
+		if err != nil {
+			return nil, err
+		}
+		caCertPool, err := loadCACertificates(config.CAFile, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CA certificate: %w", err)
+		}
+		tlsConfig.RootCAs = caCertPool
 	}
 
 	return tlsConfig, nil
@@ -82,13 +165,82 @@ func createTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
 
 // SendMessage sends a message to Kafka topic
 func (p *KafkaProducer) SendMessage(key, value string) (partition int32, offset int64, err error) {
+	if p.transform != nil {
+		message, err := p.Prepare(key, value)
+		if err != nil {
+			return 0, 0, err
+		}
+		return p.Send(message)
+	}
+
+	keySerde, valueSerde := p.config.serdesFor(p.config.Topic)
 	msg := &sarama.ProducerMessage{
 		Topic: p.config.Topic,
-		Value: p.encodeValue(value, p.config.ValueSerde),
+		Value: p.encodeValue(value, valueSerde),
+	}
+
+	if key != "" {
+		msg.Key = p.encodeValue(key, keySerde)
+	}
+
+	partition, offset, err = p.producer.SendMessage(msg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to send message: %w", err)
 	}
 
+	return partition, offset, nil
+}
+
+// Prepare builds the message SendMessage sends for key and value, after
+// the transform script if one is configured. The serdes only differ in
+// sarama encoder type, so the bytes match those of SendMessage.
+func (p *KafkaProducer) Prepare(key, value string) (OutgoingMessage, error) {
+	keySerde, valueSerde := p.config.serdesFor(p.config.Topic)
+	return p.prepare(p.config.Topic, key, value, keySerde, valueSerde)
+}
+
+func (p *KafkaProducer) prepare(topic, key, value, keySerde, valueSerde string) (OutgoingMessage, error) {
+	msg := OutgoingMessage{Topic: topic, Partition: -1}
+	var err error
+	if msg.Value, err = p.encodeValue(value, valueSerde).Encode(); err != nil {
+		return OutgoingMessage{}, fmt.Errorf("failed to encode value: %w", err)
+	}
 	if key != "" {
-		msg.Key = p.encodeValue(key, p.config.KeySerde)
+		if msg.Key, err = p.encodeValue(key, keySerde).Encode(); err != nil {
+			return OutgoingMessage{}, fmt.Errorf("failed to encode key: %w", err)
+		}
+	}
+	return p.Transform(msg)
+}
+
+// Transform runs the transform script, if one is configured, on a fully
+// specified message, e.g. one received by the HTTP API
+func (p *KafkaProducer) Transform(message OutgoingMessage) (OutgoingMessage, error) {
+	if p.transform == nil {
+		return message, nil
+	}
+	return p.transform.Apply(message)
+}
+
+// Send sends a fully specified message, e.g. one copied from another topic
+func (p *KafkaProducer) Send(message OutgoingMessage) (partition int32, offset int64, err error) {
+	msg := &sarama.ProducerMessage{
+		Topic:     message.Topic,
+		Value:     sarama.ByteEncoder(message.Value),
+		Timestamp: message.Timestamp,
+	}
+	if msg.Topic == "" {
+		msg.Topic = p.config.Topic
+	}
+	if message.Key != nil {
+		msg.Key = sarama.ByteEncoder(message.Key)
+	}
+	if message.Partition >= 0 {
+		msg.Partition = message.Partition
+		msg.Metadata = explicitPartition{}
+	}
+	for _, header := range message.Headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(header.Key), Value: []byte(header.Value)})
 	}
 
 	partition, offset, err = p.producer.SendMessage(msg)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/IBM/sarama"
 )
@@ -111,12 +112,60 @@ func TestEncodeValue_Default(t *testing.T) {
 }
 
 func TestCreateTLSConfig_InvalidCert(t *testing.T) {
-	_, err := createTLSConfig("nonexistent.pem", "nonexistent.key", "nonexistent.ca")
+	_, err := createTLSConfig(&Config{UseAuth: true, CertFile: "nonexistent.pem", KeyFile: "nonexistent.key", CAFile: "nonexistent.ca"})
 	if err == nil {
 		t.Error("Expected error for nonexistent certificates, got nil")
 	}
 }
 
+func TestCreateTLSConfig_ServerOnly(t *testing.T) {
+	tlsConfig, err := createTLSConfig(&Config{
+		UseTLS:                true,
+		TLSServerName:         "kafka.internal",
+		TLSInsecureSkipVerify: true,
+	})
+	if err != nil {
+		t.Fatalf("createTLSConfig() error = %v", err)
+	}
+
+	if len(tlsConfig.Certificates) != 0 {
+		t.Errorf("Expected no client certificate, got %d", len(tlsConfig.Certificates))
+	}
+	if tlsConfig.RootCAs != nil {
+		t.Error("Expected nil RootCAs to use the system cert pool")
+	}
+	if tlsConfig.ServerName != "kafka.internal" {
+		t.Errorf("Expected server name 'kafka.internal', got %q", tlsConfig.ServerName)
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be set")
+	}
+}
+
+func TestNewSaramaConfig_TLS(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		want   bool
+	}{
+		{name: "disabled", config: &Config{}, want: false},
+		{name: "server only", config: &Config{UseTLS: true}, want: true},
+		{name: "insecure flag alone", config: &Config{TLSInsecureSkipVerify: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saramaConfig, err := newSaramaConfig(tt.config)
+			if err != nil {
+				t.Fatalf("newSaramaConfig() error = %v", err)
+			}
+			if saramaConfig.Net.TLS.Enable != tt.want {
+				t.Errorf("Expected TLS enabled %v, got %v", tt.want, saramaConfig.Net.TLS.Enable)
+			}
+		})
+	}
+}
+
 func TestCreateTLSConfig_InvalidCA(t *testing.T) {
 	// Create temp cert and key files
 	tempDir := t.TempDir()
@@ -155,7 +204,7 @@ EKTcWGekdmdDPsHloRNtsiCa697B2O9IFA==
 		t.Fatal(err)
 	}
 
-	_, err := createTLSConfig(certFile, keyFile, caFile)
+	_, err := createTLSConfig(&Config{UseAuth: true, CertFile: certFile, KeyFile: keyFile, CAFile: caFile})
 	if err == nil {
 		t.Error("Expected error for invalid CA certificate, got nil")
 	}
@@ -198,7 +247,7 @@ EKTcWGekdmdDPsHloRNtsiCa697B2O9IFA==
 		t.Fatal(err)
 	}
 
-	tlsConfig, err := createTLSConfig(certFile, keyFile, caFile)
+	tlsConfig, err := createTLSConfig(&Config{UseAuth: true, CertFile: certFile, KeyFile: keyFile, CAFile: caFile})
 	if err != nil {
 		t.Fatalf("createTLSConfig() error = %v", err)
 	}
@@ -391,3 +440,93 @@ func TestKafkaProducer_SendMessage_EmptyKey(t *testing.T) {
 		t.Fatalf("SendMessage() error = %v", err)
 	}
 }
+
+func TestKafkaProducer_Send(t *testing.T) {
+	timestamp := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	var sent *sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{
+			sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+				sent = msg
+				return 2, 7, nil
+			},
+		},
+		config: &Config{Topic: "test-topic", ValueSerde: "string"},
+	}
+
+	partition, offset, err := producer.Send(OutgoingMessage{
+		Key:       []byte("k"),
+		Value:     []byte("v"),
+		Headers:   []MessageHeader{{Key: "trace-id", Value: "abc"}},
+		Partition: 2,
+		Timestamp: timestamp,
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if partition != 2 || offset != 7 {
+		t.Errorf("Expected 2@7, got %d@%d", partition, offset)
+	}
+
+	if sent.Topic != "test-topic" {
+		t.Errorf("Expected default topic, got %s", sent.Topic)
+	}
+	if _, ok := sent.Metadata.(explicitPartition); !ok || sent.Partition != 2 {
+		t.Errorf("Expected explicit partition 2, got %d (%v)", sent.Partition, sent.Metadata)
+	}
+	if !sent.Timestamp.Equal(timestamp) {
+		t.Errorf("Expected timestamp %v, got %v", timestamp, sent.Timestamp)
+	}
+	if len(sent.Headers) != 1 || string(sent.Headers[0].Key) != "trace-id" || string(sent.Headers[0].Value) != "abc" {
+		t.Errorf("Expected trace-id header, got %v", sent.Headers)
+	}
+}
+
+func TestPartitioner(t *testing.T) {
+	p := newPartitioner("test-topic")
+
+	explicit := &sarama.ProducerMessage{Topic: "test-topic", Partition: 3, Metadata: explicitPartition{}}
+	if partition, err := p.Partition(explicit, 4); err != nil || partition != 3 {
+		t.Errorf("Expected explicit partition 3, got %d (%v)", partition, err)
+	}
+
+	explicit.Partition = 4
+	if _, err := p.Partition(explicit, 4); err == nil {
+		t.Error("Expected error for a partition that does not exist")
+	}
+
+	// Keyed messages are hashed consistently
+	keyed := &sarama.ProducerMessage{Topic: "test-topic", Key: sarama.StringEncoder("user-1")}
+	first, _ := p.Partition(keyed, 4)
+	second, _ := p.Partition(keyed, 4)
+	if first != second {
+		t.Errorf("Expected the same partition for the same key, got %d and %d", first, second)
+	}
+}
+
+func TestKafkaProducer_SendMessage_TopicSerdes(t *testing.T) {
+	var sent *sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			sent = msg
+			return 0, 0, nil
+		}},
+		config: &Config{
+			Topic:       "orders.eu",
+			KeySerde:    serdeJSON,
+			ValueSerde:  serdeJSON,
+			TopicSerdes: []TopicSerde{{Pattern: "orders.*", KeySerde: serdeString}},
+		},
+	}
+
+	if _, _, err := producer.SendMessage("order-1", "{}"); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, ok := sent.Key.(sarama.StringEncoder); !ok {
+		t.Errorf("Expected the mapped string serde for the key, got %T", sent.Key)
+	}
+	if _, ok := sent.Value.(sarama.ByteEncoder); !ok {
+		t.Errorf("Expected the global json serde for the value, got %T", sent.Value)
+	}
+}
@@ -0,0 +1,481 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Kafka security.protocol values
+const (
+	protocolPlaintext     = "PLAINTEXT"
+	protocolSSL           = "SSL"
+	protocolSASLPlaintext = "SASL_PLAINTEXT"
+	protocolSASLSSL       = "SASL_SSL"
+)
+
+// JAAS login modules of the supported SASL mechanisms
+const (
+	jaasKerberosModule = "com.sun.security.auth.module.Krb5LoginModule"
+	jaasOAuthModule    = "org.apache.kafka.common.security.oauthbearer.OAuthBearerLoginModule"
+	oauthLoginHandler  = "org.apache.kafka.common.security.oauthbearer.OAuthBearerLoginCallbackHandler"
+)
+
+// jaasOption matches key=value and key="quoted value" pairs of sasl.jaas.config
+var jaasOption = regexp.MustCompile(`([\w.]+)\s*=\s*(?:"((?:[^"\\]|\\.)*)"|([^\s;]+))`)
+
+// secretProperties are never imported; each maps to where the secret goes instead
+var secretProperties = map[string]string{
+	"ssl.key.password":               fieldKeyPassword,
+	"ssl.keystore.password":          fieldKeyPassword,
+	"ssl.truststore.password":        "$" + defaultTruststorePasswordEnv,
+	"sasl.oauthbearer.client.secret": fieldOAuthClientSecret,
+}
+
+// parseProperties reads the key=value syntax shared by Java .properties
+// files and librdkafka configs such as kcat.conf: "#" and "!" comments,
+// "=", ":" or whitespace separators, trailing-backslash continuations and
+// backslash escapes
+func parseProperties(r io.Reader) (map[string]string, error) {
+	props := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+
+	var logical strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical.Len() == 0 && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+
+		// An odd number of trailing backslashes continues the line
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if trailing%2 == 1 {
+			logical.WriteString(line[:len(line)-1])
+			continue
+		}
+		logical.WriteString(line)
+
+		key, value := splitProperty(logical.String())
+		props[unescapeProperty(key)] = unescapeProperty(value)
+		logical.Reset()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if logical.Len() > 0 {
+		key, value := splitProperty(logical.String())
+		props[unescapeProperty(key)] = unescapeProperty(value)
+	}
+
+	return props, nil
+}
+
+// splitProperty splits a logical line at the first unescaped separator
+func splitProperty(line string) (key, value string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':':
+			return line[:i], strings.TrimLeft(line[i+1:], " \t\f")
+		case ' ', '\t', '\f':
+			rest := strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return line[:i], rest
+		}
+	}
+	return line, ""
+}
+
+// unescapeProperty resolves \t, \n, \r, \f, \uXXXX and escaped characters
+func unescapeProperty(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// parseJAASConfig returns the login module and options of sasl.jaas.config
+func parseJAASConfig(value string) (module string, options map[string]string) {
+	value = strings.TrimSpace(value)
+	module, _, _ = strings.Cut(value, " ")
+	options = make(map[string]string)
+	for _, m := range jaasOption.FindAllStringSubmatch(value, -1) {
+		if m[3] != "" {
+			options[m[1]] = m[3]
+		} else {
+			options[m[1]] = strings.ReplaceAll(m[2], `\"`, `"`)
+		}
+	}
+	return module, options
+}
+
+// importClientProperties replaces the connection settings of config —
+// brokers, TLS and SASL — with those of a Java client.properties or a
+// librdkafka config such as kcat.conf. Secrets are not imported. The
+// returned notes list what could not be carried over.
+func importClientProperties(config *Config, props map[string]string) (notes []string) {
+	notef := func(format string, args ...any) {
+		notes = append(notes, fmt.Sprintf(format, args...))
+	}
+	handled := make(map[string]bool)
+	get := func(keys ...string) string {
+		for _, key := range keys {
+			handled[key] = true
+		}
+		for _, key := range keys {
+			if value, ok := props[key]; ok {
+				return strings.TrimSpace(value)
+			}
+		}
+		return ""
+	}
+
+	if servers := get("bootstrap.servers", "metadata.broker.list"); servers != "" {
+		config.Brokers = nil
+		for _, broker := range strings.Split(servers, ",") {
+			if broker = strings.TrimSpace(broker); broker != "" {
+				config.Brokers = append(config.Brokers, broker)
+			}
+		}
+	}
+
+	// Start from a clean slate so that settings of the previous cluster do
+	// not leak into the imported one
+	config.CertFile, config.KeyFile, config.CAFile = "", "", ""
+	config.KeyPassword = ""
+	config.UseAuth, config.UseTLS = false, false
+	config.TLSServerName, config.TLSInsecureSkipVerify = "", false
+	config.SASLMechanism = ""
+	config.OAuthTokenURL, config.OAuthClientID, config.OAuthClientSecret = "", "", ""
+	config.OAuthScopes, config.OAuthAudience, config.OAuthExtensions = nil, "", nil
+	config.KerberosUsername, config.KerberosRealm, config.KerberosPassword = "", "", ""
+	config.KerberosKeytabPath, config.KerberosConfigPath, config.KerberosServiceName = "", "", ""
+	config.KerberosDisablePAFXFAST = false
+
+	protocol := strings.ToUpper(cmp.Or(get("security.protocol"), protocolPlaintext))
+	switch protocol {
+	case protocolPlaintext, protocolSSL, protocolSASLPlaintext, protocolSASLSSL:
+	default:
+		notef("security.protocol %q is unknown, assuming %s", protocol, protocolPlaintext)
+		protocol = protocolPlaintext
+	}
+	config.UseTLS = protocol == protocolSSL || protocol == protocolSASLSSL
+
+	// TLS: Java keystores and truststores, librdkafka PEM locations
+	config.CAFile = get("ssl.truststore.location", "ssl.ca.location")
+	if config.CAFile == "probe" {
+		config.CAFile = "" // librdkafka's system CA lookup
+	}
+	config.CertFile = get("ssl.keystore.location", "ssl.certificate.location")
+	config.KeyFile = get("ssl.key.location")
+	if strings.EqualFold(get("ssl.keystore.type"), formatPEM) && config.KeyFile == "" {
+		config.KeyFile = config.CertFile // a Java PEM keystore holds the key and the chain
+	}
+	get("ssl.truststore.type")
+	config.UseAuth = mtlsConfigured(config.CertFile, config.KeyFile)
+
+	if strings.EqualFold(get("enable.ssl.certificate.verification"), "false") {
+		config.TLSInsecureSkipVerify = true
+	}
+	if algorithm, ok := props["ssl.endpoint.identification.algorithm"]; ok {
+		handled["ssl.endpoint.identification.algorithm"] = true
+		if algorithm = strings.TrimSpace(algorithm); algorithm == "" || strings.EqualFold(algorithm, "none") {
+			notef("ssl.endpoint.identification.algorithm disables only the host name check, which cannot be turned off on its own; set %s to the name in the broker certificate", fieldTLSServer)
+		}
+	}
+	for _, key := range []string{"ssl.truststore.certificates", "ssl.keystore.certificate.chain", "ssl.keystore.key", "ssl.ca.pem", "ssl.certificate.pem", "ssl.key.pem"} {
+		if _, ok := props[key]; ok {
+			handled[key] = true
+			notef("%s holds PEM data inline; save it to a file and set %s, %s or %s", key, fieldCAFile, fieldCertFile, fieldKeyFile)
+		}
+	}
+
+	// SASL: Java defaults to GSSAPI when the protocol asks for SASL
+	mechanism := strings.ToUpper(get("sasl.mechanism", "sasl.mechanisms"))
+	if mechanism == "" && strings.HasPrefix(protocol, "SASL_") {
+		mechanism = saslGSSAPI
+	}
+	if mechanism != "" && !strings.HasPrefix(protocol, "SASL_") {
+		notef("sasl.mechanism %s is ignored because security.protocol is %s", mechanism, protocol)
+		mechanism = ""
+	}
+	module, jaas := parseJAASConfig(get("sasl.jaas.config"))
+
+	switch mechanism {
+	case "":
+	case saslGSSAPI:
+		config.SASLMechanism = saslGSSAPI
+		config.KerberosServiceName = get("sasl.kerberos.service.name")
+		config.KerberosUsername = cmp.Or(get("sasl.kerberos.principal"), jaas["principal"])
+		config.KerberosKeytabPath = cmp.Or(get("sasl.kerberos.keytab"), jaas["keyTab"])
+		if module != "" && module != jaasKerberosModule {
+			notef("sasl.jaas.config uses %s, expected %s", module, jaasKerberosModule)
+		}
+		if jaas["useTicketCache"] == "true" && config.KerberosKeytabPath == "" {
+			notef("the Kerberos ticket cache is not supported, set %s or %s", fieldKerberosKeytab, fieldKerberosPassword)
+		}
+	case saslOAuthBearer:
+		config.SASLMechanism = saslOAuthBearer
+		config.OAuthTokenURL = get("sasl.oauthbearer.token.endpoint.url")
+		config.OAuthClientID = cmp.Or(get("sasl.oauthbearer.client.id"), jaas["clientId"])
+		if scope := cmp.Or(get("sasl.oauthbearer.scope"), jaas["scope"]); scope != "" {
+			config.OAuthScopes = strings.Fields(strings.ReplaceAll(scope, ",", " "))
+		}
+		for name, value := range jaas {
+			if extension, ok := strings.CutPrefix(name, "extension_"); ok {
+				config.OAuthExtensions = setExtension(config.OAuthExtensions, extension, value)
+			}
+		}
+		for _, pair := range strings.Split(get("sasl.oauthbearer.extensions"), ",") {
+			if name, value, ok := strings.Cut(pair, "="); ok {
+				config.OAuthExtensions = setExtension(config.OAuthExtensions, strings.TrimSpace(name), strings.TrimSpace(value))
+			}
+		}
+		if _, ok := jaas["clientSecret"]; ok {
+			notef("the clientSecret of sasl.jaas.config is a secret and was not imported; set %s to a reference such as env:VAR", fieldOAuthClientSecret)
+		}
+		get("sasl.login.callback.handler.class", "sasl.oauthbearer.method")
+	default:
+		notef("SASL mechanism %s is not supported, expected one of %s", mechanism, strings.Join(saslMechanisms, ", "))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(secretProperties)) {
+		if _, ok := props[key]; ok {
+			handled[key] = true
+			target := secretProperties[key]
+			if strings.HasPrefix(target, "$") {
+				notef("%s is a secret and was not imported; export it as %s", key, target)
+			} else {
+				notef("%s is a secret and was not imported; set %s to a reference such as env:VAR", key, target)
+			}
+		}
+	}
+	get("sasl.kerberos.kinit.cmd", "sasl.kerberos.min.time.before.relogin")
+
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		if handled[key] {
+			continue
+		}
+		if strings.HasPrefix(key, "ssl.") || strings.HasPrefix(key, "sasl.") {
+			notef("%s is not supported and was skipped", key)
+		}
+	}
+
+	return notes
+}
+
+// setExtension adds a SASL extension, allocating the map on first use
+func setExtension(extensions map[string]string, name, value string) map[string]string {
+	if extensions == nil {
+		extensions = make(map[string]string)
+	}
+	extensions[name] = value
+	return extensions
+}
+
+// importClientPropertiesFile reads path and imports it into config
+func importClientPropertiesFile(config *Config, path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	props, err := parseProperties(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return importClientProperties(config, props), nil
+}
+
+// writeClientProperties writes the connection settings of config as a Java
+// client.properties for kafka-console-producer and the other Kafka tools.
+// Secrets are never written: the properties that need them are left as
+// comments to fill in.
+func writeClientProperties(w io.Writer, config *Config) error {
+	var b strings.Builder
+	set := func(key, value string) {
+		fmt.Fprintf(&b, "%s=%s\n", key, escapeProperty(value))
+	}
+	comment := func(format string, args ...any) {
+		fmt.Fprintf(&b, "# "+format+"\n", args...)
+	}
+
+	comment("Generated by kafka-producer-ui")
+	set("bootstrap.servers", strings.Join(config.Brokers, ","))
+
+	mechanism := config.saslMechanism()
+	tlsEnabled := config.tlsEnabled()
+	switch {
+	case mechanism != "" && tlsEnabled:
+		set("security.protocol", protocolSASLSSL)
+	case mechanism != "":
+		set("security.protocol", protocolSASLPlaintext)
+	case tlsEnabled:
+		set("security.protocol", protocolSSL)
+	default:
+		set("security.protocol", protocolPlaintext)
+	}
+
+	if tlsEnabled {
+		b.WriteString("\n")
+		writeTLSProperties(&b, config, set, comment)
+	}
+
+	switch mechanism {
+	case saslGSSAPI:
+		b.WriteString("\n")
+		username, realm := config.kerberosPrincipal()
+		principal := username
+		if realm != "" {
+			principal += "@" + realm
+		}
+		set("sasl.mechanism", saslGSSAPI)
+		set("sasl.kerberos.service.name", cmp.Or(config.KerberosServiceName, defaultKerberosServiceName))
+		if config.KerberosKeytabPath != "" {
+			set("sasl.jaas.config", fmt.Sprintf(`%s required useKeyTab=true storeKey=true keyTab="%s" principal="%s";`,
+				jaasKerberosModule, config.KerberosKeytabPath, principal))
+		} else {
+			comment("Password logins need a keytab or a ticket cache (kinit %s) with Java:", principal)
+			comment(`sasl.jaas.config=%s required useTicketCache=true principal="%s";`, jaasKerberosModule, principal)
+		}
+		comment("Pass -Djava.security.krb5.conf=%s to the JVM", config.kerberosConfigPath())
+	case saslOAuthBearer:
+		b.WriteString("\n")
+		set("sasl.mechanism", saslOAuthBearer)
+		set("sasl.oauthbearer.token.endpoint.url", config.OAuthTokenURL)
+		set("sasl.login.callback.handler.class", oauthLoginHandler)
+
+		options := fmt.Sprintf(`clientId="%s" clientSecret="<secret>"`, config.OAuthClientID)
+		if len(config.OAuthScopes) > 0 {
+			options += fmt.Sprintf(` scope="%s"`, strings.Join(config.OAuthScopes, " "))
+		}
+		for _, name := range slices.Sorted(maps.Keys(config.OAuthExtensions)) {
+			options += fmt.Sprintf(` extension_%s="%s"`, name, config.OAuthExtensions[name])
+		}
+		comment("Replace <secret> with the client secret, which is not exported")
+		set("sasl.jaas.config", fmt.Sprintf("%s required %s;", jaasOAuthModule, options))
+		if config.OAuthAudience != "" {
+			comment("The token endpoint audience %q has no client.properties equivalent", config.OAuthAudience)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTLSProperties writes the truststore and keystore properties
+func writeTLSProperties(b *strings.Builder, config *Config, set func(key, value string), comment func(format string, args ...any)) {
+	if config.CAFile != "" {
+		set("ssl.truststore.location", config.CAFile)
+		set("ssl.truststore.type", storeType(config.CAFile))
+		if storeType(config.CAFile) != formatPEM {
+			comment("ssl.truststore.password=<secret, not exported>")
+		}
+	} else {
+		comment("No CA file: the JVM default truststore is used")
+	}
+
+	switch {
+	case !config.UseAuth:
+	case isKeystore(config.CertFile):
+		set("ssl.keystore.location", config.CertFile)
+		set("ssl.keystore.type", storeType(config.CertFile))
+		comment("ssl.keystore.password=<secret, not exported>")
+	case config.CertFile == config.KeyFile:
+		set("ssl.keystore.location", config.CertFile)
+		set("ssl.keystore.type", formatPEM)
+		comment("ssl.key.password=<secret, not exported, only for an encrypted key>")
+	default:
+		comment("Java reads a PEM keystore from one file with the key and the chain, create it with")
+		comment("  cat %s %s > client.pem", config.KeyFile, config.CertFile)
+		comment("ssl.keystore.location=client.pem")
+		comment("ssl.keystore.type=PEM")
+	}
+
+	if config.TLSServerName != "" {
+		comment("The TLS server name %q has no client.properties equivalent", config.TLSServerName)
+	}
+	if config.TLSInsecureSkipVerify {
+		comment("Certificate verification is disabled in kafka-producer-ui; Java still verifies the chain, only the host name check is off")
+		set("ssl.endpoint.identification.algorithm", "")
+	}
+}
+
+// storeType returns the ssl.*.type of a certificate file: PEM, PKCS12 or JKS
+func storeType(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return formatPEM
+	}
+	switch certificateFormat(data) {
+	case formatPKCS12:
+		return "PKCS12"
+	case formatJKS:
+		return formatJKS
+	default:
+		return formatPEM
+	}
+}
+
+// escapeProperty escapes a value for a .properties file
+func escapeProperty(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == ' ' && i == 0:
+			b.WriteString(`\ `)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestParseProperties(t *testing.T) {
+	input := `# Java client.properties
+! also a comment
+bootstrap.servers=broker1:9093,\
+    broker2:9093
+security.protocol : SASL_SSL
+sasl.mechanism GSSAPI
+ssl.truststore.location=C:\\certs\\truststore.jks
+client.id=caf\u00e9 producer
+empty.value=
+  indented.key = value with = sign
+`
+	props, err := parseProperties(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseProperties() error = %v", err)
+	}
+
+	expected := map[string]string{
+		"bootstrap.servers":       "broker1:9093,broker2:9093",
+		"security.protocol":       "SASL_SSL",
+		"sasl.mechanism":          "GSSAPI",
+		"ssl.truststore.location": `C:\certs\truststore.jks`,
+		"client.id":               "café producer",
+		"empty.value":             "",
+		"indented.key":            "value with = sign",
+	}
+	if len(props) != len(expected) {
+		t.Errorf("Expected %d properties, got %d: %v", len(expected), len(props), props)
+	}
+	for key, value := range expected {
+		if props[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, props[key])
+		}
+	}
+}
+
+func TestImportClientProperties(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		check func(t *testing.T, config *Config)
+		notes []string
+	}{
+		{
+			name: "Java SASL_SSL with Kerberos keytab",
+			props: map[string]string{
+				"bootstrap.servers":          "kafka1:9093, kafka2:9093",
+				"security.protocol":          "SASL_SSL",
+				"ssl.truststore.location":    "/etc/kafka/truststore.jks",
+				"ssl.truststore.password":    "changeit",
+				"sasl.kerberos.service.name": "kafka",
+				"sasl.jaas.config":           `com.sun.security.auth.module.Krb5LoginModule required useKeyTab=true keyTab="/etc/security/producer.keytab" principal="svc-producer@CORP.EXAMPLE.COM";`,
+			},
+			check: func(t *testing.T, config *Config) {
+				if !slices.Equal(config.Brokers, []string{"kafka1:9093", "kafka2:9093"}) {
+					t.Errorf("Expected two brokers, got %v", config.Brokers)
+				}
+				if !config.UseTLS || config.UseAuth || config.CAFile != "/etc/kafka/truststore.jks" {
+					t.Errorf("Expected server-only TLS with the truststore, got %+v", config)
+				}
+				if config.SASLMechanism != saslGSSAPI || config.KerberosKeytabPath != "/etc/security/producer.keytab" ||
+					config.KerberosUsername != "svc-producer@CORP.EXAMPLE.COM" || config.KerberosServiceName != "kafka" {
+					t.Errorf("Expected the Kerberos keytab login, got %+v", config)
+				}
+			},
+			notes: []string{"ssl.truststore.password is a secret"},
+		},
+		{
+			name: "Java OAUTHBEARER",
+			props: map[string]string{
+				"bootstrap.servers":                   "pkc-1.cloud:9092",
+				"security.protocol":                   "SASL_SSL",
+				"sasl.mechanism":                      "OAUTHBEARER",
+				"sasl.oauthbearer.token.endpoint.url": "https://auth.example.com/token",
+				"sasl.login.callback.handler.class":   oauthLoginHandler,
+				"sasl.jaas.config":                    `org.apache.kafka.common.security.oauthbearer.OAuthBearerLoginModule required clientId="producer" clientSecret="s3cret" scope="kafka write" extension_logicalCluster="lkc-1";`,
+			},
+			check: func(t *testing.T, config *Config) {
+				if config.SASLMechanism != saslOAuthBearer || config.OAuthTokenURL != "https://auth.example.com/token" || config.OAuthClientID != "producer" {
+					t.Errorf("Expected the OAuth settings, got %+v", config)
+				}
+				if config.OAuthClientSecret != "" {
+					t.Errorf("Expected the client secret not to be imported, got %q", config.OAuthClientSecret)
+				}
+				if !slices.Equal(config.OAuthScopes, []string{"kafka", "write"}) || config.OAuthExtensions["logicalCluster"] != "lkc-1" {
+					t.Errorf("Expected scopes and extensions, got %v and %v", config.OAuthScopes, config.OAuthExtensions)
+				}
+			},
+			notes: []string{"clientSecret of sasl.jaas.config is a secret"},
+		},
+		{
+			name: "kcat with PEM files",
+			props: map[string]string{
+				"metadata.broker.list":                "localhost:9093",
+				"security.protocol":                   "ssl",
+				"ssl.ca.location":                     "/certs/ca.pem",
+				"ssl.certificate.location":            "/certs/client.pem",
+				"ssl.key.location":                    "/certs/client.key",
+				"ssl.key.password":                    "s3cret",
+				"enable.ssl.certificate.verification": "false",
+			},
+			check: func(t *testing.T, config *Config) {
+				if !config.UseAuth || config.CertFile != "/certs/client.pem" || config.KeyFile != "/certs/client.key" || config.CAFile != "/certs/ca.pem" {
+					t.Errorf("Expected mTLS with PEM files, got %+v", config)
+				}
+				if !config.TLSInsecureSkipVerify {
+					t.Error("Expected certificate verification to be disabled")
+				}
+			},
+			notes: []string{"ssl.key.password is a secret and was not imported; set key_password"},
+		},
+		{
+			name: "Java PEM keystore",
+			props: map[string]string{
+				"security.protocol":                     "SSL",
+				"ssl.keystore.type":                     "PEM",
+				"ssl.keystore.location":                 "/certs/client-with-key.pem",
+				"ssl.endpoint.identification.algorithm": "",
+			},
+			check: func(t *testing.T, config *Config) {
+				if !config.UseAuth || config.KeyFile != "/certs/client-with-key.pem" {
+					t.Errorf("Expected the PEM keystore as certificate and key, got %+v", config)
+				}
+			},
+			notes: []string{"set tls_server_name"},
+		},
+		{
+			name: "unsupported mechanism",
+			props: map[string]string{
+				"security.protocol": "SASL_PLAINTEXT",
+				"sasl.mechanism":    "SCRAM-SHA-512",
+				"sasl.username":     "alice",
+				"ssl.cipher.suites": "TLS_AES_256_GCM_SHA384",
+			},
+			check: func(t *testing.T, config *Config) {
+				if config.SASLMechanism != "" || config.UseTLS {
+					t.Errorf("Expected no SASL and no TLS, got %+v", config)
+				}
+			},
+			notes: []string{"SCRAM-SHA-512 is not supported", "sasl.username is not supported", "ssl.cipher.suites is not supported"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Brokers:       []string{"old:9092"},
+				Topic:         testTopic,
+				KeySerde:      serdeJSON,
+				CertFile:      "/old/cert.pem",
+				TLSServerName: "old.example.com",
+			}
+			notes := importClientProperties(config, tt.props)
+
+			if config.Topic != testTopic || config.KeySerde != serdeJSON {
+				t.Errorf("Expected topic and serdes to be kept, got %q and %q", config.Topic, config.KeySerde)
+			}
+			if config.TLSServerName != "" {
+				t.Errorf("Expected settings of the previous cluster to be cleared, got %q", config.TLSServerName)
+			}
+			tt.check(t, config)
+			for _, want := range tt.notes {
+				if !slices.ContainsFunc(notes, func(note string) bool { return strings.Contains(note, want) }) {
+					t.Errorf("Expected a note containing %q, got %v", want, notes)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteClientProperties_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	caFile, _ := writeTestCertificate(t, dir, "ca", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+	key, cert := newTestIdentity(t)
+	p12, err := pkcs12.Modern.Encode(key, cert, nil, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keystore := writeTestFile(t, "client.p12", p12)
+
+	tests := []struct {
+		name   string
+		config *Config
+		want   []string
+	}{
+		{
+			name: "mTLS with keystore and Kerberos",
+			config: &Config{
+				Brokers:            []string{"kafka1:9093", "kafka2:9093"},
+				UseAuth:            true,
+				CertFile:           keystore,
+				CAFile:             caFile,
+				SASLMechanism:      saslGSSAPI,
+				KerberosUsername:   "svc-producer",
+				KerberosRealm:      "CORP.EXAMPLE.COM",
+				KerberosKeytabPath: `C:\keytabs\producer.keytab`,
+			},
+			want: []string{
+				"security.protocol=SASL_SSL",
+				"ssl.keystore.type=PKCS12",
+				"ssl.truststore.type=PEM",
+				"# ssl.keystore.password=<secret, not exported>",
+				`keyTab="C:\\keytabs\\producer.keytab" principal="svc-producer@CORP.EXAMPLE.COM"`,
+			},
+		},
+		{
+			name: "OAuth over plaintext",
+			config: &Config{
+				Brokers:           []string{"localhost:9092"},
+				SASLMechanism:     saslOAuthBearer,
+				OAuthTokenURL:     "https://auth.example.com/token",
+				OAuthClientID:     "producer",
+				OAuthClientSecret: "keyring:kafka/oauth",
+				OAuthScopes:       []string{"kafka"},
+				OAuthExtensions:   map[string]string{"logicalCluster": "lkc-1"},
+			},
+			want: []string{
+				"security.protocol=SASL_PLAINTEXT",
+				`clientId="producer" clientSecret="<secret>" scope="kafka" extension_logicalCluster="lkc-1";`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := writeClientProperties(&out, tt.config); err != nil {
+				t.Fatalf("writeClientProperties() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+			if strings.Contains(out.String(), "keyring:") {
+				t.Errorf("Expected no secret references in the output, got:\n%s", out.String())
+			}
+
+			props, err := parseProperties(&out)
+			if err != nil {
+				t.Fatalf("parseProperties() error = %v", err)
+			}
+			imported := &Config{}
+			importClientProperties(imported, props)
+
+			if !slices.Equal(imported.Brokers, tt.config.Brokers) ||
+				imported.CertFile != tt.config.CertFile || imported.CAFile != tt.config.CAFile ||
+				imported.SASLMechanism != tt.config.SASLMechanism ||
+				imported.KerberosKeytabPath != tt.config.KerberosKeytabPath ||
+				imported.OAuthClientID != tt.config.OAuthClientID ||
+				imported.OAuthTokenURL != tt.config.OAuthTokenURL {
+				t.Errorf("Expected the settings to survive a round trip, got %+v", imported)
+			}
+		})
+	}
+}
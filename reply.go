@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// ReplyWatch waits for the reply to one request on the reply topic. It is
+// started before the request is sent so that a fast reply cannot be missed.
+type ReplyWatch struct {
+	consumer *KafkaConsumer
+	topic    string
+	header   string
+	id       string
+	starts   map[int32]int64
+	sentAt   time.Time
+}
+
+// newCorrelationID returns a random UUID (version 4)
+func newCorrelationID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	id := hex.EncodeToString(b[:])
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
+
+// StartReplyWatch records the current end of the reply topic and picks a new
+// correlation ID. The watch takes ownership of consumer.
+func StartReplyWatch(consumer *KafkaConsumer, topic, header string) (*ReplyWatch, error) {
+	starts, err := consumer.HighWatermarks(topic)
+	if err != nil {
+		_ = consumer.Close()
+		return nil, err
+	}
+
+	return &ReplyWatch{
+		consumer: consumer,
+		topic:    topic,
+		header:   header,
+		id:       newCorrelationID(),
+		starts:   starts,
+		sentAt:   time.Now(),
+	}, nil
+}
+
+// CorrelationID returns the ID the reply must carry
+func (w *ReplyWatch) CorrelationID() string {
+	return w.id
+}
+
+// Header returns the correlation header to add to the request
+func (w *ReplyWatch) Header() MessageHeader {
+	return MessageHeader{Key: w.header, Value: w.id}
+}
+
+// Sent marks the moment the request was sent, the start of the round trip
+func (w *ReplyWatch) Sent() {
+	w.sentAt = time.Now()
+}
+
+// Wait returns the first record on the reply topic carrying the correlation
+// ID and the round-trip latency, or an error after timeout. It closes the
+// consumer.
+func (w *ReplyWatch) Wait(timeout time.Duration) (ConsumedMessage, time.Duration, error) {
+	defer func() { _ = w.consumer.Close() }()
+
+	var reply *ConsumedMessage
+	var latency time.Duration
+
+	stop := make(chan struct{})
+	timer := time.AfterFunc(max(timeout-time.Since(w.sentAt), 0), func() { close(stop) })
+	defer timer.Stop()
+
+	err := w.consumer.TailFrom(w.topic, w.starts, stop, func(msg ConsumedMessage) {
+		if reply != nil {
+			return
+		}
+		for _, header := range msg.Headers {
+			if header.Key == w.header && header.Value == w.id {
+				latency = time.Since(w.sentAt)
+				reply = &msg
+				if timer.Stop() {
+					close(stop)
+				}
+				return
+			}
+		}
+	})
+	if reply != nil {
+		return *reply, latency, nil
+	}
+	if err != nil {
+		return ConsumedMessage{}, 0, err
+	}
+	return ConsumedMessage{}, 0, fmt.Errorf("no reply with %s %s on %s within %s", w.header, w.id, w.topic, timeout)
+}
+
+// Close releases the consumer of a watch that will not be waited on, e.g.
+// because sending the request failed
+func (w *ReplyWatch) Close() error {
+	return w.consumer.Close()
+}
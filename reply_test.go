@@ -0,0 +1,109 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// replyClusterHandlers serves a single-partition "replies" topic whose
+// high watermark is 2
+func replyClusterHandlers(t *testing.T, broker *sarama.MockBroker) map[string]sarama.MockResponse {
+	handlers := mockClusterHandlers(t, broker, "replies")
+	handlers["OffsetRequest"] = sarama.NewMockOffsetResponse(t).
+		SetOffset("replies", 0, sarama.OffsetOldest, 0).
+		SetOffset("replies", 0, sarama.OffsetNewest, 2)
+	handlers["FetchRequest"] = sarama.NewMockFetchResponse(t, 1).SetHighWaterMark("replies", 0, 2)
+	return handlers
+}
+
+// replyFetchResponse returns offsets 2 and 3 of "replies", each carrying a
+// correlation-id header with the given value
+func replyFetchResponse(ids ...string) *sarama.FetchResponse {
+	// Version 10 is what the consumer requests with the default Kafka version
+	res := &sarama.FetchResponse{Version: 10}
+	for i, id := range ids {
+		res.AddRecordWithTimestamp("replies", 0, sarama.StringEncoder("k"), sarama.StringEncoder("reply-"+id), int64(i), time.Now())
+	}
+	block := res.GetBlock("replies", 0)
+	block.HighWaterMarkOffset = int64(2 + len(ids))
+	block.LastStableOffset = block.HighWaterMarkOffset
+	batch := block.RecordsSet[0].RecordBatch
+	batch.FirstOffset = 2
+	for i, id := range ids {
+		batch.Records[i].Headers = []*sarama.RecordHeader{{Key: []byte("correlation-id"), Value: []byte(id)}}
+	}
+	return res
+}
+
+func TestNewCorrelationID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, second := newCorrelationID(), newCorrelationID()
+	if !pattern.MatchString(first) {
+		t.Errorf("Expected a UUID, got %s", first)
+	}
+	if first == second {
+		t.Error("Expected different correlation IDs")
+	}
+}
+
+func TestReplyWatch_Wait(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	handlers := replyClusterHandlers(t, broker)
+	broker.SetHandlerByMap(handlers)
+
+	watch, err := StartReplyWatch(newTestConsumer(t, broker), "replies", "correlation-id")
+	if err != nil {
+		t.Fatalf("StartReplyWatch() error = %v", err)
+	}
+	if header := watch.Header(); header.Key != "correlation-id" || header.Value != watch.CorrelationID() {
+		t.Errorf("Expected correlation header with the watch ID, got %+v", header)
+	}
+
+	// A reply for another request arrives first
+	handlers["FetchRequest"] = sarama.NewMockWrapper(replyFetchResponse("other", watch.CorrelationID()))
+	broker.SetHandlerByMap(handlers)
+	watch.Sent()
+
+	reply, latency, err := watch.Wait(5 * time.Second)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if reply.Offset != 3 || string(reply.Value) != "reply-"+watch.CorrelationID() {
+		t.Errorf("Expected the matching reply at offset 3, got %d: %s", reply.Offset, reply.Value)
+	}
+	if latency <= 0 {
+		t.Errorf("Expected a positive latency, got %s", latency)
+	}
+}
+
+func TestReplyWatch_Timeout(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	broker.SetHandlerByMap(replyClusterHandlers(t, broker))
+
+	watch, err := StartReplyWatch(newTestConsumer(t, broker), "replies", "correlation-id")
+	if err != nil {
+		t.Fatalf("StartReplyWatch() error = %v", err)
+	}
+
+	_, _, err = watch.Wait(200 * time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "no reply with correlation-id "+watch.CorrelationID()+" on replies within 200ms") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+}
+
+func TestStartReplyWatch_UnknownTopic(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	broker.SetHandlerByMap(replyClusterHandlers(t, broker))
+
+	if _, err := StartReplyWatch(newTestConsumer(t, broker), "missing", "correlation-id"); err == nil {
+		t.Error("Expected error for unknown reply topic")
+	}
+}
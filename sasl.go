@@ -0,0 +1,176 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// SASL mechanisms supported in Config.SASLMechanism
+const (
+	saslOAuthBearer = sarama.SASLTypeOAuth
+	saslGSSAPI      = sarama.SASLTypeGSSAPI
+)
+
+var saslMechanisms = []string{saslOAuthBearer, saslGSSAPI}
+
+// Kerberos defaults
+const (
+	defaultKerberosConfigPath  = "/etc/krb5.conf"
+	defaultKerberosServiceName = "kafka"
+)
+
+// oauthTokenTimeout bounds a request to the OAuth token endpoint
+const oauthTokenTimeout = 10 * time.Second
+
+// saslMechanism returns the configured mechanism in canonical case
+func (c *Config) saslMechanism() string {
+	return strings.ToUpper(strings.TrimSpace(c.SASLMechanism))
+}
+
+// configureSASL enables the SASL mechanism of config in saramaConfig
+func configureSASL(saramaConfig *sarama.Config, config *Config) error {
+	switch mechanism := config.saslMechanism(); mechanism {
+	case "":
+		return nil
+	case saslOAuthBearer:
+		provider, err := newOAuthTokenProvider(config)
+		if err != nil {
+			return err
+		}
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+		saramaConfig.Net.SASL.TokenProvider = provider
+		return nil
+	case saslGSSAPI:
+		gssapi, err := newGSSAPIConfig(config)
+		if err != nil {
+			return err
+		}
+		saramaConfig.Net.SASL.Enable = true
+		saramaConfig.Net.SASL.Mechanism = sarama.SASLTypeGSSAPI
+		saramaConfig.Net.SASL.GSSAPI = gssapi
+		return nil
+	default:
+		return fmt.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+}
+
+// oauthTokenProvider supplies OAUTHBEARER tokens from the OAuth2
+// client-credentials flow. The token is cached and fetched again shortly
+// before it expires, and providers for the same client share their token
+// source, so the producer, admin and consumers of a session and their
+// reconnecting brokers share one token.
+type oauthTokenProvider struct {
+	source     oauth2.TokenSource
+	extensions map[string]string
+}
+
+// oauthClient identifies the token requests of a config. The secret is kept
+// as configured, usually a reference.
+type oauthClient struct {
+	tokenURL, clientID, secret, scopes, audience string
+}
+
+// oauthTokenSources holds one token source per OAuth client and process
+var oauthTokenSources = struct {
+	sync.Mutex
+	sources map[oauthClient]oauth2.TokenSource
+}{sources: make(map[oauthClient]oauth2.TokenSource)}
+
+func newOAuthTokenProvider(config *Config) (*oauthTokenProvider, error) {
+	client := oauthClient{
+		tokenURL: config.OAuthTokenURL,
+		clientID: config.OAuthClientID,
+		secret:   config.OAuthClientSecret,
+		scopes:   strings.Join(config.OAuthScopes, " "),
+		audience: config.OAuthAudience,
+	}
+
+	oauthTokenSources.Lock()
+	defer oauthTokenSources.Unlock()
+	if source, ok := oauthTokenSources.sources[client]; ok {
+		return &oauthTokenProvider{source: source, extensions: config.OAuthExtensions}, nil
+	}
+
+	secret, err := resolveSecret(config.OAuthClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve OAuth client secret: %w", err)
+	}
+
+	credentials := &clientcredentials.Config{
+		ClientID:     config.OAuthClientID,
+		ClientSecret: secret,
+		TokenURL:     config.OAuthTokenURL,
+		Scopes:       config.OAuthScopes,
+	}
+	if config.OAuthAudience != "" {
+		credentials.EndpointParams = url.Values{"audience": {config.OAuthAudience}}
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: oauthTokenTimeout})
+	source := credentials.TokenSource(ctx)
+	oauthTokenSources.sources[client] = source
+	return &oauthTokenProvider{source: source, extensions: config.OAuthExtensions}, nil
+}
+
+// Token implements sarama.AccessTokenProvider
+func (p *oauthTokenProvider) Token() (*sarama.AccessToken, error) {
+	token, err := p.source.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OAuth token: %w", err)
+	}
+	return &sarama.AccessToken{Token: token.AccessToken, Extensions: p.extensions}, nil
+}
+
+// kerberosPrincipal returns the Kerberos user and realm. The realm may be
+// given as part of the username, as in "svc-producer@CORP.EXAMPLE.COM".
+func (c *Config) kerberosPrincipal() (username, realm string) {
+	username = strings.TrimSpace(c.KerberosUsername)
+	if user, userRealm, ok := strings.Cut(username, "@"); ok {
+		return user, cmp.Or(strings.TrimSpace(c.KerberosRealm), userRealm)
+	}
+	return username, strings.TrimSpace(c.KerberosRealm)
+}
+
+// kerberosConfigPath returns the krb5.conf used for GSSAPI
+func (c *Config) kerberosConfigPath() string {
+	return cmp.Or(c.KerberosConfigPath, os.Getenv("KRB5_CONFIG"), defaultKerberosConfigPath)
+}
+
+// newGSSAPIConfig builds the sarama Kerberos settings, logging in with the
+// keytab when one is set and with the password otherwise
+func newGSSAPIConfig(config *Config) (sarama.GSSAPIConfig, error) {
+	username, realm := config.kerberosPrincipal()
+	gssapi := sarama.GSSAPIConfig{
+		KerberosConfigPath: config.kerberosConfigPath(),
+		ServiceName:        cmp.Or(config.KerberosServiceName, defaultKerberosServiceName),
+		Username:           username,
+		Realm:              realm,
+		DisablePAFXFAST:    config.KerberosDisablePAFXFAST,
+	}
+
+	if config.KerberosKeytabPath != "" {
+		gssapi.AuthType = sarama.KRB5_KEYTAB_AUTH
+		gssapi.KeyTabPath = config.KerberosKeytabPath
+		return gssapi, nil
+	}
+
+	password, err := resolveSecret(config.KerberosPassword)
+	if err != nil {
+		return gssapi, fmt.Errorf("failed to resolve Kerberos password: %w", err)
+	}
+	gssapi.AuthType = sarama.KRB5_USER_AUTH
+	gssapi.Password = password
+	return gssapi, nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// newFakeTokenServer serves the client-credentials flow for client "producer"
+// with secret "s3cret", issuing tokens valid for expiresIn seconds
+func newFakeTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		clientID, secret, ok := r.BasicAuth()
+		if !ok {
+			clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || clientID != "producer" || secret != "s3cret" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "token-" + string(rune('0'+n)) + "-" + r.PostForm.Get("scope") + "-" + r.PostForm.Get("audience"),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestOAuthTokenProvider_CachesToken(t *testing.T) {
+	server, requests := newFakeTokenServer(t, 3600)
+	t.Setenv("KAFKA_TEST_OAUTH_SECRET", "s3cret")
+
+	provider, err := newOAuthTokenProvider(&Config{
+		OAuthTokenURL:     server.URL,
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "env:KAFKA_TEST_OAUTH_SECRET",
+		OAuthScopes:       []string{"kafka"},
+		OAuthAudience:     "cluster-1",
+		OAuthExtensions:   map[string]string{"logicalCluster": "lkc-1"},
+	})
+	if err != nil {
+		t.Fatalf("newOAuthTokenProvider() error = %v", err)
+	}
+
+	for range 3 {
+		token, err := provider.Token()
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token.Token != "token-1-kafka-cluster-1" {
+			t.Errorf("Expected 'token-1-kafka-cluster-1', got %q", token.Token)
+		}
+		if token.Extensions["logicalCluster"] != "lkc-1" {
+			t.Errorf("Expected the logicalCluster extension, got %v", token.Extensions)
+		}
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 token request, got %d", got)
+	}
+}
+
+func TestOAuthTokenProvider_SharedBetweenClients(t *testing.T) {
+	server, requests := newFakeTokenServer(t, 3600)
+	config := &Config{
+		SASLMechanism:     saslOAuthBearer,
+		OAuthTokenURL:     server.URL,
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "s3cret",
+	}
+
+	// The producer, admin and consumers each build their own sarama config
+	for range 3 {
+		saramaConfig, err := newSaramaConfig(config)
+		if err != nil {
+			t.Fatalf("newSaramaConfig() error = %v", err)
+		}
+		if _, err := saramaConfig.Net.SASL.TokenProvider.Token(); err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 token request, got %d", got)
+	}
+
+	other := *config
+	other.OAuthScopes = []string{"kafka"}
+	provider, err := newOAuthTokenProvider(&other)
+	if err != nil {
+		t.Fatalf("newOAuthTokenProvider() error = %v", err)
+	}
+	if _, err := provider.Token(); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected a separate token for other scopes, got %d request(s)", got)
+	}
+}
+
+func TestOAuthTokenProvider_RefreshesExpiredToken(t *testing.T) {
+	// Tokens within the refresh margin of their expiry count as expired
+	server, requests := newFakeTokenServer(t, 1)
+
+	provider, err := newOAuthTokenProvider(&Config{
+		OAuthTokenURL:     server.URL,
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("newOAuthTokenProvider() error = %v", err)
+	}
+
+	first, err := provider.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	second, err := provider.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if first.Token == second.Token {
+		t.Errorf("Expected a new token after expiry, got %q twice", first.Token)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 token requests, got %d", got)
+	}
+}
+
+func TestOAuthTokenProvider_Errors(t *testing.T) {
+	server, _ := newFakeTokenServer(t, 3600)
+
+	provider, err := newOAuthTokenProvider(&Config{
+		OAuthTokenURL:     server.URL,
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "wrong",
+	})
+	if err != nil {
+		t.Fatalf("newOAuthTokenProvider() error = %v", err)
+	}
+	if _, err := provider.Token(); err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("Expected invalid_client error, got %v", err)
+	}
+
+	_, err = newOAuthTokenProvider(&Config{OAuthClientSecret: "env:KAFKA_TEST_MISSING_SECRET"})
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve OAuth client secret") {
+		t.Errorf("Expected secret resolution error, got %v", err)
+	}
+}
+
+func TestNewSaramaConfig_SASL(t *testing.T) {
+	saramaConfig, err := newSaramaConfig(&Config{
+		SASLMechanism:     "oauthbearer",
+		OAuthTokenURL:     "https://auth.example.com/token",
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "s3cret",
+	})
+	if err != nil {
+		t.Fatalf("newSaramaConfig() error = %v", err)
+	}
+
+	if !saramaConfig.Net.SASL.Enable {
+		t.Error("Expected SASL to be enabled")
+	}
+	if saramaConfig.Net.SASL.Mechanism != sarama.SASLTypeOAuth {
+		t.Errorf("Expected mechanism %s, got %s", sarama.SASLTypeOAuth, saramaConfig.Net.SASL.Mechanism)
+	}
+	if _, ok := saramaConfig.Net.SASL.TokenProvider.(*oauthTokenProvider); !ok {
+		t.Errorf("Expected the OAuth token provider, got %T", saramaConfig.Net.SASL.TokenProvider)
+	}
+
+	if _, err := newSaramaConfig(&Config{SASLMechanism: "NTLM"}); err == nil || !strings.Contains(err.Error(), "unsupported SASL mechanism") {
+		t.Errorf("Expected unsupported mechanism error, got %v", err)
+	}
+}
+
+func TestNewSaramaConfig_GSSAPI(t *testing.T) {
+	t.Setenv("KAFKA_TEST_KERBEROS_PASSWORD", "s3cret")
+
+	tests := []struct {
+		name     string
+		config   *Config
+		authType int
+		password string
+	}{
+		{
+			name:     "keytab",
+			config:   &Config{KerberosUsername: "svc-producer@CORP.EXAMPLE.COM", KerberosKeytabPath: "/etc/security/producer.keytab"},
+			authType: sarama.KRB5_KEYTAB_AUTH,
+		},
+		{
+			name:     "password",
+			config:   &Config{KerberosUsername: "alice", KerberosRealm: "CORP.EXAMPLE.COM", KerberosPassword: "env:KAFKA_TEST_KERBEROS_PASSWORD"},
+			authType: sarama.KRB5_USER_AUTH,
+			password: "s3cret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("KRB5_CONFIG", "")
+			tt.config.SASLMechanism = "GSSAPI"
+			saramaConfig, err := newSaramaConfig(tt.config)
+			if err != nil {
+				t.Fatalf("newSaramaConfig() error = %v", err)
+			}
+
+			gssapi := saramaConfig.Net.SASL.GSSAPI
+			if !saramaConfig.Net.SASL.Enable || saramaConfig.Net.SASL.Mechanism != sarama.SASLTypeGSSAPI {
+				t.Errorf("Expected SASL/GSSAPI, got enabled=%v mechanism=%s", saramaConfig.Net.SASL.Enable, saramaConfig.Net.SASL.Mechanism)
+			}
+			if gssapi.AuthType != tt.authType {
+				t.Errorf("Expected auth type %d, got %d", tt.authType, gssapi.AuthType)
+			}
+			if gssapi.Password != tt.password {
+				t.Errorf("Expected password %q, got %q", tt.password, gssapi.Password)
+			}
+			if gssapi.Realm != "CORP.EXAMPLE.COM" {
+				t.Errorf("Expected realm CORP.EXAMPLE.COM, got %q", gssapi.Realm)
+			}
+			if gssapi.ServiceName != defaultKerberosServiceName || gssapi.KerberosConfigPath != defaultKerberosConfigPath {
+				t.Errorf("Expected default service and krb5.conf, got %q and %q", gssapi.ServiceName, gssapi.KerberosConfigPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario step outcomes
+const (
+	stepPassed  = "passed"
+	stepFailed  = "failed"
+	stepSkipped = "skipped"
+)
+
+// defaultExpectTimeout is how long an expect step waits for a record
+const defaultExpectTimeout = 10 * time.Second
+
+// Scenario is a declarative sequence of steps read from a YAML file, e.g.
+//
+//	name: order flow
+//	vars:
+//	  order_id: "{{uuid}}"
+//	steps:
+//	  - send:
+//	      key: "{{order_id}}"
+//	      value: {type: order-created}
+//	  - sleep: 2s
+//	  - expect:
+//	      topic: payments
+//	      filter: key == "{{order_id}}"
+//	      save: {payment_id: $.id}
+//
+// Strings may refer to variables as {{name}}; the built-ins {{uuid}},
+// {{now}} and {{unix_ms}} are evaluated on every use.
+type Scenario struct {
+	Name  string            `yaml:"name"`
+	Topic string            `yaml:"topic"`
+	Vars  map[string]string `yaml:"vars"`
+	Steps []ScenarioStep    `yaml:"steps"`
+}
+
+// ScenarioStep is one step of a scenario; exactly one action is set
+type ScenarioStep struct {
+	Name   string            `yaml:"name"`
+	Send   *SendStep         `yaml:"send"`
+	Sleep  string            `yaml:"sleep"`
+	Expect *ExpectStep       `yaml:"expect"`
+	Set    map[string]string `yaml:"set"`
+}
+
+// SendStep sends a message. A Value that is not a string is sent as JSON.
+type SendStep struct {
+	Topic     string            `yaml:"topic"`
+	Key       string            `yaml:"key"`
+	Value     any               `yaml:"value"`
+	Headers   map[string]string `yaml:"headers"`
+	Partition *int32            `yaml:"partition"`
+}
+
+// ExpectStep waits for a record matching Filter on Topic that was written
+// after the scenario started. Save copies fields of the record (key, value,
+// header.NAME, $.path) into variables.
+type ExpectStep struct {
+	Topic   string            `yaml:"topic"`
+	Filter  string            `yaml:"filter"`
+	Timeout string            `yaml:"timeout"`
+	Save    map[string]string `yaml:"save"`
+}
+
+// StepResult is the outcome of one step of a scenario run
+type StepResult struct {
+	Index    int
+	Name     string
+	Status   string
+	Duration time.Duration
+	Detail   string
+	Err      error
+}
+
+// LoadScenario reads and checks a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := ParseScenario(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = path
+	}
+	return scenario, nil
+}
+
+// ParseScenario decodes a YAML scenario and checks every step
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, err
+	}
+	if len(scenario.Steps) == 0 {
+		return nil, fmt.Errorf("scenario has no steps")
+	}
+
+	for i, step := range scenario.Steps {
+		if err := step.check(); err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return &scenario, nil
+}
+
+func (s ScenarioStep) check() error {
+	actions := 0
+	for _, set := range []bool{s.Send != nil, s.Sleep != "", s.Expect != nil, s.Set != nil} {
+		if set {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("expected exactly one of send, sleep, expect or set")
+	}
+
+	switch {
+	case s.Send != nil:
+		if s.Send.Value == nil {
+			return fmt.Errorf("send needs a value")
+		}
+	case s.Sleep != "":
+		if _, err := time.ParseDuration(s.Sleep); err != nil {
+			return fmt.Errorf("invalid sleep: %w", err)
+		}
+	case s.Expect != nil:
+		if s.Expect.Topic == "" {
+			return fmt.Errorf("expect needs a topic")
+		}
+		if s.Expect.Timeout != "" {
+			if _, err := time.ParseDuration(s.Expect.Timeout); err != nil {
+				return fmt.Errorf("invalid timeout: %w", err)
+			}
+		}
+		for name, field := range s.Expect.Save {
+			if _, ok := parseFilterField(field); !ok {
+				return fmt.Errorf("cannot save %q into %s: unknown field", field, name)
+			}
+		}
+	}
+	return nil
+}
+
+// title describes the step in reports
+func (s ScenarioStep) title(defaultTopic string) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	switch {
+	case s.Send != nil:
+		return "send to " + cmp.Or(s.Send.Topic, defaultTopic)
+	case s.Sleep != "":
+		return "sleep " + s.Sleep
+	case s.Expect != nil:
+		return "expect on " + s.Expect.Topic
+	}
+	return "set " + strings.Join(slices.Sorted(maps.Keys(s.Set)), ", ")
+}
+
+// templateVariable matches {{name}} references in scenarios and fan-out
+// value templates
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ScenarioRun executes a scenario against the cluster. Records that an
+// expect step matched are not matched again by later steps.
+type ScenarioRun struct {
+	scenario *Scenario
+	producer *KafkaProducer
+	consumer *KafkaConsumer
+	topic    string
+	vars     map[string]string
+	starts   map[string]map[int32]int64
+	matched  map[string]bool
+}
+
+// NewScenarioRun prepares a run, recording the end of every topic the
+// scenario expects records on so that only new records match
+func NewScenarioRun(scenario *Scenario, producer *KafkaProducer, consumer *KafkaConsumer, topic string) (*ScenarioRun, error) {
+	run := &ScenarioRun{
+		scenario: scenario,
+		producer: producer,
+		consumer: consumer,
+		topic:    cmp.Or(scenario.Topic, topic),
+		vars:     make(map[string]string),
+		starts:   make(map[string]map[int32]int64),
+		matched:  make(map[string]bool),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(scenario.Vars)) {
+		value, err := run.expand(scenario.Vars[name])
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+		run.vars[name] = value
+	}
+
+	for _, step := range scenario.Steps {
+		if step.Expect == nil || run.starts[step.Expect.Topic] != nil {
+			continue
+		}
+		starts, err := consumer.HighWatermarks(step.Expect.Topic)
+		if err != nil {
+			return nil, err
+		}
+		run.starts[step.Expect.Topic] = starts
+	}
+
+	return run, nil
+}
+
+// Run executes the steps in order and passes each result to report. After a
+// failure, or once stop is closed, the remaining steps are skipped.
+func (r *ScenarioRun) Run(stop <-chan struct{}, report func(StepResult)) []StepResult {
+	var results []StepResult
+	failed := false
+
+	for i, step := range r.scenario.Steps {
+		result := StepResult{Index: i, Name: step.title(r.topic), Status: stepSkipped}
+
+		select {
+		case <-stop:
+			failed = true
+		default:
+		}
+
+		if !failed {
+			start := time.Now()
+			result.Detail, result.Err = r.runStep(step, stop)
+			result.Duration = time.Since(start)
+			result.Status = stepPassed
+			if result.Err != nil {
+				result.Status = stepFailed
+				failed = true
+			}
+		}
+
+		results = append(results, result)
+		if report != nil {
+			report(result)
+		}
+	}
+
+	return results
+}
+
+// Vars returns the variables set so far
+func (r *ScenarioRun) Vars() map[string]string {
+	return maps.Clone(r.vars)
+}
+
+func (r *ScenarioRun) runStep(step ScenarioStep, stop <-chan struct{}) (string, error) {
+	switch {
+	case step.Send != nil:
+		return r.send(step.Send)
+	case step.Sleep != "":
+		duration, _ := time.ParseDuration(step.Sleep)
+		timer := time.NewTimer(duration)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return "", nil
+		case <-stop:
+			return "", fmt.Errorf("interrupted")
+		}
+	case step.Expect != nil:
+		return r.expect(step.Expect, stop)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(step.Set)) {
+		value, err := r.expand(step.Set[name])
+		if err != nil {
+			return "", err
+		}
+		r.vars[name] = value
+	}
+	return "", nil
+}
+
+func (r *ScenarioRun) send(step *SendStep) (string, error) {
+	msg := OutgoingMessage{Topic: cmp.Or(step.Topic, r.topic), Partition: -1}
+	if step.Partition != nil {
+		msg.Partition = *step.Partition
+	}
+
+	if step.Key != "" {
+		key, err := r.expand(step.Key)
+		if err != nil {
+			return "", err
+		}
+		msg.Key = []byte(key)
+	}
+
+	value, err := r.expandValue(step.Value)
+	if err != nil {
+		return "", err
+	}
+	if text, ok := value.(string); ok {
+		msg.Value = []byte(text)
+	} else if msg.Value, err = json.Marshal(value); err != nil {
+		return "", fmt.Errorf("value cannot be sent as JSON: %w", err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(step.Headers)) {
+		header, err := r.expand(step.Headers[name])
+		if err != nil {
+			return "", err
+		}
+		msg.Headers = append(msg.Headers, MessageHeader{Key: name, Value: header})
+	}
+
+	partition, offset, err := r.producer.Send(msg)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%d@%d", msg.Topic, partition, offset), nil
+}
+
+func (r *ScenarioRun) expect(step *ExpectStep, stop <-chan struct{}) (string, error) {
+	text, err := r.expand(step.Filter)
+	if err != nil {
+		return "", err
+	}
+	filter, err := ParseFilter(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid filter: %w", err)
+	}
+	timeout := defaultExpectTimeout
+	if step.Timeout != "" {
+		timeout, _ = time.ParseDuration(step.Timeout)
+	}
+
+	var found *ConsumedMessage
+	done := make(chan struct{})
+	var once sync.Once
+	finish := func() { once.Do(func() { close(done) }) }
+
+	timer := time.AfterFunc(timeout, finish)
+	defer timer.Stop()
+	go func() {
+		select {
+		case <-stop:
+			finish()
+		case <-done:
+		}
+	}()
+	defer finish()
+
+	err = r.consumer.TailFrom(step.Topic, r.starts[step.Topic], done, func(msg ConsumedMessage) {
+		id := fmt.Sprintf("%s/%d@%d", msg.Topic, msg.Partition, msg.Offset)
+		if found != nil || r.matched[id] || !filter.Match(msg) {
+			return
+		}
+		r.matched[id] = true
+		found = &msg
+		finish()
+	})
+	if found == nil {
+		if err != nil {
+			return "", err
+		}
+		select {
+		case <-stop:
+			return "", fmt.Errorf("interrupted")
+		default:
+		}
+		return "", fmt.Errorf("no record matching %q on %s within %s", filter, step.Topic, timeout)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(step.Save)) {
+		field, _ := parseFilterField(step.Save[name])
+		value, ok := field.resolve(&filterContext{msg: found})
+		if !ok {
+			return "", fmt.Errorf("matched %s/%d@%d has no %s to save into %s", found.Topic, found.Partition, found.Offset, step.Save[name], name)
+		}
+		r.vars[name] = value
+	}
+	return fmt.Sprintf("matched %s/%d@%d", found.Topic, found.Partition, found.Offset), nil
+}
+
+// expand replaces {{name}} references in text
+func (r *ScenarioRun) expand(text string) (string, error) {
+	return expandVariables(text, r.vars)
+}
+
+// expandVariables replaces {{name}} references in text with vars or the
+// built-ins uuid, now and unix_ms
+func expandVariables(text string, vars map[string]string) (string, error) {
+	var err error
+	expanded := templateVariable.ReplaceAllStringFunc(text, func(ref string) string {
+		name := templateVariable.FindStringSubmatch(ref)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		switch name {
+		case "uuid":
+			return newCorrelationID()
+		case "now":
+			return time.Now().UTC().Format(time.RFC3339Nano)
+		case "unix_ms":
+			return strconv.FormatInt(time.Now().UnixMilli(), 10)
+		}
+		if err == nil {
+			err = fmt.Errorf("undefined variable %q", name)
+		}
+		return ref
+	})
+	return expanded, err
+}
+
+// expandValue expands variables in every string of a decoded YAML value
+func (r *ScenarioRun) expandValue(value any) (any, error) {
+	switch value := value.(type) {
+	case string:
+		return r.expand(value)
+	case map[string]any:
+		expanded := make(map[string]any, len(value))
+		for key, item := range value {
+			var err error
+			if expanded[key], err = r.expandValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	case []any:
+		expanded := make([]any, len(value))
+		for i, item := range value {
+			var err error
+			if expanded[i], err = r.expandValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return expanded, nil
+	}
+	return value, nil
+}
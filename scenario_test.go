@@ -0,0 +1,201 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+func TestParseScenario(t *testing.T) {
+	scenario, err := ParseScenario([]byte(`
+name: order flow
+vars:
+  order_id: "{{uuid}}"
+steps:
+  - name: create order
+    send:
+      key: "{{order_id}}"
+      value: {type: order-created, items: [1, 2]}
+  - sleep: 2s
+  - expect:
+      topic: payments
+      filter: key == "{{order_id}}"
+      timeout: 5s
+      save: {payment_id: $.id}
+  - set: {status: paid}
+`))
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+	if scenario.Name != "order flow" || len(scenario.Steps) != 4 {
+		t.Fatalf("Expected 4 steps of order flow, got %+v", scenario)
+	}
+
+	titles := []string{"create order", "sleep 2s", "expect on payments", "set status"}
+	for i, step := range scenario.Steps {
+		if got := step.title("orders"); got != titles[i] {
+			t.Errorf("Expected step %d to be %q, got %q", i+1, titles[i], got)
+		}
+	}
+}
+
+func TestParseScenario_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{name: "no steps", yaml: "name: empty", want: "no steps"},
+		{name: "two actions", yaml: "steps: [{sleep: 1s, set: {a: b}}]", want: "step 1: expected exactly one"},
+		{name: "no action", yaml: "steps: [{name: nothing}]", want: "step 1: expected exactly one"},
+		{name: "unknown field", yaml: "steps: [{sleeep: 1s}]", want: "field sleeep not found"},
+		{name: "invalid sleep", yaml: "steps: [{sleep: soon}]", want: "invalid sleep"},
+		{name: "send without value", yaml: "steps: [{send: {key: k}}]", want: "send needs a value"},
+		{name: "expect without topic", yaml: "steps: [{expect: {filter: key}}]", want: "expect needs a topic"},
+		{name: "invalid timeout", yaml: "steps: [{expect: {topic: t, timeout: 5}}]", want: "invalid timeout"},
+		{name: "invalid save", yaml: "steps: [{expect: {topic: t, save: {id: body}}}]", want: `cannot save "body" into id`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScenario([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestScenarioRun_Expand(t *testing.T) {
+	run := &ScenarioRun{vars: map[string]string{"id": "42"}}
+
+	got, err := run.expand(`order-{{id}}-{{ id }}`)
+	if err != nil || got != "order-42-42" {
+		t.Errorf("Expected order-42-42, got %q (%v)", got, err)
+	}
+	if got, _ := run.expand("{{uuid}}"); len(got) != 36 {
+		t.Errorf("Expected a UUID, got %q", got)
+	}
+	if _, err := run.expand("{{missing}}"); err == nil || !strings.Contains(err.Error(), `undefined variable "missing"`) {
+		t.Errorf("Expected undefined variable error, got %v", err)
+	}
+
+	value, err := run.expandValue(map[string]any{"id": "{{id}}", "items": []any{"{{id}}", 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := value.(map[string]any)["items"].([]any)
+	if value.(map[string]any)["id"] != "42" || items[0] != "42" || items[1] != 1 {
+		t.Errorf("Expected variables expanded in nested values, got %v", value)
+	}
+}
+
+func TestScenarioRun(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	t.Cleanup(broker.Close)
+	handlers := replyClusterHandlers(t, broker)
+	broker.SetHandlerByMap(handlers)
+
+	var sent []*sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{
+			sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+				sent = append(sent, msg)
+				return 1, 7, nil
+			},
+		},
+		config: &Config{},
+	}
+
+	scenario, err := ParseScenario([]byte(`
+vars:
+  id: order-1
+steps:
+  - send:
+      key: "{{id}}"
+      value: {id: "{{id}}", total: 100}
+      headers: {source: scenario}
+  - set: {want: b}
+  - expect:
+      topic: replies
+      filter: header.correlation-id == "{{want}}"
+      save: {reply: value}
+  - expect:
+      topic: replies
+      filter: value =~ "^reply-"
+  - expect:
+      topic: replies
+      filter: value == "{{reply}}"
+      timeout: 200ms
+  - sleep: 1ms
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := NewScenarioRun(scenario, producer, newTestConsumer(t, broker), "orders")
+	if err != nil {
+		t.Fatalf("NewScenarioRun() error = %v", err)
+	}
+	handlers["FetchRequest"] = sarama.NewMockWrapper(replyFetchResponse("a", "b"))
+	broker.SetHandlerByMap(handlers)
+
+	var reported int
+	results := run.Run(make(chan struct{}), func(StepResult) { reported++ })
+	if reported != len(results) || len(results) != 6 {
+		t.Fatalf("Expected 6 reported results, got %d of %d", reported, len(results))
+	}
+
+	want := []struct{ status, detail string }{
+		{stepPassed, "orders/1@7"},
+		{stepPassed, ""},
+		{stepPassed, "matched replies/0@3"},
+		// The record matched by the previous step is not matched again
+		{stepPassed, "matched replies/0@2"},
+		{stepFailed, ""},
+		{stepSkipped, ""},
+	}
+	for i, result := range results {
+		if result.Status != want[i].status || result.Detail != want[i].detail {
+			t.Errorf("Expected step %d to be %s %q, got %s %q (%v)", i+1, want[i].status, want[i].detail, result.Status, result.Detail, result.Err)
+		}
+	}
+	if err := results[4].Err; err == nil || !strings.Contains(err.Error(), `no record matching "value == \"reply-b\"" on replies within 200ms`) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("Expected one message, got %d", len(sent))
+	}
+	key, _ := sent[0].Key.Encode()
+	value, _ := sent[0].Value.Encode()
+	if sent[0].Topic != "orders" || string(key) != "order-1" || string(value) != `{"id":"order-1","total":100}` {
+		t.Errorf("Expected the expanded order on orders, got %s %s %s", sent[0].Topic, key, value)
+	}
+	if len(sent[0].Headers) != 1 || string(sent[0].Headers[0].Value) != "scenario" {
+		t.Errorf("Expected the source header, got %+v", sent[0].Headers)
+	}
+	if vars := run.Vars(); vars["reply"] != "reply-b" || vars["want"] != "b" {
+		t.Errorf("Expected saved and set variables, got %v", vars)
+	}
+}
+
+func TestScenarioRun_Stop(t *testing.T) {
+	scenario, err := ParseScenario([]byte("steps: [{sleep: 1h}, {set: {a: b}}]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := NewScenarioRun(scenario, nil, nil, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+	results := run.Run(stop, nil)
+	if results[0].Status != stepFailed || results[1].Status != stepSkipped {
+		t.Errorf("Expected the sleep to be interrupted and the rest skipped, got %+v", results)
+	}
+}
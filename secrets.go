@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Prefixes of secret references. A secret field holding a reference is
+// resolved when connecting and saved as is; any other value is a literal
+// that SaveConfig never writes to disk.
+const (
+	secretEnvPrefix     = "env:"
+	secretFilePrefix    = "file:"
+	secretCmdPrefix     = "cmd:"
+	secretKeyringPrefix = "keyring:"
+)
+
+var secretPrefixes = []string{secretEnvPrefix, secretFilePrefix, secretCmdPrefix, secretKeyringPrefix}
+
+// secretCommandTimeout bounds cmd: references, leaving time for pinentry
+// or a hardware token prompt
+const secretCommandTimeout = 30 * time.Second
+
+// secretCache keeps the values of cmd: and keyring: references so that the
+// command or keyring prompt runs once per reference and process
+var secretCache = struct {
+	sync.Mutex
+	values map[string]string
+}{values: make(map[string]string)}
+
+// isSecretRef reports whether value is a reference rather than a secret
+func isSecretRef(value string) bool {
+	for _, prefix := range secretPrefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSecret returns the secret a field value stands for:
+//
+//	env:VAR                 environment variable VAR
+//	file:/path              contents of the file without the trailing newline
+//	cmd:pass show kafka     standard output of the shell command, trimmed
+//	keyring:service/user    entry of the OS keyring
+//
+// Values without a known prefix are returned unchanged.
+func resolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, secretEnvPrefix):
+		name := strings.TrimPrefix(value, secretEnvPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+
+	case strings.HasPrefix(value, secretFilePrefix):
+		data, err := os.ReadFile(strings.TrimPrefix(value, secretFilePrefix))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(value, secretCmdPrefix), strings.HasPrefix(value, secretKeyringPrefix):
+		secretCache.Lock()
+		defer secretCache.Unlock()
+		if secret, ok := secretCache.values[value]; ok {
+			return secret, nil
+		}
+
+		var secret string
+		var err error
+		if command, ok := strings.CutPrefix(value, secretCmdPrefix); ok {
+			secret, err = runSecretCommand(command)
+		} else {
+			secret, err = readKeyring(strings.TrimPrefix(value, secretKeyringPrefix))
+		}
+		if err != nil {
+			return "", err
+		}
+		secretCache.values[value] = secret
+		return secret, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// resolvedOnConnect reports whether value is a cmd: or keyring: reference.
+// Resolving those may prompt for a PIN or touch, so it waits for a connect.
+func resolvedOnConnect(value string) bool {
+	return strings.HasPrefix(value, secretCmdPrefix) || strings.HasPrefix(value, secretKeyringPrefix)
+}
+
+// checkSecretRef checks a reference without running commands or keyring
+// lookups: the environment variable is set, the file is readable, the
+// command is not empty and the keyring entry is service/user
+func checkSecretRef(value string) error {
+	switch {
+	case strings.HasPrefix(value, secretCmdPrefix):
+		if strings.TrimSpace(strings.TrimPrefix(value, secretCmdPrefix)) == "" {
+			return errors.New("empty command")
+		}
+		return nil
+	case strings.HasPrefix(value, secretKeyringPrefix):
+		_, _, err := splitKeyringEntry(strings.TrimPrefix(value, secretKeyringPrefix))
+		return err
+	default:
+		_, err := resolveSecret(value)
+		return err
+	}
+}
+
+// runSecretCommand runs command in the platform shell and returns its output
+func runSecretCommand(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("empty command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command) // #nosec G204 -- the command comes from the user's own config
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("command %q failed: %w: %s", command, err, message)
+		}
+		return "", fmt.Errorf("command %q failed: %w", command, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// readKeyring reads a "service/user" entry of the OS keyring: the macOS
+// Keychain, the Secret Service on Linux or the Windows Credential Manager
+func readKeyring(entry string) (string, error) {
+	service, user, err := splitKeyringEntry(entry)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := keyring.Get(service, user)
+	if err != nil {
+		return "", fmt.Errorf("keyring entry %s: %w", entry, err)
+	}
+	return secret, nil
+}
+
+// splitKeyringEntry splits a "service/user" keyring entry at the last slash
+func splitKeyringEntry(entry string) (service, user string, err error) {
+	i := strings.LastIndex(entry, "/")
+	if i <= 0 || i == len(entry)-1 {
+		return "", "", fmt.Errorf("keyring entry %q must be service/user", entry)
+	}
+	return entry[:i], entry[i+1:], nil
+}
+
+// secretFields returns the Config fields that hold secrets or references,
+// keyed by their names in validation results
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		fieldKeyPassword:       &c.KeyPassword,
+		fieldOAuthClientSecret: &c.OAuthClientSecret,
+		fieldKerberosPassword:  &c.KerberosPassword,
+	}
+}
+
+// plainSecrets returns the names of the secret fields that hold a literal
+// secret, which SaveConfig drops
+func (c *Config) plainSecrets() []string {
+	var names []string
+	for name, field := range c.secretFields() {
+		if *field != "" && !isSecretRef(*field) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// withoutSecrets returns a copy of c in which secret fields keep only
+// references, so that literal secrets never reach the config file
+func (c *Config) withoutSecrets() *Config {
+	clean := *c
+	for _, field := range clean.secretFields() {
+		if !isSecretRef(*field) {
+			*field = ""
+		}
+	}
+	return &clean
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestResolveSecret(t *testing.T) {
+	keyring.MockInit()
+	if err := keyring.Set("kafka/prod", "producer", "from-keyring"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KAFKA_TEST_SECRET", "from-env")
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		err   string
+	}{
+		{name: "literal", value: "s3cret", want: "s3cret"},
+		{name: "env", value: "env:KAFKA_TEST_SECRET", want: "from-env"},
+		{name: "file", value: "file:" + secretFile, want: "from-file"},
+		{name: "cmd", value: "cmd:echo from-cmd", want: "from-cmd"},
+		{name: "keyring", value: "keyring:kafka/prod/producer", want: "from-keyring"},
+		{name: "missing env", value: "env:KAFKA_TEST_MISSING", err: "is not set"},
+		{name: "missing file", value: "file:" + filepath.Join(t.TempDir(), "missing"), err: "no such file"},
+		{name: "failing cmd", value: "cmd:echo denied >&2; exit 3", err: "denied"},
+		{name: "empty cmd", value: "cmd:", err: "empty command"},
+		{name: "missing keyring entry", value: "keyring:kafka/prod/other", err: "not found"},
+		{name: "keyring without user", value: "keyring:kafka", err: "must be service/user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && strings.HasPrefix(tt.value, secretCmdPrefix) {
+				t.Skip("shell syntax differs on Windows")
+			}
+			got, err := resolveSecret(tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveSecret() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveSecret_CachesCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell syntax differs on Windows")
+	}
+	counter := filepath.Join(t.TempDir(), "runs")
+	ref := "cmd:echo run >> " + counter + "; echo cached"
+
+	for range 2 {
+		if got, err := resolveSecret(ref); err != nil || got != "cached" {
+			t.Fatalf("Expected 'cached', got %q, %v", got, err)
+		}
+	}
+
+	data, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(data), "run"); runs != 1 {
+		t.Errorf("Expected the command to run once, ran %d times", runs)
+	}
+}
+
+func TestCheckSecretRef(t *testing.T) {
+	t.Setenv("KAFKA_TEST_SECRET", "from-env")
+	marker := filepath.Join(t.TempDir(), "ran")
+
+	tests := []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{name: "env", value: "env:KAFKA_TEST_SECRET"},
+		{name: "missing env", value: "env:KAFKA_TEST_MISSING", err: "is not set"},
+		{name: "missing file", value: "file:" + filepath.Join(t.TempDir(), "missing"), err: "no such file"},
+		{name: "cmd", value: "cmd:touch " + marker},
+		{name: "empty cmd", value: "cmd: ", err: "empty command"},
+		{name: "keyring", value: "keyring:kafka/prod/producer"},
+		{name: "keyring without user", value: "keyring:kafka", err: "must be service/user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSecretRef(tt.value)
+			if tt.err == "" && err != nil {
+				t.Errorf("checkSecretRef() error = %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	// Commands only run when connecting
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Error("Expected the command not to run")
+	}
+}
+
+func TestConfig_WithoutSecrets(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{password: "typed-in-the-ui", want: ""},
+		{password: "cmd:pass show kafka/prod", want: "cmd:pass show kafka/prod"},
+		{password: "keyring:kafka/prod", want: "keyring:kafka/prod"},
+	}
+
+	for _, tt := range tests {
+		config := &Config{Topic: testTopic, KeyPassword: tt.password}
+		clean := config.withoutSecrets()
+		if clean.KeyPassword != tt.want {
+			t.Errorf("Expected %q to be saved as %q, got %q", tt.password, tt.want, clean.KeyPassword)
+		}
+		if config.KeyPassword != tt.password {
+			t.Errorf("Expected the original config to keep %q, got %q", tt.password, config.KeyPassword)
+		}
+	}
+}
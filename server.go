@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// maxRequestBody limits the size of a produce request
+const maxRequestBody = 16 << 20
+
+// Server is the HTTP API of the serve command. It sends messages with the
+// same producer as the UI and lists topics through the cluster admin.
+type Server struct {
+	producer *KafkaProducer
+	admin    *KafkaAdmin
+	token    string
+	log      io.Writer
+	mux      *http.ServeMux
+}
+
+// ProduceRequest is the body of POST /topics/{topic}/messages. Key and value
+// use the export encoding: a JSON document is sent as-is, a string as text
+// and key_encoding/value_encoding "base64" marks binary data.
+type ProduceRequest struct {
+	Key           json.RawMessage `json:"key,omitempty"`
+	KeyEncoding   string          `json:"key_encoding,omitempty"`
+	Value         json.RawMessage `json:"value"`
+	ValueEncoding string          `json:"value_encoding,omitempty"`
+	Headers       []ExportHeader  `json:"headers,omitempty"`
+	Partition     *int32          `json:"partition,omitempty"`
+}
+
+// ProduceResponse reports where a message was written
+type ProduceResponse struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// TopicResponse describes a topic in GET /topics
+type TopicResponse struct {
+	Name              string `json:"name"`
+	Partitions        int32  `json:"partitions"`
+	ReplicationFactor int16  `json:"replication_factor"`
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
+
+// NewServer creates the API handler. An empty token disables
+// authentication; otherwise every request except GET /health needs an
+// "Authorization: Bearer TOKEN" header. Requests are logged to log.
+func NewServer(producer *KafkaProducer, admin *KafkaAdmin, token string, log io.Writer) *Server {
+	s := &Server{
+		producer: producer,
+		admin:    admin,
+		token:    token,
+		log:      log,
+		mux:      http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("GET /topics", s.authorized(s.handleTopics))
+	s.mux.HandleFunc("POST /topics/{topic}/messages", s.authorized(s.handleProduce))
+	// Unknown routes get the JSON error format too
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no route for %s %s", r.Method, r.URL.Path))
+	})
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+
+	if s.log != nil {
+		fmt.Fprintf(s.log, "%s %s %s %d %s\n", start.Format(time.RFC3339), r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// authorized wraps handlers that require the bearer token
+func (s *Server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="kafka-producer-ui"`)
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+				return
+			}
+		}
+		handler(w, r)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	if err := s.admin.Ping(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("kafka is unreachable: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": version})
+}
+
+func (s *Server) handleTopics(w http.ResponseWriter, _ *http.Request) {
+	topics, err := s.admin.ListTopics()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	response := make([]TopicResponse, 0, len(topics))
+	for _, topic := range topics {
+		response = append(response, TopicResponse(topic))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleProduce(w http.ResponseWriter, r *http.Request) {
+	topic := r.PathValue("topic")
+	if !topicNamePattern.MatchString(topic) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid topic name %q", topic))
+		return
+	}
+
+	var request ProduceRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	msg, err := request.OutgoingMessage(topic)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if msg.Value == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("message value cannot be empty"))
+		return
+	}
+	// The serdes do not change message bytes, so only the transform script
+	// can make the UI and the API send different bytes
+	if msg, err = s.producer.Transform(msg); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	partition, offset, err := s.producer.Send(msg)
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, sarama.ErrUnknownTopicOrPartition) || errors.Is(err, sarama.ErrInvalidPartition) {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ProduceResponse{Topic: topic, Partition: partition, Offset: offset})
+}
+
+// OutgoingMessage converts the request into a message for topic
+func (r ProduceRequest) OutgoingMessage(topic string) (OutgoingMessage, error) {
+	record := ExportRecord{
+		Key:           r.Key,
+		KeyEncoding:   r.KeyEncoding,
+		Value:         r.Value,
+		ValueEncoding: r.ValueEncoding,
+		Headers:       r.Headers,
+		Partition:     r.Partition,
+	}
+	msg, err := record.OutgoingMessage()
+	if err != nil {
+		return OutgoingMessage{}, err
+	}
+	msg.Topic = topic
+	return msg, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error(), Status: status})
+}
+
+// statusRecorder remembers the status code for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// newTestServer starts the API with a mock producer that records sent
+// messages and fails for topic "missing", and an admin on a mock cluster
+// with an "events" topic. It returns the server URL.
+func newTestServer(t *testing.T, token string, transform *Transformer) (string, *[]*sarama.ProducerMessage) {
+	t.Helper()
+
+	var sent []*sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{
+			sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+				if msg.Topic == "missing" {
+					return 0, 0, sarama.ErrUnknownTopicOrPartition
+				}
+				sent = append(sent, msg)
+				return 0, int64(len(sent) - 1), nil
+			},
+		},
+		config:    &Config{Topic: "events"},
+		transform: transform,
+	}
+	admin := newTestAdmin(t, newMockCluster(t, "events"))
+
+	server := httptest.NewServer(NewServer(producer, admin, token, nil))
+	t.Cleanup(server.Close)
+
+	return server.URL, &sent
+}
+
+func doRequest(t *testing.T, method, url, token, body string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var decoded any
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Expected a JSON response to %s %s, got error %v", method, url, err)
+	}
+	object, _ := decoded.(map[string]any)
+	return resp, object
+}
+
+func TestServer_Produce(t *testing.T) {
+	url, sent := newTestServer(t, "", nil)
+
+	body := `{"key":"user-1","value":{"total": 100},"headers":[{"key":"source","value":"postman"}],"partition":2}`
+	resp, result := doRequest(t, http.MethodPost, url+"/topics/orders/messages", "", body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", resp.StatusCode, result)
+	}
+	if result["topic"] != "orders" || result["offset"] != float64(0) {
+		t.Errorf("Expected the message on orders at offset 0, got %v", result)
+	}
+
+	if len(*sent) != 1 {
+		t.Fatalf("Expected one message, got %d", len(*sent))
+	}
+	msg := (*sent)[0]
+	key, _ := msg.Key.Encode()
+	value, _ := msg.Value.Encode()
+	if msg.Topic != "orders" || msg.Partition != 2 || string(key) != "user-1" || string(value) != `{"total": 100}` {
+		t.Errorf("Expected key user-1 and the JSON value on orders/2, got %s %d %s %s", msg.Topic, msg.Partition, key, value)
+	}
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Value) != "postman" {
+		t.Errorf("Expected the source header, got %+v", msg.Headers)
+	}
+}
+
+func TestServer_ProduceTransform(t *testing.T) {
+	transform, err := LoadTransformer(writeScript(t, `
+def transform(msg):
+    if msg["key"] == "reject":
+        fail("rejected")
+    msg["headers"]["signature"] = sha256(msg["value"])
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, sent := newTestServer(t, "", transform)
+
+	resp, result := doRequest(t, http.MethodPost, url+"/topics/orders/messages", "", `{"key":"k","value":"abc"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", resp.StatusCode, result)
+	}
+	msg := (*sent)[0]
+	if len(msg.Headers) != 1 || string(msg.Headers[0].Key) != "signature" || len(msg.Headers[0].Value) != 64 {
+		t.Errorf("Expected the transform script to add a signature header, got %+v", msg.Headers)
+	}
+
+	resp, result = doRequest(t, http.MethodPost, url+"/topics/orders/messages", "", `{"key":"reject","value":"abc"}`)
+	if message, _ := result["error"].(string); resp.StatusCode != http.StatusBadRequest || !strings.Contains(message, "rejected") {
+		t.Errorf("Expected the script error as 400, got %d: %v", resp.StatusCode, result)
+	}
+	if len(*sent) != 1 {
+		t.Errorf("Expected the rejected message not to be sent, got %d message(s)", len(*sent))
+	}
+}
+
+func TestServer_ProduceErrors(t *testing.T) {
+	url, _ := newTestServer(t, "", nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		error  string
+	}{
+		{name: "invalid JSON", path: "/topics/events/messages", body: `{"value":`, status: http.StatusBadRequest, error: "invalid request body"},
+		{name: "unknown field", path: "/topics/events/messages", body: `{"valeu":1}`, status: http.StatusBadRequest, error: "unknown field"},
+		{name: "missing value", path: "/topics/events/messages", body: `{"key":"k"}`, status: http.StatusBadRequest, error: "value cannot be empty"},
+		{name: "bad encoding", path: "/topics/events/messages", body: `{"value":"a","value_encoding":"hex"}`, status: http.StatusBadRequest, error: "invalid value"},
+		{name: "invalid topic", path: "/topics/bad%20topic/messages", body: `{"value":1}`, status: http.StatusBadRequest, error: "invalid topic name"},
+		{name: "unknown topic", path: "/topics/missing/messages", body: `{"value":1}`, status: http.StatusNotFound, error: "failed to send message"},
+		{name: "unknown route", path: "/messages", body: `{}`, status: http.StatusNotFound, error: "no route for POST /messages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, result := doRequest(t, http.MethodPost, url+tt.path, "", tt.body)
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected %d, got %d: %v", tt.status, resp.StatusCode, result)
+			}
+			message, _ := result["error"].(string)
+			if !strings.Contains(message, tt.error) || result["status"] != float64(tt.status) {
+				t.Errorf("Expected error containing %q, got %v", tt.error, result)
+			}
+		})
+	}
+}
+
+func TestServer_Topics(t *testing.T) {
+	url, _ := newTestServer(t, "", nil)
+
+	req, _ := http.NewRequest(http.MethodGet, url+"/topics", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var topics []TopicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&topics); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(topics) != 1 || topics[0].Name != "events" || topics[0].Partitions != 1 {
+		t.Errorf("Expected the events topic, got %d %+v", resp.StatusCode, topics)
+	}
+}
+
+func TestServer_Auth(t *testing.T) {
+	url, _ := newTestServer(t, "secret", nil)
+
+	resp, result := doRequest(t, http.MethodPost, url+"/topics/events/messages", "", `{"value":1}`)
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 without a token, got %d: %v", resp.StatusCode, result)
+	}
+
+	resp, _ = doRequest(t, http.MethodPost, url+"/topics/events/messages", "wrong", `{"value":1}`)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 with a wrong token, got %d", resp.StatusCode)
+	}
+
+	resp, result = doRequest(t, http.MethodPost, url+"/topics/events/messages", "secret", `{"value":1}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with the token, got %d: %v", resp.StatusCode, result)
+	}
+
+	// The health check is public so that load balancers can probe it
+	resp, result = doRequest(t, http.MethodGet, url+"/health", "", "")
+	if resp.StatusCode != http.StatusOK || result["status"] != "ok" {
+		t.Errorf("Expected healthy response, got %d: %v", resp.StatusCode, result)
+	}
+}
+
+func TestRunServeCommand_Usage(t *testing.T) {
+	setTestHome(t)
+
+	var stdout, stderr strings.Builder
+	if code := runServeCommand([]string{"extra"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2, got %d", code)
+	}
+	if code := runServeCommand([]string{"--port", "1"}, &stdout, &stderr); code != 2 {
+		t.Errorf("Expected exit code 2 for an unknown flag, got %d", code)
+	}
+}
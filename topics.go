@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// TopicSummary describes a topic in the topic list
+type TopicSummary struct {
+	Name              string
+	Partitions        int32
+	ReplicationFactor int16
+}
+
+// TopicConfigEntry is a single topic configuration value
+type TopicConfigEntry struct {
+	Name      string
+	Value     string
+	Default   bool
+	ReadOnly  bool
+	Sensitive bool
+}
+
+// parseConfigPairs parses "key=value" pairs into a map. An empty value is
+// kept so that callers can treat it as "reset to default".
+func parseConfigPairs(pairs []string) (map[string]string, error) {
+	configs := make(map[string]string)
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid config %q, expected key=value", pair)
+		}
+		configs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return configs, nil
+}
+
+// ListTopics returns all topics sorted by name
+func (a *KafkaAdmin) ListTopics() ([]TopicSummary, error) {
+	details, err := a.admin.ListTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list topics: %w", err)
+	}
+
+	topics := make([]TopicSummary, 0, len(details))
+	for name, detail := range details {
+		topics = append(topics, TopicSummary{
+			Name:              name,
+			Partitions:        detail.NumPartitions,
+			ReplicationFactor: detail.ReplicationFactor,
+		})
+	}
+	sort.Slice(topics, func(i, j int) bool { return topics[i].Name < topics[j].Name })
+
+	return topics, nil
+}
+
+// CreateTopic creates a topic with the given partitions, replication factor
+// and topic configs (e.g. cleanup.policy, retention.ms)
+func (a *KafkaAdmin) CreateTopic(name string, partitions int32, replicationFactor int16, configs map[string]string) error {
+	detail := &sarama.TopicDetail{
+		NumPartitions:     partitions,
+		ReplicationFactor: replicationFactor,
+		ConfigEntries:     make(map[string]*string, len(configs)),
+	}
+	for key, value := range configs {
+		value := value
+		detail.ConfigEntries[key] = &value
+	}
+
+	if err := a.admin.CreateTopic(name, detail, false); err != nil {
+		return fmt.Errorf("failed to create topic %q: %w", name, err)
+	}
+	return nil
+}
+
+// AddPartitions increases the partition count of a topic to total
+func (a *KafkaAdmin) AddPartitions(name string, total int32) error {
+	if err := a.admin.CreatePartitions(name, total, nil, false); err != nil {
+		return fmt.Errorf("failed to add partitions to %q: %w", name, err)
+	}
+	return nil
+}
+
+// TopicConfig returns the configuration of a topic sorted by name
+func (a *KafkaAdmin) TopicConfig(name string) ([]TopicConfigEntry, error) {
+	entries, err := a.admin.DescribeConfig(sarama.ConfigResource{
+		Type: sarama.TopicResource,
+		Name: name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topic %q: %w", name, err)
+	}
+
+	configs := make([]TopicConfigEntry, 0, len(entries))
+	for _, entry := range entries {
+		configs = append(configs, TopicConfigEntry{
+			Name:      entry.Name,
+			Value:     entry.Value,
+			Default:   entry.Default || entry.Source == sarama.SourceDefault,
+			ReadOnly:  entry.ReadOnly,
+			Sensitive: entry.Sensitive,
+		})
+	}
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Name < configs[j].Name })
+
+	return configs, nil
+}
+
+// AlterTopicConfig sets the given topic configs; an empty value resets the
+// config to the broker default. Other configs are left untouched.
+func (a *KafkaAdmin) AlterTopicConfig(name string, changes map[string]string) error {
+	entries := make(map[string]sarama.IncrementalAlterConfigsEntry, len(changes))
+	for key, value := range changes {
+		if value == "" {
+			entries[key] = sarama.IncrementalAlterConfigsEntry{Operation: sarama.IncrementalAlterConfigsOperationDelete}
+			continue
+		}
+		value := value
+		entries[key] = sarama.IncrementalAlterConfigsEntry{
+			Operation: sarama.IncrementalAlterConfigsOperationSet,
+			Value:     &value,
+		}
+	}
+
+	if err := a.admin.IncrementalAlterConfig(sarama.TopicResource, name, entries, false); err != nil {
+		return fmt.Errorf("failed to alter topic %q: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteTopic deletes a topic
+func (a *KafkaAdmin) DeleteTopic(name string) error {
+	if err := a.admin.DeleteTopic(name); err != nil {
+		return fmt.Errorf("failed to delete topic %q: %w", name, err)
+	}
+	return nil
+}
+
+// PartitionWatermarks holds the offset range currently stored in a partition
+type PartitionWatermarks struct {
+	Partition int32
+	Low       int64
+	High      int64
+}
+
+// TruncateResult reports the low watermark of a partition before and after
+// its records were deleted
+type TruncateResult struct {
+	Partition int32
+	LowBefore int64
+	LowAfter  int64
+	High      int64
+}
+
+// parsePartitionList parses a comma-separated list of partition numbers. An
+// empty string selects all partitions and returns nil.
+func parsePartitionList(value string) ([]int32, error) {
+	var partitions []int32
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		partition, err := strconv.ParseInt(field, 10, 32)
+		if err != nil || partition < 0 {
+			return nil, fmt.Errorf("invalid partition %q", field)
+		}
+		partitions = append(partitions, int32(partition))
+	}
+	return partitions, nil
+}
+
+// Watermarks returns the low and high watermarks of the given partitions of
+// a topic, or of all partitions if none are given
+func (a *KafkaAdmin) Watermarks(topic string, partitions []int32) ([]PartitionWatermarks, error) {
+	all, err := a.client.Partitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get partitions of %q: %w", topic, err)
+	}
+	if len(partitions) == 0 {
+		partitions = all
+	}
+
+	watermarks := make([]PartitionWatermarks, 0, len(partitions))
+	for _, partition := range partitions {
+		if !slices.Contains(all, partition) {
+			return nil, fmt.Errorf("topic %q has no partition %d", topic, partition)
+		}
+
+		mark := PartitionWatermarks{Partition: partition}
+		if mark.Low, err = a.client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+			return nil, fmt.Errorf("failed to get low watermark of %s/%d: %w", topic, partition, err)
+		}
+		if mark.High, err = a.client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+			return nil, fmt.Errorf("failed to get high watermark of %s/%d: %w", topic, partition, err)
+		}
+		watermarks = append(watermarks, mark)
+	}
+	sort.Slice(watermarks, func(i, j int) bool { return watermarks[i].Partition < watermarks[j].Partition })
+
+	return watermarks, nil
+}
+
+// TruncateTopic deletes all records before offset before in the given
+// partitions (all partitions if none are given). A negative offset deletes
+// every record. Partitions that have nothing to delete are left untouched.
+func (a *KafkaAdmin) TruncateTopic(topic string, partitions []int32, before int64) ([]TruncateResult, error) {
+	watermarks, err := a.Watermarks(topic, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make(map[int32]int64)
+	for _, mark := range watermarks {
+		target := mark.High
+		if before >= 0 {
+			target = min(before, mark.High)
+		}
+		if target > mark.Low {
+			offsets[mark.Partition] = target
+		}
+	}
+
+	if len(offsets) > 0 {
+		if err := a.admin.DeleteRecords(topic, offsets); err != nil {
+			return nil, fmt.Errorf("failed to delete records of %q: %w", topic, err)
+		}
+	}
+
+	results := make([]TruncateResult, 0, len(watermarks))
+	for _, mark := range watermarks {
+		result := TruncateResult{Partition: mark.Partition, LowBefore: mark.Low, LowAfter: mark.Low, High: mark.High}
+		if _, ok := offsets[mark.Partition]; ok {
+			if result.LowAfter, err = a.client.GetOffset(topic, mark.Partition, sarama.OffsetOldest); err != nil {
+				return nil, fmt.Errorf("failed to get low watermark of %s/%d: %w", topic, mark.Partition, err)
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
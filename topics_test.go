@@ -0,0 +1,246 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+func newTestAdmin(t *testing.T, broker *sarama.MockBroker) *KafkaAdmin {
+	t.Helper()
+
+	admin, err := NewKafkaAdmin(&Config{Brokers: []string{broker.Addr()}})
+	if err != nil {
+		t.Fatalf("NewKafkaAdmin() error = %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	return admin
+}
+
+// lastRequest returns the most recent request of type T received by broker
+func lastRequest[T any](broker *sarama.MockBroker) (T, bool) {
+	var zero T
+	history := broker.History()
+	for i := len(history) - 1; i >= 0; i-- {
+		if req, ok := history[i].Request.(T); ok {
+			return req, true
+		}
+	}
+	return zero, false
+}
+
+func TestParseConfigPairs(t *testing.T) {
+	configs, err := parseConfigPairs([]string{"cleanup.policy=compact,delete", " retention.ms = 1000 ", "segment.ms=", ""})
+	if err != nil {
+		t.Fatalf("parseConfigPairs() error = %v", err)
+	}
+
+	want := map[string]string{
+		"cleanup.policy": "compact,delete",
+		"retention.ms":   "1000",
+		"segment.ms":     "",
+	}
+	if len(configs) != len(want) {
+		t.Fatalf("Expected %d configs, got %v", len(want), configs)
+	}
+	for key, value := range want {
+		if configs[key] != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, configs[key])
+		}
+	}
+
+	for _, invalid := range []string{"no-equals", "=value"} {
+		if _, err := parseConfigPairs([]string{invalid}); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestKafkaAdmin_CreateTopic(t *testing.T) {
+	broker := newMockCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	err := admin.CreateTopic("orders", 3, 1, map[string]string{"cleanup.policy": "compact"})
+	if err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	req, ok := lastRequest[*sarama.CreateTopicsRequest](broker)
+	if !ok {
+		t.Fatal("Expected a CreateTopicsRequest")
+	}
+
+	detail := req.TopicDetails["orders"]
+	if detail == nil {
+		t.Fatal("Expected topic detail for orders")
+	}
+	if detail.NumPartitions != 3 {
+		t.Errorf("Expected 3 partitions, got %d", detail.NumPartitions)
+	}
+	if value := detail.ConfigEntries["cleanup.policy"]; value == nil || *value != "compact" {
+		t.Errorf("Expected cleanup.policy=compact, got %v", value)
+	}
+}
+
+func TestKafkaAdmin_CreateTopic_Error(t *testing.T) {
+	broker := newMockCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	// The mock broker rejects topics with a reserved prefix
+	if err := admin.CreateTopic("_reserved", 1, 1, nil); err == nil {
+		t.Error("Expected error for rejected topic")
+	}
+}
+
+func TestKafkaAdmin_AddPartitions(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	admin := newTestAdmin(t, broker)
+
+	if err := admin.AddPartitions("orders", 6); err != nil {
+		t.Fatalf("AddPartitions() error = %v", err)
+	}
+
+	req, ok := lastRequest[*sarama.CreatePartitionsRequest](broker)
+	if !ok {
+		t.Fatal("Expected a CreatePartitionsRequest")
+	}
+	if count := req.TopicPartitions["orders"].Count; count != 6 {
+		t.Errorf("Expected partition count 6, got %d", count)
+	}
+}
+
+func TestKafkaAdmin_TopicConfig(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	admin := newTestAdmin(t, broker)
+
+	configs, err := admin.TopicConfig("orders")
+	if err != nil {
+		t.Fatalf("TopicConfig() error = %v", err)
+	}
+
+	found := false
+	for i, entry := range configs {
+		if i > 0 && configs[i-1].Name > entry.Name {
+			t.Error("Expected configs to be sorted by name")
+		}
+		if entry.Name == "retention.ms" {
+			found = true
+			if entry.Value != "5000" {
+				t.Errorf("Expected retention.ms=5000, got %s", entry.Value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected retention.ms in %v", configs)
+	}
+}
+
+func TestKafkaAdmin_AlterTopicConfig(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	admin := newTestAdmin(t, broker)
+
+	err := admin.AlterTopicConfig("orders", map[string]string{"retention.ms": "1000", "segment.ms": ""})
+	if err != nil {
+		t.Fatalf("AlterTopicConfig() error = %v", err)
+	}
+
+	req, ok := lastRequest[*sarama.IncrementalAlterConfigsRequest](broker)
+	if !ok || len(req.Resources) != 1 {
+		t.Fatal("Expected an IncrementalAlterConfigsRequest for one resource")
+	}
+
+	entries := req.Resources[0].ConfigEntries
+	if entry := entries["retention.ms"]; entry.Operation != sarama.IncrementalAlterConfigsOperationSet || *entry.Value != "1000" {
+		t.Errorf("Expected retention.ms to be set to 1000, got %+v", entry)
+	}
+	if entry := entries["segment.ms"]; entry.Operation != sarama.IncrementalAlterConfigsOperationDelete {
+		t.Errorf("Expected segment.ms to be reset, got %+v", entry)
+	}
+}
+
+func TestKafkaAdmin_DeleteTopic(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	admin := newTestAdmin(t, broker)
+
+	if err := admin.DeleteTopic("orders"); err != nil {
+		t.Fatalf("DeleteTopic() error = %v", err)
+	}
+
+	req, ok := lastRequest[*sarama.DeleteTopicsRequest](broker)
+	if !ok || len(req.Topics) != 1 || req.Topics[0] != "orders" {
+		t.Errorf("Expected DeleteTopicsRequest for orders, got %+v", req)
+	}
+}
+
+func TestParsePartitionList(t *testing.T) {
+	partitions, err := parsePartitionList(" 0, 2,,5 ")
+	if err != nil {
+		t.Fatalf("parsePartitionList() error = %v", err)
+	}
+	if len(partitions) != 3 || partitions[0] != 0 || partitions[1] != 2 || partitions[2] != 5 {
+		t.Errorf("Expected [0 2 5], got %v", partitions)
+	}
+
+	if partitions, err := parsePartitionList(""); err != nil || partitions != nil {
+		t.Errorf("Expected nil for empty list, got %v, %v", partitions, err)
+	}
+
+	for _, invalid := range []string{"a", "-1", "1,x"} {
+		if _, err := parsePartitionList(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestKafkaAdmin_Watermarks(t *testing.T) {
+	admin := newTestAdmin(t, newGroupsCluster(t))
+
+	watermarks, err := admin.Watermarks("orders", nil)
+	if err != nil {
+		t.Fatalf("Watermarks() error = %v", err)
+	}
+	if len(watermarks) != 2 || watermarks[0].Low != 10 || watermarks[0].High != 50 || watermarks[1].High != 7 {
+		t.Errorf("Unexpected watermarks %+v", watermarks)
+	}
+
+	if _, err := admin.Watermarks("orders", []int32{5}); err == nil {
+		t.Error("Expected error for a partition that does not exist")
+	}
+}
+
+func TestKafkaAdmin_TruncateTopic(t *testing.T) {
+	broker := newGroupsCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	// Partition 0 holds offsets 10-50, partition 1 holds 0-7
+	results, err := admin.TruncateTopic("orders", nil, 20)
+	if err != nil {
+		t.Fatalf("TruncateTopic() error = %v", err)
+	}
+	if len(results) != 2 || results[0].LowBefore != 10 || results[1].High != 7 {
+		t.Errorf("Unexpected results %+v", results)
+	}
+
+	req, ok := lastRequest[*sarama.DeleteRecordsRequest](broker)
+	if !ok {
+		t.Fatal("Expected a DeleteRecordsRequest")
+	}
+	offsets := req.Topics["orders"].PartitionOffsets
+	if offsets[0] != 20 || offsets[1] != 7 {
+		t.Errorf("Expected deletion before 20 and 7, got %v", offsets)
+	}
+}
+
+func TestKafkaAdmin_TruncateTopic_NothingToDelete(t *testing.T) {
+	broker := newGroupsCluster(t)
+	admin := newTestAdmin(t, broker)
+
+	// Offset 5 is below the low watermark of partition 0
+	if _, err := admin.TruncateTopic("orders", []int32{0}, 5); err != nil {
+		t.Fatalf("TruncateTopic() error = %v", err)
+	}
+	if _, ok := lastRequest[*sarama.DeleteRecordsRequest](broker); ok {
+		t.Error("Expected no DeleteRecordsRequest")
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/md5" //nolint:gosec // checksums for message formats that require MD5
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"time"
+
+	"go.starlark.net/lib/json"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// transformMaxSteps stops scripts that loop forever instead of hanging the UI
+const transformMaxSteps = 10_000_000
+
+// Transformer runs the transform(msg) function of a Starlark script on every
+// message before it is sent, e.g. to add a signature header:
+//
+//	def transform(msg):
+//	    msg["headers"]["signature"] = hmac_sha256("secret", msg["value"])
+//
+// msg is a dict with topic, key (None if unset), value, headers (a dict) and
+// partition (None lets the partitioner choose). The function may change msg
+// in place and return None, or return a new dict.
+type Transformer struct {
+	fn *starlark.Function
+}
+
+// transformBuiltins are the functions available to transform scripts
+var transformBuiltins = starlark.StringDict{
+	"json":        json.Module,
+	"sha256":      starlark.NewBuiltin("sha256", hashBuiltin(func(data []byte) []byte { sum := sha256.Sum256(data); return sum[:] })),
+	"md5":         starlark.NewBuiltin("md5", hashBuiltin(func(data []byte) []byte { sum := md5.Sum(data); return sum[:] })), //nolint:gosec // see import
+	"crc32":       starlark.NewBuiltin("crc32", crc32Builtin),
+	"hmac_sha256": starlark.NewBuiltin("hmac_sha256", hmacSHA256Builtin),
+	"base64":      starlark.NewBuiltin("base64", base64Builtin),
+	"uuid":        starlark.NewBuiltin("uuid", uuidBuiltin),
+	"now_ms":      starlark.NewBuiltin("now_ms", nowMsBuiltin),
+}
+
+// LoadTransformer compiles a script and checks that it defines transform
+func LoadTransformer(path string) (*Transformer, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	thread := &starlark.Thread{Name: "load " + path}
+	thread.SetMaxExecutionSteps(transformMaxSteps)
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, source, transformBuiltins)
+	if err != nil {
+		return nil, scriptError(err)
+	}
+	// Frozen globals keep one message from leaking state into the next
+	globals.Freeze()
+
+	fn, ok := globals["transform"].(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf("%s does not define a transform(msg) function", path)
+	}
+	if fn.NumParams() != 1 {
+		return nil, fmt.Errorf("%s: transform must take exactly one argument, takes %d", path, fn.NumParams())
+	}
+	return &Transformer{fn: fn}, nil
+}
+
+// Apply passes msg through the transform function
+func (t *Transformer) Apply(msg OutgoingMessage) (OutgoingMessage, error) {
+	dict := messageToDict(msg)
+
+	thread := &starlark.Thread{Name: "transform"}
+	thread.SetMaxExecutionSteps(transformMaxSteps)
+	result, err := starlark.Call(thread, t.fn, starlark.Tuple{dict}, nil)
+	if err != nil {
+		return OutgoingMessage{}, fmt.Errorf("transform failed: %w", scriptError(err))
+	}
+
+	switch result := result.(type) {
+	case starlark.NoneType:
+	case *starlark.Dict:
+		dict = result
+	default:
+		return OutgoingMessage{}, fmt.Errorf("transform must return a dict or None, got %s", result.Type())
+	}
+
+	transformed, err := dictToMessage(dict)
+	if err != nil {
+		return OutgoingMessage{}, fmt.Errorf("transform returned an invalid message: %w", err)
+	}
+	transformed.Timestamp = msg.Timestamp
+	return transformed, nil
+}
+
+// scriptError reduces a Starlark error to its message and the innermost
+// script position so that it fits the status bar
+func scriptError(err error) error {
+	var evalErr *starlark.EvalError
+	if !errors.As(err, &evalErr) {
+		return err
+	}
+	for i := range evalErr.CallStack {
+		if pos := evalErr.CallStack.At(i).Pos; pos.Filename() != "<builtin>" {
+			return fmt.Errorf("%s: %s", pos, evalErr.Msg)
+		}
+	}
+	return errors.New(evalErr.Msg)
+}
+
+func messageToDict(msg OutgoingMessage) *starlark.Dict {
+	headers := starlark.NewDict(len(msg.Headers))
+	for _, header := range msg.Headers {
+		_ = headers.SetKey(starlark.String(header.Key), starlark.String(header.Value))
+	}
+
+	dict := starlark.NewDict(5)
+	_ = dict.SetKey(starlark.String("topic"), starlark.String(msg.Topic))
+	_ = dict.SetKey(starlark.String("key"), starlark.None)
+	if msg.Key != nil {
+		_ = dict.SetKey(starlark.String("key"), starlark.String(msg.Key))
+	}
+	_ = dict.SetKey(starlark.String("value"), starlark.String(msg.Value))
+	_ = dict.SetKey(starlark.String("headers"), headers)
+	_ = dict.SetKey(starlark.String("partition"), starlark.None)
+	if msg.Partition >= 0 {
+		_ = dict.SetKey(starlark.String("partition"), starlark.MakeInt(int(msg.Partition)))
+	}
+	return dict
+}
+
+func dictToMessage(dict *starlark.Dict) (OutgoingMessage, error) {
+	msg := OutgoingMessage{Partition: -1}
+
+	for _, item := range dict.Items() {
+		name, ok := starlark.AsString(item[0])
+		if !ok {
+			return OutgoingMessage{}, fmt.Errorf("unexpected field %s", item[0])
+		}
+		value := item[1]
+
+		switch name {
+		case "topic":
+			topic, ok := starlark.AsString(value)
+			if !ok || !topicNamePattern.MatchString(topic) {
+				return OutgoingMessage{}, fmt.Errorf("topic must be a valid topic name, got %s", value)
+			}
+			msg.Topic = topic
+		case "key":
+			if value == starlark.None {
+				continue
+			}
+			key, ok := starlark.AsString(value)
+			if !ok {
+				return OutgoingMessage{}, fmt.Errorf("key must be a string, bytes or None, got %s", value.Type())
+			}
+			msg.Key = []byte(key)
+		case "value":
+			data, ok := starlark.AsString(value)
+			if !ok {
+				return OutgoingMessage{}, fmt.Errorf("value must be a string or bytes, got %s", value.Type())
+			}
+			msg.Value = []byte(data)
+		case "headers":
+			headers, ok := value.(*starlark.Dict)
+			if !ok {
+				return OutgoingMessage{}, fmt.Errorf("headers must be a dict, got %s", value.Type())
+			}
+			for _, header := range headers.Items() {
+				key, keyOK := starlark.AsString(header[0])
+				text, valueOK := starlark.AsString(header[1])
+				if !keyOK || !valueOK {
+					return OutgoingMessage{}, fmt.Errorf("header %s must map a string to a string", header[0])
+				}
+				msg.Headers = append(msg.Headers, MessageHeader{Key: key, Value: text})
+			}
+		case "partition":
+			if value == starlark.None {
+				continue
+			}
+			var partition int32
+			if err := starlark.AsInt(value, &partition); err != nil || partition < 0 {
+				return OutgoingMessage{}, fmt.Errorf("partition must be a non-negative int or None, got %s", value)
+			}
+			msg.Partition = partition
+		default:
+			return OutgoingMessage{}, fmt.Errorf("unexpected field %q", name)
+		}
+	}
+
+	if msg.Topic == "" {
+		return OutgoingMessage{}, fmt.Errorf("topic is missing")
+	}
+	if msg.Value == nil {
+		return OutgoingMessage{}, fmt.Errorf("value is missing")
+	}
+	return msg, nil
+}
+
+// hashBuiltin returns a builtin that hashes its string or bytes argument and
+// returns the hex digest
+func hashBuiltin(sum func([]byte) []byte) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var data starlark.Value
+		if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &data); err != nil {
+			return nil, err
+		}
+		text, ok := starlark.AsString(data)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected string or bytes, got %s", b.Name(), data.Type())
+		}
+		return starlark.String(hex.EncodeToString(sum([]byte(text)))), nil
+	}
+}
+
+func hmacSHA256Builtin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var key, data string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 2, &key, &data); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(data))
+	return starlark.String(hex.EncodeToString(mac.Sum(nil))), nil
+}
+
+func crc32Builtin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var data string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &data); err != nil {
+		return nil, err
+	}
+	return starlark.MakeUint(uint(crc32.ChecksumIEEE([]byte(data)))), nil
+}
+
+func base64Builtin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var data string
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 1, &data); err != nil {
+		return nil, err
+	}
+	return starlark.String(base64.StdEncoding.EncodeToString([]byte(data))), nil
+}
+
+func uuidBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.String(newCorrelationID()), nil
+}
+
+func nowMsBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackPositionalArgs(b.Name(), args, kwargs, 0); err != nil {
+		return nil, err
+	}
+	return starlark.MakeInt64(time.Now().UnixMilli()), nil
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+)
+
+// writeScript writes a transform script to a temporary file
+func writeScript(t *testing.T, source string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transform.star")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTransformer_Apply(t *testing.T) {
+	in := OutgoingMessage{
+		Topic:     "orders",
+		Key:       []byte("order-1"),
+		Value:     []byte(`{"amount":10}`),
+		Headers:   []MessageHeader{{Key: "source", Value: "ui"}},
+		Partition: -1,
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		want   OutgoingMessage
+	}{
+		{
+			name:   "unchanged",
+			script: "def transform(msg):\n    pass\n",
+			want:   in,
+		},
+		{
+			name: "modify in place",
+			script: `def transform(msg):
+    msg["topic"] = "orders-v2"
+    msg["key"] = None
+    msg["partition"] = 3
+    msg["headers"]["checksum"] = sha256(msg["value"])
+`,
+			want: OutgoingMessage{
+				Topic: "orders-v2",
+				Value: []byte(`{"amount":10}`),
+				Headers: []MessageHeader{
+					{Key: "source", Value: "ui"},
+					{Key: "checksum", Value: fmt.Sprintf("%x", sha256.Sum256([]byte(`{"amount":10}`)))},
+				},
+				Partition: 3,
+			},
+		},
+		{
+			name: "return a new dict",
+			script: `def transform(msg):
+    body = json.decode(msg["value"])
+    body["amount"] = body["amount"] * 2
+    return {"topic": msg["topic"], "key": msg["key"] + "-x", "value": json.encode(body), "headers": {}}
+`,
+			want: OutgoingMessage{Topic: "orders", Key: []byte("order-1-x"), Value: []byte(`{"amount":20}`), Partition: -1},
+		},
+		{
+			name:   "hmac signature",
+			script: "def transform(msg):\n    msg[\"headers\"] = {\"signature\": hmac_sha256(\"secret\", \"data\")}\n",
+			want: OutgoingMessage{
+				Topic:     "orders",
+				Key:       []byte("order-1"),
+				Value:     []byte(`{"amount":10}`),
+				Headers:   []MessageHeader{{Key: "signature", Value: "1b2c16b75bd2a870c114153ccda5bcfca63314bc722fa160d690de133ccbb9db"}},
+				Partition: -1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer, err := LoadTransformer(writeScript(t, tt.script))
+			if err != nil {
+				t.Fatalf("LoadTransformer() error = %v", err)
+			}
+
+			got, err := transformer.Apply(in)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTransformer_Apply_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{name: "script failure", script: "def transform(msg):\n    fail(\"rejected\")\n", err: "transform.star:2:9: fail: rejected"},
+		{name: "wrong return type", script: "def transform(msg):\n    return 1\n", err: "must return a dict or None, got int"},
+		{name: "missing value", script: "def transform(msg):\n    msg.pop(\"value\")\n", err: "value is missing"},
+		{name: "invalid topic", script: "def transform(msg):\n    msg[\"topic\"] = \"bad topic\"\n", err: "topic must be a valid topic name"},
+		{name: "negative partition", script: "def transform(msg):\n    msg[\"partition\"] = -2\n", err: "partition must be a non-negative int"},
+		{name: "header value", script: "def transform(msg):\n    msg[\"headers\"][\"n\"] = 1\n", err: `header "n" must map a string to a string`},
+		{name: "unknown field", script: "def transform(msg):\n    msg[\"timestamp\"] = 1\n", err: `unexpected field "timestamp"`},
+		{name: "module state", script: "seen = []\ndef transform(msg):\n    seen.append(msg[\"value\"])\n", err: "cannot append to frozen list"},
+		{name: "endless loop", script: "def transform(msg):\n    for i in range(1000000000):\n        pass\n", err: "too many steps"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transformer, err := LoadTransformer(writeScript(t, tt.script))
+			if err != nil {
+				t.Fatalf("LoadTransformer() error = %v", err)
+			}
+
+			_, err = transformer.Apply(OutgoingMessage{Topic: "orders", Value: []byte("v"), Partition: -1})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadTransformer_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		err    string
+	}{
+		{name: "syntax error", script: "def transform(msg)\n", err: "got newline, want ':'"},
+		{name: "no transform", script: "x = 1\n", err: "does not define a transform(msg) function"},
+		{name: "wrong arity", script: "def transform(msg, extra):\n    pass\n", err: "takes 2"},
+		{name: "undefined name", script: "def transform(msg):\n    return undefined(msg)\n", err: "undefined: undefined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTransformer(writeScript(t, tt.script))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+
+	if _, err := LoadTransformer(filepath.Join(t.TempDir(), "missing.star")); err == nil {
+		t.Error("Expected an error for a missing script")
+	}
+}
+
+func TestKafkaProducer_SendMessage_Transform(t *testing.T) {
+	transformer, err := LoadTransformer(writeScript(t, "def transform(msg):\n    msg[\"headers\"][\"key-length\"] = str(len(msg[\"key\"]))\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent *sarama.ProducerMessage
+	producer := &KafkaProducer{
+		producer: &mockSyncProducer{sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			sent = msg
+			return 0, 7, nil
+		}},
+		config:    &Config{Topic: "orders"},
+		transform: transformer,
+	}
+
+	if _, offset, err := producer.SendMessage("order-1", "{}"); err != nil || offset != 7 {
+		t.Fatalf("SendMessage() = %d, %v", offset, err)
+	}
+	if sent.Topic != "orders" || len(sent.Headers) != 1 || string(sent.Headers[0].Value) != "7" {
+		t.Errorf("Expected the transformed message, got %+v", sent)
+	}
+
+	transformer, err = LoadTransformer(writeScript(t, "def transform(msg):\n    fail(\"no\")\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer.transform = transformer
+	if _, _, err := producer.SendMessage("order-1", "{}"); err == nil || !strings.Contains(err.Error(), "transform failed") {
+		t.Errorf("Expected a transform error, got %v", err)
+	}
+}
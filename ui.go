@@ -1,11 +1,15 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -24,6 +28,11 @@ type viewMode int
 const (
 	configView viewMode = iota
 	messageView
+	diagnosticsView
+	topicAdminView
+	groupsView
+	consumerView
+	scenarioView
 )
 
 // Input field index for config view
@@ -32,14 +41,56 @@ type configField int
 const (
 	brokerField configField = iota
 	topicField
+	fanOutField
 	certField
 	keyField
 	caField
+	keyPasswordField
+	tlsModeField
+	tlsServerNameField
+	saslMechanismField
+	kerberosUsernameField
+	kerberosRealmField
+	kerberosPasswordField
+	kerberosKeytabField
+	kerberosConfigField
+	kerberosServiceField
 	keySerdeField
 	valueSerdeField
+	replyTopicField
+	correlationHeaderField
+	transformScriptField
 	maxConfigField
 )
 
+// configFieldKeys maps config inputs to the field names used in validation results
+var configFieldKeys = map[configField]string{
+	brokerField:      fieldBrokers,
+	topicField:       fieldTopic,
+	fanOutField:      fieldFanOut,
+	certField:        fieldCertFile,
+	keyField:         fieldKeyFile,
+	caField:          fieldCAFile,
+	keyPasswordField: fieldKeyPassword,
+	keySerdeField:    fieldKeySerde,
+	valueSerdeField:  fieldValueSerde,
+
+	tlsModeField:       fieldTLS,
+	tlsServerNameField: fieldTLSServer,
+	saslMechanismField: fieldSASLMechanism,
+
+	kerberosUsernameField: fieldKerberosUsername,
+	kerberosRealmField:    fieldKerberosRealm,
+	kerberosPasswordField: fieldKerberosPassword,
+	kerberosKeytabField:   fieldKerberosKeytab,
+	kerberosConfigField:   fieldKerberosConfig,
+	kerberosServiceField:  fieldKerberosService,
+
+	replyTopicField:        fieldReplyTopic,
+	correlationHeaderField: fieldCorrelationHeader,
+	transformScriptField:   fieldTransformScript,
+}
+
 // Input field index for message view
 type messageField int
 
@@ -49,20 +100,37 @@ const (
 	maxMessageField
 )
 
-// Message represents a sent message with status
+// Message represents a sent message with status. Fan-out sends add one
+// message per topic. Requests sent in reply mode also carry the correlation
+// ID and, once it arrives, the reply. Messages sent in verify mode record
+// the outcome of reading them back.
 type Message struct {
 	Timestamp time.Time
+	Topic     string // set for fan-out sends
 	Key       string
 	Value     string
 	Status    string
 	Partition int32
 	Offset    int64
+
+	CorrelationID string
+	AwaitingReply bool
+	Reply         *ConsumedMessage
+	ReplyLatency  time.Duration
+	ReplyError    string
+
+	Verifying      bool
+	Verified       bool
+	VerifyMismatch []string
+	VerifyError    string
 }
 
 // Model holds the application state
 type model struct {
 	config           *Config
 	producer         *KafkaProducer
+	admin            *KafkaAdmin
+	clusterInfo      *ClusterInfo
 	configInputs     []textinput.Model
 	messages         []Message
 	messageKeyInput  textinput.Model
@@ -75,6 +143,16 @@ type model struct {
 	width            int
 	height           int
 	connected        bool
+	validation       ValidationResult
+	diagnostics      []DiagnosticStep
+	diagnosing       bool
+	topicAdmin       topicAdminState
+	groups           groupsState
+	consumer         consumerState
+	scenario         scenarioState
+	awaitReply       bool
+	verify           bool
+	keys             keyMap
 }
 
 type errMsg struct{ err error }
@@ -85,18 +163,42 @@ type successMsg struct{ msg string }
 
 type connectSuccessMsg struct {
 	producer *KafkaProducer
+	admin    *KafkaAdmin
+}
+
+// fanOutMsg carries the results of sending to the fan-out topics
+type fanOutMsg struct {
+	results []FanOutResult
 }
 
 type messageResult struct {
 	err       error
 	offset    int64
 	partition int32
+	watch     *ReplyWatch
+	sent      *OutgoingMessage
+}
+
+// replyMsg reports the reply to the request at index in the message history
+type replyMsg struct {
+	index   int
+	reply   ConsumedMessage
+	latency time.Duration
+	err     error
+}
+
+// verifyMsg reports the result of reading back the message at index in the
+// message history
+type verifyMsg struct {
+	index      int
+	mismatches []string
+	err        error
 }
 
 // initialModel creates the initial model
 func initialModel(config *Config) model {
 	// Create config input fields
-	configInputs := make([]textinput.Model, 7)
+	configInputs := make([]textinput.Model, maxConfigField)
 
 	// Broker input
 	configInputs[brokerField] = textinput.New()
@@ -111,24 +213,89 @@ func initialModel(config *Config) model {
 	configInputs[topicField].SetValue(config.Topic)
 	configInputs[topicField].Width = 60
 
+	// Fan-out topics input
+	configInputs[fanOutField] = textinput.New()
+	configInputs[fanOutField].Placeholder = "optional, e.g. orders, audit:string:json"
+	configInputs[fanOutField].SetValue(formatFanOutTargets(config.FanOut))
+	configInputs[fanOutField].Width = 60
+
 	// Cert input
 	configInputs[certField] = textinput.New()
-	configInputs[certField].Placeholder = "/path/to/cert.pem"
+	configInputs[certField].Placeholder = "/path/to/cert.pem, keystore.p12 or keystore.jks"
 	configInputs[certField].SetValue(config.CertFile)
 	configInputs[certField].Width = 60
 
 	// Key input
 	configInputs[keyField] = textinput.New()
-	configInputs[keyField].Placeholder = "/path/to/key.pem"
+	configInputs[keyField].Placeholder = "/path/to/key.pem (not needed with a keystore)"
 	configInputs[keyField].SetValue(config.KeyFile)
 	configInputs[keyField].Width = 60
 
 	// CA input
 	configInputs[caField] = textinput.New()
-	configInputs[caField].Placeholder = "/path/to/ca.pem"
+	configInputs[caField].Placeholder = "/path/to/ca.pem, truststore.p12 or .jks (empty: system CAs)"
 	configInputs[caField].SetValue(config.CAFile)
 	configInputs[caField].Width = 60
 
+	// Key password input, masked; only secret references are saved
+	configInputs[keyPasswordField] = textinput.New()
+	configInputs[keyPasswordField].Placeholder = "optional, or set $" + config.keyPasswordEnv()
+	configInputs[keyPasswordField].EchoMode = textinput.EchoPassword
+	configInputs[keyPasswordField].EchoCharacter = '•'
+	configInputs[keyPasswordField].SetValue(config.KeyPassword)
+	configInputs[keyPasswordField].Width = 60
+
+	// TLS mode input
+	configInputs[tlsModeField] = textinput.New()
+	configInputs[tlsModeField].Placeholder = "auto (on with a certificate or CA), on, insecure"
+	configInputs[tlsModeField].SetValue(formatTLSMode(config))
+	configInputs[tlsModeField].Width = 60
+
+	// TLS server name input
+	configInputs[tlsServerNameField] = textinput.New()
+	configInputs[tlsServerNameField].Placeholder = "optional, overrides the broker host name"
+	configInputs[tlsServerNameField].SetValue(config.TLSServerName)
+	configInputs[tlsServerNameField].Width = 60
+
+	// SASL mechanism input
+	configInputs[saslMechanismField] = textinput.New()
+	configInputs[saslMechanismField].Placeholder = "optional, GSSAPI or OAUTHBEARER (oauth_* settings in the config file)"
+	configInputs[saslMechanismField].SetValue(config.SASLMechanism)
+	configInputs[saslMechanismField].Width = 60
+
+	// Kerberos inputs, shown for GSSAPI only
+	configInputs[kerberosUsernameField] = textinput.New()
+	configInputs[kerberosUsernameField].Placeholder = "user or user@REALM"
+	configInputs[kerberosUsernameField].SetValue(config.KerberosUsername)
+	configInputs[kerberosUsernameField].Width = 60
+
+	configInputs[kerberosRealmField] = textinput.New()
+	configInputs[kerberosRealmField].Placeholder = "e.g. CORP.EXAMPLE.COM"
+	configInputs[kerberosRealmField].SetValue(config.KerberosRealm)
+	configInputs[kerberosRealmField].Width = 60
+
+	configInputs[kerberosPasswordField] = textinput.New()
+	configInputs[kerberosPasswordField].Placeholder = "not needed with a keytab"
+	configInputs[kerberosPasswordField].EchoMode = textinput.EchoPassword
+	configInputs[kerberosPasswordField].EchoCharacter = '•'
+	configInputs[kerberosPasswordField].SetValue(config.KerberosPassword)
+	configInputs[kerberosPasswordField].Width = 60
+
+	configInputs[kerberosKeytabField] = textinput.New()
+	configInputs[kerberosKeytabField].Placeholder = "/path/to/user.keytab"
+	configInputs[kerberosKeytabField].SetValue(config.KerberosKeytabPath)
+	configInputs[kerberosKeytabField].Width = 60
+
+	configInputs[kerberosConfigField] = textinput.New()
+	configInputs[kerberosConfigField].Placeholder = "default $KRB5_CONFIG or " + defaultKerberosConfigPath
+	configInputs[kerberosConfigField].SetValue(config.KerberosConfigPath)
+	configInputs[kerberosConfigField].Width = 60
+
+	configInputs[kerberosServiceField] = textinput.New()
+	configInputs[kerberosServiceField].Placeholder = defaultKerberosServiceName
+	configInputs[kerberosServiceField].SetValue(config.KerberosServiceName)
+	configInputs[kerberosServiceField].Width = 60
+
 	// Key Serde input
 	configInputs[keySerdeField] = textinput.New()
 	configInputs[keySerdeField].Placeholder = "string, json, bytearray"
@@ -149,6 +316,24 @@ func initialModel(config *Config) model {
 	}
 	configInputs[valueSerdeField].Width = 60
 
+	// Reply topic input
+	configInputs[replyTopicField] = textinput.New()
+	configInputs[replyTopicField].Placeholder = "optional, enables request/reply mode"
+	configInputs[replyTopicField].SetValue(config.ReplyTopic)
+	configInputs[replyTopicField].Width = 60
+
+	// Correlation header input
+	configInputs[correlationHeaderField] = textinput.New()
+	configInputs[correlationHeaderField].Placeholder = defaultCorrelationHeader
+	configInputs[correlationHeaderField].SetValue(config.CorrelationHeader)
+	configInputs[correlationHeaderField].Width = 60
+
+	// Transform script input
+	configInputs[transformScriptField] = textinput.New()
+	configInputs[transformScriptField].Placeholder = "optional, Starlark script with transform(msg)"
+	configInputs[transformScriptField].SetValue(config.TransformScript)
+	configInputs[transformScriptField].Width = 60
+
 	// Create message key input
 	messageKeyInput := textinput.New()
 	messageKeyInput.Placeholder = "optional-key"
@@ -171,6 +356,7 @@ func initialModel(config *Config) model {
 		messageFocus:     0,
 		messages:         []Message{},
 		connected:        false,
+		keys:             newKeyMap(config.Keymap),
 	}
 }
 
@@ -186,17 +372,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "esc":
+		if m.currentView == topicAdminView {
+			if cmd, handled := m.updateTopicAdmin(msg); handled {
+				return m, cmd
+			}
+		}
+		if m.currentView == groupsView {
+			if cmd, handled := m.updateGroups(msg); handled {
+				return m, cmd
+			}
+		}
+		if m.currentView == consumerView {
+			if cmd, handled := m.updateConsumer(msg); handled {
+				return m, cmd
+			}
+		}
+		if m.currentView == scenarioView {
+			if cmd, handled := m.updateScenario(msg); handled {
+				return m, cmd
+			}
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			m.stopConsumer()
+			m.stopScenario()
 			if m.producer != nil {
 				_ = m.producer.Close() // Ignore error on exit
 			}
+			if m.admin != nil {
+				_ = m.admin.Close() // Ignore error on exit
+			}
 			return m, tea.Quit
 
-		case "tab":
+		case key.Matches(msg, m.keys.NextField):
+			if !m.hasTextInputs() {
+				return m, nil
+			}
 			if m.currentView == configView {
 				m.configInputs[m.configFocus].Blur()
-				m.configFocus = (m.configFocus + 1) % int(maxConfigField)
+				for {
+					m.configFocus = (m.configFocus + 1) % int(maxConfigField)
+					if !m.configFieldHidden(configField(m.configFocus)) {
+						break
+					}
+				}
 				m.configInputs[m.configFocus].Focus()
 			} else {
 				if m.messageFocus == int(msgKeyField) {
@@ -211,13 +431,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "shift+tab":
+		case key.Matches(msg, m.keys.PrevField):
+			if !m.hasTextInputs() {
+				return m, nil
+			}
 			if m.currentView == configView {
 				m.configInputs[m.configFocus].Blur()
-				if m.configFocus == 0 {
-					m.configFocus = int(maxConfigField) - 1
-				} else {
-					m.configFocus--
+				for {
+					if m.configFocus == 0 {
+						m.configFocus = int(maxConfigField) - 1
+					} else {
+						m.configFocus--
+					}
+					if !m.configFieldHidden(configField(m.configFocus)) {
+						break
+					}
 				}
 				m.configInputs[m.configFocus].Focus()
 			} else {
@@ -233,19 +461,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case "f2":
+		case key.Matches(msg, m.keys.Switch):
 			// Toggle between views
+			if !m.hasTextInputs() {
+				m.setView(configView)
+				m.configInputs[m.configFocus].Focus()
+				return m, nil
+			}
 			if m.currentView == configView {
 				if m.connected {
 					m.configInputs[m.configFocus].Blur()
-					m.currentView = messageView
+					m.setView(messageView)
 					if m.messageFocus == int(msgKeyField) {
 						m.messageKeyInput.Focus()
 					} else {
 						m.messageValueArea.Focus()
 					}
 				} else {
-					m.statusMessage = "Please connect to Kafka first (F5)"
+					m.statusMessage = m.connectFirstMessage()
 				}
 			} else {
 				if m.messageFocus == int(msgKeyField) {
@@ -253,27 +486,120 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				} else {
 					m.messageValueArea.Blur()
 				}
-				m.currentView = configView
+				m.setView(configView)
 				m.configInputs[m.configFocus].Focus()
 			}
 			return m, nil
 
-		case "f5":
+		case key.Matches(msg, m.keys.Cluster):
+			// Refresh cluster overview
+			if m.admin == nil {
+				m.statusMessage = m.connectFirstMessage()
+				return m, nil
+			}
+			m.statusMessage = "Refreshing cluster info..."
+			return m, m.fetchClusterInfo()
+
+		case key.Matches(msg, m.keys.Topics):
+			// Open topic administration
+			if m.admin == nil {
+				m.statusMessage = m.connectFirstMessage()
+				return m, nil
+			}
+			return m, m.openTopicAdmin()
+
+		case key.Matches(msg, m.keys.Groups):
+			// Open consumer groups
+			if m.admin == nil {
+				m.statusMessage = m.connectFirstMessage()
+				return m, nil
+			}
+			return m, m.openGroups()
+
+		case key.Matches(msg, m.keys.Consume):
+			// Open consumer
+			if m.admin == nil {
+				m.statusMessage = m.connectFirstMessage()
+				return m, nil
+			}
+			return m, m.openConsumer()
+
+		case key.Matches(msg, m.keys.Scenario):
+			// Open scenario runner
+			if m.producer == nil {
+				m.statusMessage = m.connectFirstMessage()
+				return m, nil
+			}
+			m.openScenario()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Connect):
 			// Connect/Reconnect to Kafka
+			m.validateInputs()
+			if m.validation.HasErrors() {
+				m.statusMessage = fmt.Sprintf("Configuration has %d error(s), fix them before connecting", m.validation.ErrorCount())
+				return m, nil
+			}
 			return m, m.connect()
 
-		case "f9":
+		case key.Matches(msg, m.keys.Diagnose):
+			// Run connection diagnostics
+			if m.diagnosing {
+				return m, nil
+			}
+			if m.currentView == configView {
+				m.applyConfigInputs()
+			}
+			m.blurInputs()
+			m.setView(diagnosticsView)
+			m.diagnostics = nil
+			m.diagnosing = true
+			m.statusMessage = "Running connection diagnostics..."
+			return m, m.runDiagnostics()
+
+		case key.Matches(msg, m.keys.Save):
 			// Save config
+			m.validateInputs()
 			return m, m.saveConfig()
 
-		case "enter":
+		case key.Matches(msg, m.keys.Send):
 			if m.currentView == messageView {
 				// Send message
 				return m, m.sendMessage()
 			}
 			return m, nil
 
-		case "f10":
+		case key.Matches(msg, m.keys.Reply):
+			// Toggle request/reply mode
+			if m.currentView != messageView {
+				return m, nil
+			}
+			if m.config.ReplyTopic == "" {
+				m.statusMessage = "Set a reply topic in the configuration to await replies"
+				return m, nil
+			}
+			m.awaitReply = !m.awaitReply
+			if m.awaitReply {
+				m.statusMessage = fmt.Sprintf("Awaiting replies on %s (%s header)", m.config.ReplyTopic, m.config.correlationHeader())
+			} else {
+				m.statusMessage = "Request/reply mode disabled"
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Verify):
+			// Toggle read-back verification of sent messages
+			if m.currentView != messageView {
+				return m, nil
+			}
+			m.verify = !m.verify
+			if m.verify {
+				m.statusMessage = "Sent messages will be read back and compared"
+			} else {
+				m.statusMessage = "Verification disabled"
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Format):
 			// Format JSON in message value field
 			if m.currentView == messageView && m.messageFocus == int(msgValueField) {
 				return m, m.formatJSON()
@@ -283,6 +609,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Delegate to textinput/textarea for handling
 		var cmd tea.Cmd
+		if !m.hasTextInputs() {
+			return m, nil
+		}
 		if m.currentView == configView {
 			m.configInputs[m.configFocus], cmd = m.configInputs[m.configFocus].Update(msg)
 		} else {
@@ -300,8 +629,102 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case connectSuccessMsg:
 		m.producer = msg.producer
+		m.admin = msg.admin
+		m.clusterInfo = nil
 		m.connected = true
 		m.statusMessage = "Successfully connected to Kafka"
+		if m.admin != nil {
+			return m, m.fetchClusterInfo()
+		}
+		return m, nil
+
+	case topicsLoadedMsg:
+		m.topicAdmin.topics = msg.topics
+		if m.topicAdmin.selected >= len(msg.topics) {
+			m.topicAdmin.selected = max(len(msg.topics)-1, 0)
+		}
+		return m, nil
+
+	case topicConfigLoadedMsg:
+		m.topicAdmin.configs = msg.configs
+		if msg.status != "" {
+			m.statusMessage = msg.status
+		}
+		return m, nil
+
+	case topicAdminDoneMsg:
+		m.statusMessage = msg.status
+		m.topicAdmin.closeForm()
+		return m, m.loadTopics()
+
+	case topicWatermarksMsg:
+		if m.topicAdmin.mode == topicTruncateMode && m.topicAdmin.target == msg.topic {
+			m.topicAdmin.watermarks = msg.watermarks
+		}
+		return m, nil
+
+	case topicTruncatedMsg:
+		m.statusMessage = msg.status
+		m.topicAdmin.closeForm()
+		m.topicAdmin.truncated = msg.results
+		return m, nil
+
+	case tailStartedMsg:
+		return m, m.handleTailStarted(msg)
+
+	case tailRecordsMsg:
+		return m, m.handleTailRecords(msg)
+
+	case tailStoppedMsg:
+		m.handleTailStopped(msg)
+		return m, nil
+
+	case scenarioStartedMsg:
+		return m, m.handleScenarioStarted(msg)
+
+	case scenarioStepMsg:
+		return m, m.handleScenarioStep(msg)
+
+	case scenarioDoneMsg:
+		m.handleScenarioDone(msg)
+		return m, nil
+
+	case groupsLoadedMsg:
+		return m, m.handleGroupsLoaded(msg)
+
+	case groupsTickMsg:
+		return m, m.handleGroupsTick(msg)
+
+	case offsetPlanMsg:
+		m.handleOffsetPlan(msg)
+		return m, nil
+
+	case offsetResetDoneMsg:
+		m.statusMessage = msg.status
+		m.groups.reset = offsetResetForm{}
+		return m, m.refreshGroups()
+
+	case clusterInfoMsg:
+		m.clusterInfo = msg.info
+		return m, nil
+
+	case diagnosticStepMsg:
+		m.diagnostics = append(m.diagnostics, msg.step)
+		return m, waitForDiagnosticStep(msg.steps)
+
+	case diagnosticsDoneMsg:
+		m.diagnosing = false
+		failed := 0
+		for _, step := range m.diagnostics {
+			if step.Status == diagFailed {
+				failed++
+			}
+		}
+		if failed > 0 {
+			m.statusMessage = fmt.Sprintf("Diagnostics finished: %d check(s) failed", failed)
+		} else {
+			m.statusMessage = "Diagnostics finished: all checks passed"
+		}
 		return m, nil
 
 	case errMsg:
@@ -317,19 +740,71 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Value:     m.messageValueArea.Value(),
 				Status:    fmt.Sprintf("Failed: %v", msg.err),
 			})
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
 		} else {
-			m.messages = append(m.messages, Message{
+			sent := Message{
 				Timestamp: time.Now(),
 				Key:       m.messageKeyInput.Value(),
 				Value:     m.messageValueArea.Value(),
 				Status:    "Success",
 				Partition: msg.partition,
 				Offset:    msg.offset,
-			})
+			}
+			if msg.watch != nil {
+				sent.CorrelationID = msg.watch.CorrelationID()
+				sent.AwaitingReply = true
+			}
+			sent.Verifying = msg.sent != nil
+			m.messages = append(m.messages, sent)
 			// Clear message fields after successful send
 			m.messageKeyInput.SetValue("")
 			m.messageValueArea.SetValue("")
 		}
+		var cmds []tea.Cmd
+		if msg.watch != nil {
+			cmds = append(cmds, waitForReply(msg.watch, len(m.messages)-1, m.config.replyTimeout()))
+		}
+		if msg.err == nil && msg.sent != nil {
+			cmds = append(cmds, m.verifyMessage(len(m.messages)-1, *msg.sent, msg.partition, msg.offset))
+		}
+		return m, tea.Batch(cmds...)
+
+	case fanOutMsg:
+		return m, m.handleFanOut(msg)
+
+	case replyMsg:
+		if msg.index < 0 || msg.index >= len(m.messages) {
+			return m, nil
+		}
+		sent := &m.messages[msg.index]
+		sent.AwaitingReply = false
+		if msg.err != nil {
+			sent.ReplyError = msg.err.Error()
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+		} else {
+			sent.Reply = &msg.reply
+			sent.ReplyLatency = msg.latency
+			m.statusMessage = fmt.Sprintf("Reply received in %s", msg.latency.Round(time.Millisecond))
+		}
+		return m, nil
+
+	case verifyMsg:
+		if msg.index < 0 || msg.index >= len(m.messages) {
+			return m, nil
+		}
+		sent := &m.messages[msg.index]
+		sent.Verifying = false
+		switch {
+		case msg.err != nil:
+			sent.VerifyError = msg.err.Error()
+			m.statusMessage = fmt.Sprintf("Verification failed: %v", msg.err)
+		case len(msg.mismatches) > 0:
+			sent.VerifyMismatch = msg.mismatches
+			m.statusMessage = fmt.Sprintf("Read back P:%d O:%d differs: %s", sent.Partition, sent.Offset, strings.Join(msg.mismatches, "; "))
+		default:
+			sent.Verified = true
+			m.statusMessage = fmt.Sprintf("Read back P:%d O:%d matches", sent.Partition, sent.Offset)
+		}
 		return m, nil
 	}
 
@@ -342,9 +817,20 @@ func (m model) View() string {
 	}
 
 	var content string
-	if m.currentView == configView {
+	switch m.currentView {
+	case configView:
 		content = m.renderConfigView()
-	} else {
+	case diagnosticsView:
+		content = m.renderDiagnosticsView()
+	case topicAdminView:
+		content = m.renderTopicAdminView()
+	case groupsView:
+		content = m.renderGroupsView()
+	case consumerView:
+		content = m.renderConsumerView()
+	case scenarioView:
+		content = m.renderScenarioView()
+	default:
 		content = m.renderMessageView()
 	}
 
@@ -378,14 +864,12 @@ func (m model) View() string {
 		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
 		Padding(0, 2)
 
-	help := "󰌌 F2: Switch │ 󰛐 F5: Connect │ 󰆓 F9: Save │ 󰉢 F10: Format │  Enter: Send │ 󰩈 Esc: Quit"
-
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		content,
 		"",
 		statusStyle.Render(status),
-		helpStyle.Render(help),
+		helpStyle.Render(m.keys.helpView()),
 	)
 }
 
@@ -407,6 +891,15 @@ func (m model) renderConfigView() string {
 		Bold(true).
 		MarginTop(1)
 
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#FBBF24"})
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+
 	title := titleStyle.Render("⚡ Kafka Producer Configuration")
 
 	fields := []struct {
@@ -416,11 +909,25 @@ func (m model) renderConfigView() string {
 	}{
 		{"Brokers (comma-separated)", "󰒋", brokerField},
 		{"Topic", "󰏫", topicField},
+		{"Fan-out Topics (topic[:key serde[:value serde]])", "󰁕", fanOutField},
 		{"Client Certificate Path", "󰄤", certField},
 		{"Client Key Path", "󰌆", keyField},
 		{"CA Certificate Path", "󰷛", caField},
+		{"Key / Keystore Password (env:, file:, cmd: or keyring: refs are saved)", "󰌋", keyPasswordField},
+		{"TLS Mode", "󰒃", tlsModeField},
+		{"TLS Server Name (SNI)", "󰖟", tlsServerNameField},
+		{"SASL Mechanism", "󰌾", saslMechanismField},
+		{"Kerberos Username", "󰀄", kerberosUsernameField},
+		{"Kerberos Realm", "󰒍", kerberosRealmField},
+		{"Kerberos Password (env:, file:, cmd: or keyring: refs are saved)", "󰌋", kerberosPasswordField},
+		{"Kerberos Keytab Path", "󰌆", kerberosKeytabField},
+		{"krb5.conf Path", "󰈙", kerberosConfigField},
+		{"Kerberos Service Name", "󰒋", kerberosServiceField},
 		{"Key Serde", "󰘦", keySerdeField},
 		{"Value Serde", "󰘦", valueSerdeField},
+		{"Reply Topic", "󰑓", replyTopicField},
+		{"Correlation Header", "󰌷", correlationHeaderField},
+		{"Transform Script", "󰯁", transformScriptField},
 	}
 
 	var rows []string
@@ -428,6 +935,10 @@ func (m model) renderConfigView() string {
 	rows = append(rows, "")
 
 	for _, f := range fields {
+		if m.configFieldHidden(f.field) {
+			continue
+		}
+
 		var label string
 		if m.configFocus == int(f.field) {
 			label = focusedStyle.Render(f.icon + " " + f.label + " ›")
@@ -437,33 +948,92 @@ func (m model) renderConfigView() string {
 
 		rows = append(rows, label)
 		rows = append(rows, m.configInputs[f.field].View())
+
+		if f.field == topicField {
+			if mapping := m.config.topicSerde(m.configInputs[topicField].Value()); mapping != nil {
+				keySerde, valueSerde := m.config.serdesFor(m.configInputs[topicField].Value())
+				rows = append(rows, hintStyle.Render(fmt.Sprintf("  ↳ %s matches: key %s, value %s", mapping.Pattern, keySerde, valueSerde)))
+			}
+		}
+
+		for _, issue := range m.validation.ForField(configFieldKeys[f.field]) {
+			if issue.Warning {
+				rows = append(rows, warningStyle.Render("  ⚠ "+issue.Message))
+			} else {
+				rows = append(rows, errorStyle.Render("  ✗ "+issue.Message))
+			}
+		}
+	}
+
+	// Issues of settings that only exist in the config file
+	inputFields := slices.Collect(maps.Values(configFieldKeys))
+	for _, issue := range m.validation {
+		if slices.Contains(inputFields, issue.Field) {
+			continue
+		}
+		if issue.Warning {
+			rows = append(rows, warningStyle.Render("  ⚠ "+issue.String()))
+		} else {
+			rows = append(rows, errorStyle.Render("  ✗ "+issue.String()))
+		}
 	}
 
-	// Adaptive mTLS status badge
+	// Adaptive TLS status badge
 	certVal := m.configInputs[certField].Value()
 	keyVal := m.configInputs[keyField].Value()
 	caVal := m.configInputs[caField].Value()
+	mode := parseTLSMode(m.configInputs[tlsModeField].Value())
+
+	mtls := mtlsConfigured(certVal, keyVal)
+	tlsOn := mtls || caVal != "" || mode == tlsModeOn || mode == tlsModeInsecure
 
 	var authBadge string
-	if m.config.UseAuth || (certVal != "" && keyVal != "" && caVal != "") {
+	switch {
+	case tlsOn && mode == tlsModeInsecure:
+		authBadgeStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#FCA5A5"}).
+			Background(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#450A0A"}).
+			Padding(0, 1).
+			MarginTop(1)
+		authBadge = authBadgeStyle.Render("⚠ TLS INSECURE: broker certificates are not verified")
+	case tlsOn:
+		label := "🔒 TLS Enabled (server only)"
+		if mtls {
+			label = "🔒 mTLS Enabled"
+		}
 		authBadgeStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#10B981"}).
 			Background(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#064E3B"}).
 			Padding(0, 1).
 			MarginTop(1)
-		authBadge = authBadgeStyle.Render("🔒 mTLS Enabled")
-	} else {
+		authBadge = authBadgeStyle.Render(label)
+	default:
 		authBadgeStyle := lipgloss.NewStyle().
 			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#6B7280"}).
 			Background(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#1F2937"}).
 			Padding(0, 1).
 			MarginTop(1)
-		authBadge = authBadgeStyle.Render("🔓 mTLS Disabled")
+		authBadge = authBadgeStyle.Render("🔓 TLS Disabled")
+	}
+
+	if mechanism := m.config.saslMechanism(); mechanism != "" {
+		saslBadgeStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#60A5FA"}).
+			Background(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#1E3A8A"}).
+			Padding(0, 1).
+			MarginTop(1)
+		authBadge = lipgloss.JoinHorizontal(lipgloss.Top, authBadge, " ", saslBadgeStyle.Render("🔑 SASL "+mechanism))
 	}
 
 	rows = append(rows, "")
 	rows = append(rows, authBadge)
 
+	if m.clusterInfo != nil {
+		rows = append(rows, "")
+		rows = append(rows, m.renderClusterPanel())
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
@@ -481,9 +1051,35 @@ func (m model) renderMessageView() string {
 		Background(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#451A03"}).
 		Padding(0, 1).
 		Bold(true).
-		Render(m.config.Topic)
+		Render(m.targetTopics())
 
 	title := titleStyle.Render("󰭻 Send Message") + " " + topicBadge
+	if len(m.config.FanOut) == 0 {
+		keySerde, valueSerde := m.config.serdesFor(m.config.Topic)
+		serdeBadge := lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+			Padding(0, 1).
+			Render(fmt.Sprintf("󰘦 key: %s │ value: %s", cmp.Or(keySerde, defaultSerde), cmp.Or(valueSerde, defaultSerde)))
+		title += " " + serdeBadge
+	}
+	if m.awaitReply {
+		replyBadge := lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#60A5FA"}).
+			Background(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#172554"}).
+			Padding(0, 1).
+			Bold(true).
+			Render("⇄ reply: " + m.config.ReplyTopic)
+		title += " " + replyBadge
+	}
+	if m.verify {
+		verifyBadge := lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#6EE7B7"}).
+			Background(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#064E3B"}).
+			Padding(0, 1).
+			Bold(true).
+			Render("✓ verify")
+		title += " " + verifyBadge
+	}
 
 	fieldStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
@@ -568,10 +1164,14 @@ func (m model) renderMessageView() string {
 			timeStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"})
 			keyStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"})
 
-			msgStr := fmt.Sprintf("  %s %s │ Key: %s",
+			msgStr := fmt.Sprintf("  %s %s │ ",
 				timeStyle.Render(msg.Timestamp.Format("15:04:05")),
-				statusBadge,
-				keyStyle.Render(truncate(msg.Key, 20)))
+				statusBadge)
+			if msg.Topic != "" {
+				topicStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#FBBF24"})
+				msgStr += topicStyle.Render(msg.Topic) + " │ "
+			}
+			msgStr += "Key: " + keyStyle.Render(truncate(msg.Key, 20))
 
 			if msg.Status == "Success" {
 				partitionStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"})
@@ -579,37 +1179,76 @@ func (m model) renderMessageView() string {
 			}
 
 			rows = append(rows, msgStyle.Render(msgStr))
+
+			if msg.CorrelationID != "" {
+				rows = append(rows, renderReply(msg))
+			}
+			if line := renderVerification(msg); line != "" {
+				rows = append(rows, line)
+			}
 		}
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
+// renderReply renders the reply line shown under a request in the history
+func renderReply(msg Message) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+
+	switch {
+	case msg.AwaitingReply:
+		return dimStyle.Render(fmt.Sprintf("    ↳ awaiting reply (%s)…", msg.CorrelationID))
+	case msg.Reply == nil:
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"}).
+			Render("    ↳ ✗ " + msg.ReplyError)
+	}
+
+	latencyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#FBBF24"}).
+		Bold(true)
+	value := strings.Join(strings.Fields(string(msg.Reply.Value)), " ")
+	return fmt.Sprintf("    ↳ %s %s %s",
+		lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#6EE7B7"}).Render("reply"),
+		latencyStyle.Render(msg.ReplyLatency.Round(time.Millisecond).String()),
+		dimStyle.Render(fmt.Sprintf("│ P:%d O:%d │ %s", msg.Reply.Partition, msg.Reply.Offset, truncate(value, 60))))
+}
+
+// renderVerification renders the read-back result shown under a message in
+// the history, or "" if the message was not verified
+func renderVerification(msg Message) string {
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+
+	switch {
+	case msg.Verifying:
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"}).
+			Render("    ↳ verifying…")
+	case msg.Verified:
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#6EE7B7"}).
+			Render("    ↳ ✓ verified: key, value and headers match")
+	case len(msg.VerifyMismatch) > 0:
+		return errorStyle.Render("    ↳ ✗ mismatch: " + truncate(strings.Join(msg.VerifyMismatch, "; "), 80))
+	case msg.VerifyError != "":
+		return errorStyle.Render("    ↳ ✗ verify failed: " + msg.VerifyError)
+	}
+	return ""
+}
+
 func (m *model) connect() tea.Cmd {
 	return func() tea.Msg {
-		// Close existing producer
+		// Close existing producer and admin
 		if m.producer != nil {
 			_ = m.producer.Close() // Ignore error when reconnecting
 		}
-
-		// Update config from inputs
-		brokers := strings.Split(m.configInputs[brokerField].Value(), ",")
-		for i := range brokers {
-			brokers[i] = strings.TrimSpace(brokers[i])
+		if m.admin != nil {
+			_ = m.admin.Close() // Ignore error when reconnecting
 		}
 
-		m.config.Brokers = brokers
-		m.config.Topic = m.configInputs[topicField].Value()
-		m.config.CertFile = m.configInputs[certField].Value()
-		m.config.KeyFile = m.configInputs[keyField].Value()
-		m.config.CAFile = m.configInputs[caField].Value()
-		m.config.KeySerde = m.configInputs[keySerdeField].Value()
-		m.config.ValueSerde = m.configInputs[valueSerdeField].Value()
-
-		// Enable mTLS if certificates are provided
-		m.config.UseAuth = m.configInputs[certField].Value() != "" &&
-			m.configInputs[keyField].Value() != "" &&
-			m.configInputs[caField].Value() != ""
+		// Update config from inputs
+		m.applyConfigInputs()
 
 		// Create new producer
 		producer, err := NewKafkaProducer(m.config)
@@ -617,37 +1256,146 @@ func (m *model) connect() tea.Cmd {
 			return errMsg{err}
 		}
 
-		return connectSuccessMsg{producer: producer}
+		admin, err := NewKafkaAdmin(m.config)
+		if err != nil {
+			_ = producer.Close()
+			return errMsg{err}
+		}
+
+		return connectSuccessMsg{producer: producer, admin: admin}
 	}
 }
 
 func (m *model) saveConfig() tea.Cmd {
 	return func() tea.Msg {
 		// Update config from inputs
-		brokers := strings.Split(m.configInputs[brokerField].Value(), ",")
-		for i := range brokers {
-			brokers[i] = strings.TrimSpace(brokers[i])
-		}
-
-		m.config.Brokers = brokers
-		m.config.Topic = m.configInputs[topicField].Value()
-		m.config.CertFile = m.configInputs[certField].Value()
-		m.config.KeyFile = m.configInputs[keyField].Value()
-		m.config.CAFile = m.configInputs[caField].Value()
-		m.config.KeySerde = m.configInputs[keySerdeField].Value()
-		m.config.ValueSerde = m.configInputs[valueSerdeField].Value()
-		m.config.UseAuth = m.configInputs[certField].Value() != "" &&
-			m.configInputs[keyField].Value() != "" &&
-			m.configInputs[caField].Value() != ""
+		m.applyConfigInputs()
 
 		if err := SaveConfig(m.config); err != nil {
 			return errMsg{err}
 		}
 
+		if fields := m.config.plainSecrets(); len(fields) > 0 {
+			return successMsg{fmt.Sprintf("Configuration saved without %s: use an env:, file:, cmd: or keyring: reference to keep it", strings.Join(fields, ", "))}
+		}
 		return successMsg{"Configuration saved successfully"}
 	}
 }
 
+// setView switches to view. Leaving the consumer view stops tailing; the
+// commands that keep it open leave the consumer running.
+func (m *model) setView(view viewMode) {
+	if m.currentView == consumerView && view != consumerView {
+		m.stopConsumer()
+	}
+	m.currentView = view
+}
+
+// hasTextInputs reports whether the current view is one of the two
+// input screens handled by the tab and delegation logic in Update
+func (m *model) hasTextInputs() bool {
+	return m.currentView == configView || m.currentView == messageView
+}
+
+// connectFirstMessage is the status shown when an action needs a connection
+func (m *model) connectFirstMessage() string {
+	return fmt.Sprintf("Please connect to Kafka first (%s)", m.keys.Connect.Help().Key)
+}
+
+// blurInputs removes focus from the config and message inputs before
+// switching to another screen
+func (m *model) blurInputs() {
+	m.configInputs[m.configFocus].Blur()
+	m.messageKeyInput.Blur()
+	m.messageValueArea.Blur()
+}
+
+// applyConfigInputs copies the config view inputs into m.config
+func (m *model) applyConfigInputs() {
+	brokers := strings.Split(m.configInputs[brokerField].Value(), ",")
+	for i := range brokers {
+		brokers[i] = strings.TrimSpace(brokers[i])
+	}
+
+	m.config.Brokers = brokers
+	m.config.Topic = m.configInputs[topicField].Value()
+	m.config.FanOut = parseFanOutTargets(m.configInputs[fanOutField].Value(), m.config.FanOut)
+	m.config.CertFile = m.configInputs[certField].Value()
+	m.config.KeyFile = m.configInputs[keyField].Value()
+	m.config.CAFile = m.configInputs[caField].Value()
+	m.config.KeySerde = m.configInputs[keySerdeField].Value()
+	m.config.ValueSerde = m.configInputs[valueSerdeField].Value()
+	m.config.ReplyTopic = strings.TrimSpace(m.configInputs[replyTopicField].Value())
+	m.config.CorrelationHeader = m.configInputs[correlationHeaderField].Value()
+	m.config.TransformScript = strings.TrimSpace(m.configInputs[transformScriptField].Value())
+
+	m.config.KeyPassword = m.configInputs[keyPasswordField].Value()
+	m.config.TLSServerName = strings.TrimSpace(m.configInputs[tlsServerNameField].Value())
+
+	// Enable mTLS if a client certificate is provided, and TLS with it or
+	// with a CA unless the mode asks for TLS explicitly
+	m.config.UseAuth = mtlsConfigured(m.config.CertFile, m.config.KeyFile)
+	mode := parseTLSMode(m.configInputs[tlsModeField].Value())
+	m.config.UseTLS = mode == tlsModeOn || mode == tlsModeInsecure || m.config.UseAuth || m.config.CAFile != ""
+	m.config.TLSInsecureSkipVerify = mode == tlsModeInsecure
+
+	m.config.SASLMechanism = strings.TrimSpace(m.configInputs[saslMechanismField].Value())
+	m.config.KerberosUsername = strings.TrimSpace(m.configInputs[kerberosUsernameField].Value())
+	m.config.KerberosRealm = strings.TrimSpace(m.configInputs[kerberosRealmField].Value())
+	m.config.KerberosPassword = m.configInputs[kerberosPasswordField].Value()
+	m.config.KerberosKeytabPath = strings.TrimSpace(m.configInputs[kerberosKeytabField].Value())
+	m.config.KerberosConfigPath = strings.TrimSpace(m.configInputs[kerberosConfigField].Value())
+	m.config.KerberosServiceName = strings.TrimSpace(m.configInputs[kerberosServiceField].Value())
+}
+
+// configFieldHidden reports whether an input is hidden from the config
+// view: the Kerberos inputs only apply to the GSSAPI mechanism
+func (m *model) configFieldHidden(field configField) bool {
+	if field < kerberosUsernameField || field > kerberosServiceField {
+		return false
+	}
+	return !strings.EqualFold(strings.TrimSpace(m.configInputs[saslMechanismField].Value()), saslGSSAPI)
+}
+
+// validateInputs applies the config inputs and stores the validation
+// result so that renderConfigView can show issues next to each field
+func (m *model) validateInputs() {
+	m.applyConfigInputs()
+	m.validation = ValidateConfig(m.config)
+	if value := m.configInputs[tlsModeField].Value(); !slices.Contains(tlsModes, parseTLSMode(value)) {
+		m.validation = append(m.validation, ValidationIssue{
+			Field:   fieldTLS,
+			Message: fmt.Sprintf("unknown mode %q, expected one of %s", value, strings.Join(tlsModes, ", ")),
+		})
+	}
+}
+
+// TLS modes of the config view
+const (
+	tlsModeAuto     = "auto"
+	tlsModeOn       = "on"
+	tlsModeInsecure = "insecure"
+)
+
+var tlsModes = []string{tlsModeAuto, tlsModeOn, tlsModeInsecure}
+
+// parseTLSMode normalizes the TLS mode input, an empty value meaning auto
+func parseTLSMode(value string) string {
+	return cmp.Or(strings.ToLower(strings.TrimSpace(value)), tlsModeAuto)
+}
+
+// formatTLSMode returns the TLS mode input value for config
+func formatTLSMode(config *Config) string {
+	switch {
+	case config.TLSInsecureSkipVerify:
+		return tlsModeInsecure
+	case config.UseTLS && !config.UseAuth && config.CAFile == "":
+		return tlsModeOn
+	default:
+		return ""
+	}
+}
+
 func (m *model) sendMessage() tea.Cmd {
 	return func() tea.Msg {
 		if m.producer == nil {
@@ -661,11 +1409,130 @@ func (m *model) sendMessage() tea.Cmd {
 			return errMsg{fmt.Errorf("message value cannot be empty")}
 		}
 
+		if len(m.config.FanOut) > 0 {
+			if m.awaitReply {
+				return errMsg{fmt.Errorf("reply mode cannot be combined with fan-out topics")}
+			}
+			return fanOutMsg{results: m.producer.FanOut(m.config.FanOut, key, value)}
+		}
+
+		if m.awaitReply && m.config.ReplyTopic != "" {
+			return m.sendRequest(key, value)
+		}
+
+		if m.verify {
+			// Send the exact bytes that will be compared with the record
+			sent, err := m.producer.Prepare(key, value)
+			if err != nil {
+				return messageResult{err: err}
+			}
+			partition, offset, err := m.producer.Send(sent)
+			return messageResult{err: err, partition: partition, offset: offset, sent: &sent}
+		}
+
 		partition, offset, err := m.producer.SendMessage(key, value)
 		return messageResult{err: err, partition: partition, offset: offset}
 	}
 }
 
+// targetTopics names the topics the message view sends to
+func (m model) targetTopics() string {
+	if len(m.config.FanOut) > 0 {
+		return strings.Join(fanOutTopics(m.config.FanOut), ", ")
+	}
+	return m.config.Topic
+}
+
+// handleFanOut adds one history row per fan-out topic. The inputs are kept
+// if any topic failed so that the message can be sent again.
+func (m *model) handleFanOut(msg fanOutMsg) tea.Cmd {
+	key := m.messageKeyInput.Value()
+	value := m.messageValueArea.Value()
+
+	var cmds []tea.Cmd
+	var failed []string
+	for _, result := range msg.results {
+		row := Message{Timestamp: time.Now(), Topic: result.Topic, Key: key, Value: value}
+		if result.Err != nil {
+			row.Status = fmt.Sprintf("Failed: %v", result.Err)
+			failed = append(failed, fmt.Sprintf("%s: %v", result.Topic, result.Err))
+		} else {
+			row.Status = "Success"
+			row.Partition = result.Partition
+			row.Offset = result.Offset
+			row.Verifying = m.verify
+		}
+		m.messages = append(m.messages, row)
+		if row.Verifying {
+			cmds = append(cmds, m.verifyMessage(len(m.messages)-1, result.Sent, result.Partition, result.Offset))
+		}
+	}
+
+	if len(failed) > 0 {
+		m.statusMessage = fmt.Sprintf("Sent to %d of %d topics; %s", len(msg.results)-len(failed), len(msg.results), strings.Join(failed, "; "))
+	} else {
+		m.statusMessage = fmt.Sprintf("Sent to %d topics", len(msg.results))
+		m.messageKeyInput.SetValue("")
+		m.messageValueArea.SetValue("")
+	}
+	return tea.Batch(cmds...)
+}
+
+// sendRequest sends a message with a new correlation ID after starting to
+// watch the reply topic
+func (m *model) sendRequest(key, value string) tea.Msg {
+	consumer, err := NewKafkaConsumer(m.config)
+	if err != nil {
+		return messageResult{err: err}
+	}
+	watch, err := StartReplyWatch(consumer, m.config.ReplyTopic, m.config.correlationHeader())
+	if err != nil {
+		return messageResult{err: err}
+	}
+
+	request, err := m.producer.Prepare(key, value)
+	if err != nil {
+		_ = watch.Close()
+		return messageResult{err: err}
+	}
+	request.Headers = append(request.Headers, watch.Header())
+
+	watch.Sent()
+	partition, offset, err := m.producer.Send(request)
+	if err != nil {
+		_ = watch.Close()
+		return messageResult{err: err}
+	}
+	result := messageResult{partition: partition, offset: offset, watch: watch}
+	if m.verify {
+		result.sent = &request
+	}
+	return result
+}
+
+// waitForReply waits for the reply to the request at index in the history
+func waitForReply(watch *ReplyWatch, index int, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		reply, latency, err := watch.Wait(timeout)
+		return replyMsg{index: index, reply: reply, latency: latency, err: err}
+	}
+}
+
+// verifyMessage reads back the message at index in the history from the
+// topic it was sent to and compares it with what was sent
+func (m *model) verifyMessage(index int, sent OutgoingMessage, partition int32, offset int64) tea.Cmd {
+	return func() tea.Msg {
+		consumer, err := NewKafkaConsumer(m.config)
+		if err != nil {
+			return verifyMsg{index: index, err: err}
+		}
+		defer func() { _ = consumer.Close() }()
+
+		mismatches, err := VerifyRecord(consumer, cmp.Or(sent.Topic, m.config.Topic), partition, offset, sent)
+		return verifyMsg{index: index, mismatches: mismatches, err: err}
+	}
+}
+
 func (m *model) formatJSON() tea.Cmd {
 	return func() tea.Msg {
 		value := m.messageValueArea.Value()
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type clusterInfoMsg struct {
+	info *ClusterInfo
+}
+
+// fetchClusterInfo loads the cluster overview shown in the config view
+func (m *model) fetchClusterInfo() tea.Cmd {
+	admin := m.admin
+	return func() tea.Msg {
+		info, err := admin.ClusterInfo()
+		if err != nil {
+			return errMsg{err}
+		}
+		return clusterInfoMsg{info: info}
+	}
+}
+
+func (m model) renderClusterPanel() string {
+	info := m.clusterInfo
+
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Padding(0, 1)
+
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Bold(true)
+
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"})
+
+	clusterID := info.ClusterID
+	if clusterID == "" {
+		clusterID = "unknown"
+	}
+
+	var lines []string
+	lines = append(lines, headerStyle.Render("󰒍 Cluster Overview"))
+	lines = append(lines, fmt.Sprintf("%s %s  %s %d  %s %d",
+		labelStyle.Render("Cluster ID:"), clusterID,
+		labelStyle.Render("Controller:"), info.ControllerID,
+		labelStyle.Render("Topics:"), info.TopicCount))
+
+	lines = append(lines, labelStyle.Render(fmt.Sprintf("Brokers (%d):", len(info.Brokers))))
+	for _, broker := range info.Brokers {
+		line := fmt.Sprintf("  #%d %s", broker.ID, broker.Addr)
+		if broker.Rack != "" {
+			line += " rack=" + broker.Rack
+		}
+		if broker.ID == info.ControllerID {
+			line += " (controller)"
+		}
+		lines = append(lines, line)
+	}
+
+	var apis []string
+	for _, key := range info.APIVersions {
+		if _, ok := apiKeyNames[key.ApiKey]; ok && key.ApiKey <= 3 {
+			apis = append(apis, fmt.Sprintf("%s v%d-v%d", apiKeyName(key.ApiKey), key.MinVersion, key.MaxVersion))
+		}
+	}
+	apiLine := fmt.Sprintf("%s %d supported", labelStyle.Render("APIs:"), len(info.APIVersions))
+	if len(apis) > 0 {
+		apiLine += " (" + strings.Join(apis, ", ") + ")"
+	}
+	lines = append(lines, apiLine)
+
+	return panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_Update_F3_NotConnected(t *testing.T) {
+	m := initialModel(&Config{})
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF3})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if cmd != nil {
+		t.Error("Expected no command when not connected")
+	}
+	if !strings.Contains(updatedModel.statusMessage, "connect") {
+		t.Errorf("Expected connection warning in status, got: %s", updatedModel.statusMessage)
+	}
+}
+
+func TestModel_Update_ClusterInfoMsg(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 100
+	m.connected = true
+
+	info := &ClusterInfo{
+		ClusterID:    "abc-123",
+		ControllerID: 2,
+		Brokers: []BrokerInfo{
+			{ID: 1, Addr: "broker-1:9092", Rack: "eu-1a"},
+			{ID: 2, Addr: "broker-2:9092"},
+		},
+		APIVersions: []sarama.ApiVersionsResponseKey{{ApiKey: 0, MinVersion: 3, MaxVersion: 9}},
+		TopicCount:  42,
+	}
+
+	newModel, _ := m.Update(clusterInfoMsg{info: info})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	view := updatedModel.renderConfigView()
+	for _, want := range []string{"Cluster Overview", "abc-123", "broker-1:9092", "rack=eu-1a", "(controller)", "Topics:", "42", "Produce v3-v9"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in cluster panel", want)
+		}
+	}
+}
+
+func TestModel_Update_ConnectSuccessMsg_FetchesClusterInfo(t *testing.T) {
+	broker := newMockCluster(t, testTopic)
+
+	admin, err := NewKafkaAdmin(&Config{Brokers: []string{broker.Addr()}})
+	if err != nil {
+		t.Fatalf("NewKafkaAdmin() error = %v", err)
+	}
+	defer admin.Close()
+
+	m := initialModel(&Config{})
+	newModel, cmd := m.Update(connectSuccessMsg{producer: &KafkaProducer{config: &Config{}}, admin: admin})
+	if _, ok := newModel.(model); !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if cmd == nil {
+		t.Fatal("Expected cluster info command after connecting")
+	}
+
+	msg, ok := cmd().(clusterInfoMsg)
+	if !ok {
+		t.Fatalf("Expected clusterInfoMsg, got %T", msg)
+	}
+	if msg.info.TopicCount != 1 {
+		t.Errorf("Expected 1 topic, got %d", msg.info.TopicCount)
+	}
+}
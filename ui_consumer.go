@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// consumerInitialRecords is the number of existing records per partition
+	// shown when the consumer view opens
+	consumerInitialRecords = 50
+	// consumerHistorySize is the number of records kept for re-filtering
+	consumerHistorySize = 1000
+	// tailBatchSize limits the records delivered to the UI in one message
+	tailBatchSize = 256
+)
+
+// tailer consumes a topic in the background and applies the filter before
+// records reach the UI loop. It keeps the last consumerHistorySize records so
+// that a new filter also applies to what was already consumed.
+type tailer struct {
+	events   chan tailEvent
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	mu      sync.Mutex
+	filter  *Filter
+	history []ConsumedMessage
+	scanned int
+	closed  bool
+	err     error
+}
+
+// tailEvent carries matching records; reset replaces everything shown so far
+type tailEvent struct {
+	reset   bool
+	records []ConsumedMessage
+}
+
+type tailStartedMsg struct {
+	tailer *tailer
+	err    error
+}
+
+type tailRecordsMsg struct {
+	tailer  *tailer
+	reset   bool
+	records []ConsumedMessage
+	scanned int
+}
+
+type tailStoppedMsg struct {
+	tailer *tailer
+	err    error
+}
+
+// startTailer connects a consumer and starts tailing topic
+func startTailer(config *Config, topic string, filter *Filter) (*tailer, error) {
+	consumer, err := NewKafkaConsumer(config)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &tailer{
+		events: make(chan tailEvent, tailBatchSize),
+		stop:   make(chan struct{}),
+		filter: filter,
+	}
+	go func() {
+		err := consumer.Tail(topic, consumerInitialRecords, t.stop, t.handle)
+		_ = consumer.Close()
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.closed, t.err = true, err
+		close(t.events)
+	}()
+
+	return t, nil
+}
+
+func (t *tailer) handle(msg ConsumedMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.history = append(t.history, msg)
+	if len(t.history) > consumerHistorySize {
+		t.history = t.history[len(t.history)-consumerHistorySize:]
+	}
+	t.scanned++
+
+	if !t.filter.Match(msg) {
+		// Only update the counters, never wait for the UI
+		select {
+		case t.events <- tailEvent{}:
+		default:
+		}
+		return
+	}
+
+	select {
+	case t.events <- tailEvent{records: []ConsumedMessage{msg}}:
+	case <-t.stop:
+	}
+}
+
+// setFilter applies filter to new records and re-filters the history
+func (t *tailer) setFilter(filter *Filter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+
+	t.filter = filter
+	var matches []ConsumedMessage
+	for _, msg := range t.history {
+		if filter.Match(msg) {
+			matches = append(matches, msg)
+		}
+	}
+
+	select {
+	case t.events <- tailEvent{reset: true, records: matches}:
+	case <-t.stop:
+	}
+}
+
+func (t *tailer) scannedCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.scanned
+}
+
+func (t *tailer) close() {
+	t.stopOnce.Do(func() { close(t.stop) })
+}
+
+// waitForTail delivers the pending events of t as one message
+func waitForTail(t *tailer) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-t.events
+		if !ok {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			return tailStoppedMsg{tailer: t, err: t.err}
+		}
+
+		msg := tailRecordsMsg{tailer: t}
+		add := func(event tailEvent) {
+			if event.reset {
+				msg.reset = true
+				msg.records = nil
+			}
+			msg.records = append(msg.records, event.records...)
+		}
+		add(event)
+
+	drain:
+		for len(msg.records) < tailBatchSize {
+			select {
+			case event, ok := <-t.events:
+				if !ok {
+					break drain
+				}
+				add(event)
+			default:
+				break drain
+			}
+		}
+
+		msg.scanned = t.scannedCount()
+		return msg
+	}
+}
+
+// consumerState holds the state of the consumer view. selected is -1 while
+// the view follows the newest record.
+type consumerState struct {
+	tailer      *tailer
+	records     []ConsumedMessage
+	selected    int
+	scanned     int
+	filter      *Filter
+	filterInput textinput.Model
+	filterErr   error
+}
+
+// openConsumer switches to the consumer view and starts tailing the current
+// topic
+func (m *model) openConsumer() tea.Cmd {
+	m.blurInputs()
+	m.setView(consumerView)
+
+	s := &m.consumer
+	if s.tailer != nil {
+		return nil
+	}
+	if s.filterInput.Placeholder == "" {
+		s.filterInput = newFormInput(`key =~ "^user-" and $.total > 100`, "")
+		s.filterInput.Blur()
+	}
+	s.records = nil
+	s.selected = -1
+	s.scanned = 0
+	m.statusMessage = fmt.Sprintf("Consuming %s...", m.config.Topic)
+
+	config := *m.config
+	filter := s.filter
+	return func() tea.Msg {
+		t, err := startTailer(&config, config.Topic, filter)
+		return tailStartedMsg{tailer: t, err: err}
+	}
+}
+
+// stopConsumer stops tailing when the consumer view is left
+func (m *model) stopConsumer() {
+	if m.consumer.tailer != nil {
+		m.consumer.tailer.close()
+		m.consumer.tailer = nil
+	}
+}
+
+func (m *model) handleTailStarted(msg tailStartedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+		return nil
+	}
+	if m.currentView != consumerView || m.consumer.tailer != nil {
+		msg.tailer.close()
+		return nil
+	}
+	m.consumer.tailer = msg.tailer
+	m.statusMessage = fmt.Sprintf("Consuming %s", m.config.Topic)
+	return waitForTail(msg.tailer)
+}
+
+func (m *model) handleTailRecords(msg tailRecordsMsg) tea.Cmd {
+	s := &m.consumer
+	if msg.tailer != s.tailer {
+		return nil
+	}
+
+	if msg.reset {
+		s.records = nil
+		s.selected = -1
+	}
+	s.records = append(s.records, msg.records...)
+	if drop := len(s.records) - consumerHistorySize; drop > 0 {
+		s.records = s.records[drop:]
+		if s.selected >= 0 {
+			s.selected = max(s.selected-drop, 0)
+		}
+	}
+	s.scanned = msg.scanned
+
+	return waitForTail(msg.tailer)
+}
+
+func (m *model) handleTailStopped(msg tailStoppedMsg) {
+	if msg.tailer != m.consumer.tailer {
+		return
+	}
+	m.consumer.tailer = nil
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+	}
+}
+
+// updateConsumer handles keys on the consumer view. Keys it does not handle
+// fall through to the global handlers.
+func (m *model) updateConsumer(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := &m.consumer
+
+	if m.keys.isCommand(msg) {
+		return nil, false
+	}
+
+	if s.filterInput.Focused() {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			s.filterInput.Blur()
+			s.filterInput.SetValue(s.filter.String())
+			s.filterErr = nil
+			return nil, true
+		case key.Matches(msg, m.keys.Apply):
+			filter, err := ParseFilter(s.filterInput.Value())
+			if err != nil {
+				s.filterErr = err
+				return nil, true
+			}
+			s.filter, s.filterErr = filter, nil
+			s.filterInput.Blur()
+			t := s.tailer
+			if t == nil {
+				return nil, true
+			}
+			return func() tea.Msg {
+				t.setFilter(filter)
+				return nil
+			}, true
+		}
+		if key.Matches(msg, m.keys.Quit) {
+			return nil, false
+		}
+		var cmd tea.Cmd
+		s.filterInput, cmd = s.filterInput.Update(msg)
+		return cmd, true
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Filter):
+		s.filterInput.Focus()
+		return nil, true
+	case key.Matches(msg, m.keys.Up):
+		if s.selected < 0 {
+			s.selected = len(s.records)
+		}
+		if s.selected > 0 {
+			s.selected--
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.Down):
+		if s.selected >= 0 {
+			s.selected++
+			if s.selected >= len(s.records) {
+				s.selected = -1
+			}
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.Follow):
+		s.selected = -1
+		return nil, true
+	case key.Matches(msg, m.keys.Clear):
+		s.records = nil
+		s.selected = -1
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// selectedRecord returns the highlighted record, the newest one while
+// following, or nil if there are no records
+func (s *consumerState) selectedRecord() *ConsumedMessage {
+	if len(s.records) == 0 {
+		return nil
+	}
+	if s.selected < 0 || s.selected >= len(s.records) {
+		return &s.records[len(s.records)-1]
+	}
+	return &s.records[s.selected]
+}
+
+func (m model) renderConsumerView() string {
+	s := m.consumer
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+		MarginTop(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+
+	var rows []string
+	rows = append(rows, titleStyle.Render("󰍉 Consumer: "+m.config.Topic))
+
+	filterLabel := "Filter:"
+	if s.filterInput.Focused() {
+		filterLabel = selectedStyle.Render(filterLabel)
+	}
+	rows = append(rows, filterLabel+" "+s.filterInput.View())
+	if s.filterErr != nil {
+		rows = append(rows, errorStyle.Render("  ✗ "+s.filterErr.Error()))
+	}
+
+	state := "following"
+	if s.selected >= 0 {
+		state = fmt.Sprintf("record %d of %d", s.selected+1, len(s.records))
+	}
+	if s.tailer == nil {
+		state = "stopped"
+	}
+	rows = append(rows, dimStyle.Render(fmt.Sprintf("  Matched %d │ Scanned %d │ %s", len(s.records), s.scanned, state)))
+
+	rows = append(rows, fieldStyle.Render(fmt.Sprintf("  %-10s %10s  %-8s  %-20s %s", "PARTITION", "OFFSET", "TIME", "KEY", "VALUE")))
+	if len(s.records) == 0 {
+		rows = append(rows, dimStyle.Italic(true).Render("  No matching records yet"))
+	}
+
+	// Show the window of records that ends at the selection
+	visible := max(m.height-24, 5)
+	end := len(s.records)
+	if s.selected >= 0 {
+		end = min(max(s.selected+1, visible), len(s.records))
+	}
+	start := max(end-visible, 0)
+	selected := s.selectedRecord()
+	for i := start; i < end; i++ {
+		record := s.records[i]
+		line := fmt.Sprintf("%-10d %10d  %-8s  %-20s %s",
+			record.Partition, record.Offset, record.Timestamp.Format("15:04:05"),
+			truncate(string(record.Key), 20), truncate(strings.Join(strings.Fields(string(record.Value)), " "), 60))
+		if i == s.selected {
+			rows = append(rows, selectedStyle.Render("› "+line))
+		} else {
+			rows = append(rows, "  "+line)
+		}
+	}
+
+	if selected != nil {
+		rows = append(rows, fieldStyle.Render(fmt.Sprintf("Record %d@%d:", selected.Partition, selected.Offset)))
+		for _, header := range selected.Headers {
+			rows = append(rows, dimStyle.Render(fmt.Sprintf("  %s: %s", header.Key, header.Value)))
+		}
+		value := string(selected.Value)
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, selected.Value, "  ", "  ") == nil {
+			value = pretty.String()
+		}
+		lines := strings.Split(value, "\n")
+		if len(lines) > 10 {
+			lines = append(lines[:10], "…")
+		}
+		rows = append(rows, "  "+strings.Join(lines, "\n  "))
+	}
+
+	if s.filterInput.Focused() {
+		apply := m.keys.Apply
+		apply.SetHelp(apply.Help().Key, "Apply filter")
+		rows = append(rows, "", dimStyle.Render("  "+hintView(apply, m.keys.Cancel)))
+	} else {
+		rows = append(rows, "", dimStyle.Render("  "+hintView(m.keys.Filter, m.keys.Up, m.keys.Down, m.keys.Follow, m.keys.Clear, m.keys.back())))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestTailer returns a tailer that is fed by calling handle directly
+func newTestTailer(filter *Filter) *tailer {
+	return &tailer{
+		events: make(chan tailEvent, tailBatchSize),
+		stop:   make(chan struct{}),
+		filter: filter,
+	}
+}
+
+func testRecord(offset int64, key string) ConsumedMessage {
+	return ConsumedMessage{Topic: "test-topic", Offset: offset, Key: []byte(key), Value: []byte(fmt.Sprintf(`{"n":%d}`, offset))}
+}
+
+func consumerModel(t *testing.T) model {
+	t.Helper()
+
+	m := initialModel(&Config{Brokers: []string{"localhost:9092"}, Topic: "test-topic"})
+	m.width = 120
+	m.admin = &KafkaAdmin{}
+	m.currentView = consumerView
+	m.consumer.filterInput = newFormInput("", "")
+	m.consumer.selected = -1
+	m.consumer.tailer = newTestTailer(nil)
+	return m
+}
+
+func TestTailer_FiltersBeforeUI(t *testing.T) {
+	filter, _ := ParseFilter("$.n >= 2")
+	tl := newTestTailer(filter)
+
+	for i := int64(0); i < 4; i++ {
+		tl.handle(testRecord(i, "k"))
+	}
+
+	msg, ok := waitForTail(tl)().(tailRecordsMsg)
+	if !ok {
+		t.Fatalf("Expected tailRecordsMsg")
+	}
+	if len(msg.records) != 2 || msg.records[0].Offset != 2 || msg.reset {
+		t.Errorf("Expected offsets 2 and 3, got %+v", msg.records)
+	}
+	if msg.scanned != 4 {
+		t.Errorf("Expected 4 scanned records, got %d", msg.scanned)
+	}
+}
+
+func TestTailer_SetFilterRefiltersHistory(t *testing.T) {
+	tl := newTestTailer(nil)
+	for i := int64(0); i < 3; i++ {
+		tl.handle(testRecord(i, fmt.Sprintf("key-%d", i)))
+	}
+
+	filter, _ := ParseFilter("key == key-1")
+	tl.setFilter(filter)
+	tl.handle(testRecord(3, "key-1"))
+	tl.handle(testRecord(4, "key-2"))
+
+	msg := waitForTail(tl)().(tailRecordsMsg)
+	if !msg.reset {
+		t.Error("Expected the new filter to reset the records")
+	}
+	var offsets []int64
+	for _, record := range msg.records {
+		offsets = append(offsets, record.Offset)
+	}
+	if fmt.Sprint(offsets) != "[1 3]" {
+		t.Errorf("Expected offsets 1 and 3 after the reset, got %v", offsets)
+	}
+}
+
+func TestTailer_Stopped(t *testing.T) {
+	tl := newTestTailer(nil)
+	tl.closed, tl.err = true, fmt.Errorf("broker gone")
+	close(tl.events)
+
+	msg, ok := waitForTail(tl)().(tailStoppedMsg)
+	if !ok || msg.err == nil {
+		t.Errorf("Expected tailStoppedMsg with error, got %+v", msg)
+	}
+}
+
+func TestConsumerView_Records(t *testing.T) {
+	m := consumerModel(t)
+	tl := m.consumer.tailer
+
+	updated, cmd := m.Update(tailRecordsMsg{tailer: tl, records: []ConsumedMessage{testRecord(0, "a"), testRecord(1, "b")}, scanned: 5})
+	m = updated.(model)
+	if len(m.consumer.records) != 2 || m.consumer.scanned != 5 {
+		t.Errorf("Expected 2 records of 5 scanned, got %d of %d", len(m.consumer.records), m.consumer.scanned)
+	}
+	if cmd == nil {
+		t.Error("Expected to keep waiting for records")
+	}
+
+	// Records of a previous tailer are ignored
+	updated, cmd = m.Update(tailRecordsMsg{tailer: newTestTailer(nil), records: []ConsumedMessage{testRecord(9, "z")}})
+	m = updated.(model)
+	if len(m.consumer.records) != 2 || cmd != nil {
+		t.Errorf("Expected stale records to be ignored, got %d records", len(m.consumer.records))
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Matched 2 │ Scanned 5 │ following") {
+		t.Errorf("Expected counters in view, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Record 0@1:") {
+		t.Errorf("Expected the newest record to be shown, got:\n%s", view)
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyUp})
+	if m.consumer.selected != 0 || !strings.Contains(m.View(), "Record 0@0:") {
+		t.Errorf("Expected the first record to be selected, got %d", m.consumer.selected)
+	}
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnd})
+	if m.consumer.selected != -1 {
+		t.Errorf("Expected to follow the newest record, got %d", m.consumer.selected)
+	}
+}
+
+func TestConsumerView_FilterBar(t *testing.T) {
+	m := consumerModel(t)
+
+	m, _ = pressKeys(t, m, runes("/"))
+	if !m.consumer.filterInput.Focused() {
+		t.Fatal("Expected / to focus the filter bar")
+	}
+
+	m, _ = pressKeys(t, m, runes("color == red"))
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.consumer.filterErr == nil || !strings.Contains(m.View(), "unknown field") {
+		t.Errorf("Expected filter error in view, got %v", m.consumer.filterErr)
+	}
+
+	m.consumer.filterInput.SetValue("$.n > 1")
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.consumer.filterErr != nil || m.consumer.filter.String() != "$.n > 1" {
+		t.Errorf("Expected filter to be applied, got %v (%v)", m.consumer.filter, m.consumer.filterErr)
+	}
+	if m.consumer.filterInput.Focused() || cmd == nil {
+		t.Error("Expected the filter bar to close and the tailer to be updated")
+	}
+	if cmd() != nil {
+		t.Error("Expected the filter to be applied in the background")
+	}
+	if event := <-m.consumer.tailer.events; !event.reset {
+		t.Error("Expected the tailer to re-filter its history")
+	}
+
+	// Esc in the filter bar restores the applied filter instead of quitting
+	m, _ = pressKeys(t, m, runes("/"))
+	m, cmd = pressKeys(t, m, runes("x"), tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || m.consumer.filterInput.Value() != "$.n > 1" {
+		t.Errorf("Expected esc to cancel editing, got %q", m.consumer.filterInput.Value())
+	}
+}
+
+func TestConsumerView_LeavingStopsTailer(t *testing.T) {
+	m := consumerModel(t)
+	tl := m.consumer.tailer
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyF2})
+	m = updated.(model)
+
+	if m.consumer.tailer != nil || m.currentView != configView {
+		t.Error("Expected F2 to leave the consumer view")
+	}
+	select {
+	case <-tl.stop:
+	default:
+		t.Error("Expected the tailer to be stopped")
+	}
+}
+
+func TestConsumerView_CommandsKeepTailing(t *testing.T) {
+	setTestHome(t)
+	m := consumerModel(t)
+	tl := m.consumer.tailer
+
+	// Save and Format leave the consumer view open
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF9}, tea.KeyMsg{Type: tea.KeyF10})
+	if m.consumer.tailer != tl || m.currentView != consumerView {
+		t.Fatal("Expected to stay on the consumer view")
+	}
+	select {
+	case <-tl.stop:
+		t.Error("Expected the tailer to keep running")
+	default:
+	}
+}
+
+func TestConsumerView_RequiresConnection(t *testing.T) {
+	m := initialModel(&Config{Brokers: []string{"localhost:9092"}, Topic: "test-topic"})
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF8})
+	m = updated.(model)
+	if cmd != nil || m.currentView == consumerView {
+		t.Error("Expected F8 to require a connection")
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type diagnosticStepMsg struct {
+	step  DiagnosticStep
+	steps <-chan DiagnosticStep
+}
+
+type diagnosticsDoneMsg struct{}
+
+// runDiagnostics starts a diagnostics run in the background and streams
+// every finished step back to the model
+func (m *model) runDiagnostics() tea.Cmd {
+	config := *m.config
+	return func() tea.Msg {
+		steps := make(chan DiagnosticStep)
+		go func() {
+			RunDiagnostics(&config, func(step DiagnosticStep) { steps <- step })
+			close(steps)
+		}()
+		return waitForDiagnosticStep(steps)()
+	}
+}
+
+func waitForDiagnosticStep(steps <-chan DiagnosticStep) tea.Cmd {
+	return func() tea.Msg {
+		step, ok := <-steps
+		if !ok {
+			return diagnosticsDoneMsg{}
+		}
+		return diagnosticStepMsg{step: step, steps: steps}
+	}
+}
+
+func (m model) renderDiagnosticsView() string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	brokerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true).
+		MarginTop(1)
+
+	passStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#6EE7B7"})
+	failStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+	skipStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	detailStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"})
+
+	var rows []string
+	rows = append(rows, titleStyle.Render("󰓅 Connection Diagnostics"))
+
+	lastBroker := ""
+	for i, step := range m.diagnostics {
+		if i == 0 || step.Broker != lastBroker {
+			rows = append(rows, brokerStyle.Render("󰒋 "+step.Broker))
+			lastBroker = step.Broker
+		}
+
+		var line string
+		switch step.Status {
+		case diagPassed:
+			line = passStyle.Render(fmt.Sprintf("  ✓ %-20s %8s", step.Name, step.Duration.Round(time.Millisecond)))
+		case diagFailed:
+			line = failStyle.Render(fmt.Sprintf("  ✗ %-20s %8s", step.Name, step.Duration.Round(time.Millisecond)))
+		default:
+			line = skipStyle.Render(fmt.Sprintf("  – %-20s", step.Name))
+		}
+		rows = append(rows, line)
+
+		for _, detail := range step.Details {
+			rows = append(rows, detailStyle.Render("      "+detail))
+		}
+		if step.Err != nil {
+			rows = append(rows, failStyle.Render(fmt.Sprintf("      %v", step.Err)))
+		}
+	}
+
+	if m.diagnosing {
+		rows = append(rows, "", detailStyle.Italic(true).Render("  Running..."))
+	} else if len(m.diagnostics) > 0 {
+		rows = append(rows, "", detailStyle.Render(fmt.Sprintf("  %s: Run again │ %s: Back to configuration", m.keys.Diagnose.Help().Key, m.keys.Switch.Help().Key)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_Update_F6_StartsDiagnostics(t *testing.T) {
+	m := initialModel(&Config{Brokers: []string{"localhost:9092"}})
+	m.diagnostics = []DiagnosticStep{{Name: "stale"}}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF6})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if cmd == nil {
+		t.Error("Expected diagnostics command")
+	}
+	if updatedModel.currentView != diagnosticsView {
+		t.Errorf("Expected diagnosticsView, got %v", updatedModel.currentView)
+	}
+	if !updatedModel.diagnosing {
+		t.Error("Expected diagnosing to be true")
+	}
+	if len(updatedModel.diagnostics) != 0 {
+		t.Error("Expected previous diagnostics to be cleared")
+	}
+}
+
+func TestModel_Update_DiagnosticSteps(t *testing.T) {
+	m := initialModel(&Config{})
+	m.currentView = diagnosticsView
+	m.diagnosing = true
+
+	steps := make(chan DiagnosticStep)
+	close(steps)
+
+	newModel, cmd := m.Update(diagnosticStepMsg{
+		step:  DiagnosticStep{Broker: "b:9092", Name: "TCP connect", Status: diagFailed, Err: errors.New("refused")},
+		steps: steps,
+	})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if len(updatedModel.diagnostics) != 1 {
+		t.Fatalf("Expected 1 step, got %d", len(updatedModel.diagnostics))
+	}
+
+	// The follow-up command reads from the closed channel and reports completion
+	if _, ok := cmd().(diagnosticsDoneMsg); !ok {
+		t.Error("Expected diagnosticsDoneMsg from closed channel")
+	}
+
+	newModel, _ = updatedModel.Update(diagnosticsDoneMsg{})
+	updatedModel, ok = newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if updatedModel.diagnosing {
+		t.Error("Expected diagnosing to be false when done")
+	}
+	if !strings.Contains(updatedModel.statusMessage, "1 check(s) failed") {
+		t.Errorf("Expected failure summary in status, got %s", updatedModel.statusMessage)
+	}
+}
+
+func TestModel_RenderDiagnosticsView(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 100
+	m.currentView = diagnosticsView
+	m.diagnostics = []DiagnosticStep{
+		{Broker: "broker:9092", Name: "DNS lookup", Status: diagPassed, Duration: 3 * time.Millisecond, Details: []string{"resolved to 10.0.0.1"}},
+		{Broker: "broker:9092", Name: "TCP connect", Status: diagFailed, Err: errors.New("connection refused")},
+		{Broker: "broker:9092", Name: "TLS handshake", Status: diagSkipped},
+	}
+
+	view := m.View()
+
+	for _, want := range []string{"Connection Diagnostics", "broker:9092", "DNS lookup", "resolved to 10.0.0.1", "connection refused"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in diagnostics view", want)
+		}
+	}
+}
+
+func TestModel_Update_F2_FromDiagnostics(t *testing.T) {
+	m := initialModel(&Config{})
+	m.currentView = diagnosticsView
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyF2})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if updatedModel.currentView != configView {
+		t.Error("Expected F2 to return to configView from diagnostics")
+	}
+}
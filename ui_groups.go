@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// groupsRefreshInterval is the auto-refresh period of the consumer groups view
+const groupsRefreshInterval = 2 * time.Second
+
+// groupsState holds the state of the consumer groups screen. Every load
+// carries the generation it was started with so that a manual refresh or
+// leaving the screen stops the previous auto-refresh loop.
+type groupsState struct {
+	groups      []GroupSummary
+	selected    int
+	lags        []PartitionLag
+	autoRefresh bool
+	generation  int
+	updated     time.Time
+	reset       offsetResetForm
+}
+
+type groupsLoadedMsg struct {
+	generation int
+	groups     []GroupSummary
+	group      string
+	lags       []PartitionLag
+	err        error
+}
+
+type groupsTickMsg struct {
+	generation int
+}
+
+// selectedGroup returns the highlighted group or nil if there are no groups
+func (s *groupsState) selectedGroup() *GroupSummary {
+	if s.selected < 0 || s.selected >= len(s.groups) {
+		return nil
+	}
+	return &s.groups[s.selected]
+}
+
+// openGroups switches to the consumer groups screen with auto-refresh enabled
+func (m *model) openGroups() tea.Cmd {
+	m.blurInputs()
+	m.setView(groupsView)
+	m.groups.autoRefresh = true
+	return m.refreshGroups()
+}
+
+// refreshGroups starts a new load generation, dropping any pending refresh
+func (m *model) refreshGroups() tea.Cmd {
+	m.groups.generation++
+	group := ""
+	if selected := m.groups.selectedGroup(); selected != nil {
+		group = selected.ID
+	}
+	return m.loadGroups(m.groups.generation, group)
+}
+
+// loadGroups lists the consumer groups and the lag of group (or the first
+// group) on the current topic
+func (m *model) loadGroups(generation int, group string) tea.Cmd {
+	admin := m.admin
+	topic := m.config.Topic
+	return func() tea.Msg {
+		msg := groupsLoadedMsg{generation: generation}
+
+		msg.groups, msg.err = admin.ListGroups(topic)
+		if msg.err != nil || len(msg.groups) == 0 {
+			return msg
+		}
+
+		msg.group = msg.groups[0].ID
+		for _, g := range msg.groups {
+			if g.ID == group {
+				msg.group = group
+				break
+			}
+		}
+		msg.lags, msg.err = admin.GroupLag(msg.group, topic)
+		return msg
+	}
+}
+
+func (m *model) handleGroupsLoaded(msg groupsLoadedMsg) tea.Cmd {
+	s := &m.groups
+	if msg.generation != s.generation {
+		return nil
+	}
+
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+	} else {
+		s.groups = msg.groups
+		s.lags = msg.lags
+		s.updated = time.Now()
+		s.selected = 0
+		for i, g := range s.groups {
+			if g.ID == msg.group {
+				s.selected = i
+				break
+			}
+		}
+	}
+
+	if !s.autoRefresh || m.currentView != groupsView {
+		return nil
+	}
+	generation := msg.generation
+	return tea.Tick(groupsRefreshInterval, func(time.Time) tea.Msg {
+		return groupsTickMsg{generation: generation}
+	})
+}
+
+func (m *model) handleGroupsTick(msg groupsTickMsg) tea.Cmd {
+	s := &m.groups
+	if msg.generation != s.generation || !s.autoRefresh || m.currentView != groupsView {
+		return nil
+	}
+	group := ""
+	if selected := s.selectedGroup(); selected != nil {
+		group = selected.ID
+	}
+	return m.loadGroups(msg.generation, group)
+}
+
+// updateGroups handles keys on the consumer groups screen. Keys it does not
+// handle fall through to the global handlers.
+func (m *model) updateGroups(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := &m.groups
+	if s.reset.open() {
+		return m.updateOffsetReset(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if s.selected > 0 {
+			s.selected--
+			s.lags = nil
+			return m.refreshGroups(), true
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.Down):
+		if s.selected < len(s.groups)-1 {
+			s.selected++
+			s.lags = nil
+			return m.refreshGroups(), true
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.Refresh):
+		return m.refreshGroups(), true
+	case key.Matches(msg, m.keys.ResetOffsets):
+		m.openOffsetReset()
+		return nil, true
+	case key.Matches(msg, m.keys.AutoRefresh):
+		s.autoRefresh = !s.autoRefresh
+		if s.autoRefresh {
+			m.statusMessage = "Auto-refresh enabled"
+			return m.refreshGroups(), true
+		}
+		s.generation++
+		m.statusMessage = "Auto-refresh disabled"
+		return nil, true
+	}
+
+	return nil, false
+}
+
+func (m model) renderGroupsView() string {
+	s := m.groups
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+		MarginTop(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	lagStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#FBBF24"})
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#6EE7B7"})
+
+	var rows []string
+	rows = append(rows, titleStyle.Render("󰡨 Consumer Groups"))
+
+	if len(s.groups) == 0 {
+		rows = append(rows, dimStyle.Italic(true).Render("  No consumer groups found"))
+	}
+	for i, group := range s.groups {
+		line := fmt.Sprintf("%-40s %-20s members:%d", group.ID, group.State, len(group.Members))
+		if i == s.selected {
+			rows = append(rows, selectedStyle.Render("› "+line))
+		} else {
+			rows = append(rows, "  "+line)
+		}
+	}
+
+	if group := s.selectedGroup(); group != nil {
+		rows = append(rows, fieldStyle.Render(fmt.Sprintf("Members of %s:", group.ID)))
+		if len(group.Members) == 0 {
+			rows = append(rows, dimStyle.Render("  no active members"))
+		}
+		for _, member := range group.Members {
+			partitions := make([]string, len(member.Partitions))
+			for i, p := range member.Partitions {
+				partitions[i] = fmt.Sprint(p)
+			}
+			rows = append(rows, fmt.Sprintf("  %s (%s) %s partitions:[%s]",
+				member.ClientID, member.Host, dimStyle.Render(member.ID), strings.Join(partitions, ",")))
+		}
+
+		rows = append(rows, fieldStyle.Render(fmt.Sprintf("Lag on %s:", m.config.Topic)))
+		rows = append(rows, dimStyle.Render(fmt.Sprintf("  %-10s %15s %15s %10s", "PARTITION", "COMMITTED", "HIGH WATERMARK", "LAG")))
+		for _, lag := range s.lags {
+			committed, lagValue := "-", "-"
+			if lag.Committed >= 0 {
+				committed = fmt.Sprint(lag.Committed)
+				lagValue = fmt.Sprint(lag.Lag)
+			}
+			line := fmt.Sprintf("  %-10d %15s %15d %10s", lag.Partition, committed, lag.HighWatermark, lagValue)
+			if lag.Lag > 0 {
+				line = lagStyle.Render(line)
+			}
+			rows = append(rows, line)
+		}
+		if total := TotalLag(s.lags); total > 0 {
+			rows = append(rows, lagStyle.Render(fmt.Sprintf("  Total lag: %d", total)))
+		} else if len(s.lags) > 0 {
+			rows = append(rows, okStyle.Render("  ✓ No lag"))
+		}
+	}
+
+	refresh := "Auto-refresh off"
+	if s.autoRefresh {
+		refresh = fmt.Sprintf("Auto-refresh every %s", groupsRefreshInterval)
+	}
+	if !s.updated.IsZero() {
+		refresh += fmt.Sprintf(" (updated %s)", s.updated.Format("15:04:05"))
+	}
+	rows = append(rows, "", dimStyle.Render("  "+refresh))
+
+	if s.reset.open() {
+		rows = append(rows, m.renderOffsetReset()...)
+	} else {
+		rows = append(rows, dimStyle.Render("  "+hintView(m.keys.Up, m.keys.Down, m.keys.ResetOffsets, m.keys.Refresh, m.keys.AutoRefresh, m.keys.back())))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// groupsModel returns a connected model on the consumer groups screen with
+// the first load applied
+func groupsModel(t *testing.T) (model, tea.Cmd) {
+	t.Helper()
+
+	m := initialModel(&Config{Topic: "orders"})
+	m.width = 120
+	m.connected = true
+	m.admin = newTestAdmin(t, newGroupsCluster(t))
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF7})
+	if cmd == nil {
+		t.Fatal("Expected groups load command")
+	}
+
+	newModel, tick := m.Update(cmd())
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+	return updatedModel, tick
+}
+
+func TestModel_Update_F7_NotConnected(t *testing.T) {
+	m := initialModel(&Config{})
+
+	updatedModel, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF7})
+
+	if cmd != nil {
+		t.Error("Expected no command when not connected")
+	}
+	if updatedModel.currentView == groupsView {
+		t.Error("Expected to stay out of the groups view")
+	}
+}
+
+func TestModel_Groups_LoadAndRender(t *testing.T) {
+	m, tick := groupsModel(t)
+
+	if m.currentView != groupsView {
+		t.Fatalf("Expected groups view, got %v", m.currentView)
+	}
+	if len(m.groups.groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(m.groups.groups))
+	}
+	if tick == nil {
+		t.Error("Expected auto-refresh tick to be scheduled")
+	}
+
+	// The first group (audit) has no commits on the topic
+	m, cmd := pressKeys(t, m, runes("j"))
+	if cmd == nil {
+		t.Fatal("Expected load command after changing the selection")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+
+	if m.groups.selectedGroup().ID != "billing" {
+		t.Fatalf("Expected billing to be selected, got %s", m.groups.selectedGroup().ID)
+	}
+	if TotalLag(m.groups.lags) != 10 {
+		t.Errorf("Expected lag 10 for billing, got %d", TotalLag(m.groups.lags))
+	}
+
+	view := m.View()
+	for _, want := range []string{"Consumer Groups", "Stable", "billing-service", "Lag on orders", "Total lag: 10", "Auto-refresh every 2s"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in groups view", want)
+		}
+	}
+}
+
+func TestModel_Groups_StaleLoadIgnored(t *testing.T) {
+	m, _ := groupsModel(t)
+
+	stale := groupsLoadedMsg{generation: m.groups.generation - 1, groups: []GroupSummary{{ID: "stale"}}}
+	newModel, cmd := m.Update(stale)
+	m = newModel.(model)
+
+	if cmd != nil {
+		t.Error("Expected no tick for a stale load")
+	}
+	if m.groups.groups[0].ID == "stale" {
+		t.Error("Expected stale load to be ignored")
+	}
+}
+
+func TestModel_Groups_AutoRefreshToggle(t *testing.T) {
+	m, _ := groupsModel(t)
+
+	m, cmd := pressKeys(t, m, runes("a"))
+	if m.groups.autoRefresh || cmd != nil {
+		t.Fatal("Expected auto-refresh to be disabled")
+	}
+
+	newModel, cmd := m.Update(groupsTickMsg{generation: m.groups.generation - 1})
+	if cmd != nil {
+		t.Error("Expected tick from the previous loop to be dropped")
+	}
+	m = newModel.(model)
+	if !strings.Contains(m.View(), "Auto-refresh off") {
+		t.Error("Expected auto-refresh state in view")
+	}
+
+	m, cmd = pressKeys(t, m, runes("a"))
+	if !m.groups.autoRefresh || cmd == nil {
+		t.Error("Expected auto-refresh to be enabled with a reload")
+	}
+}
+
+func TestModel_Groups_TickStopsOutsideView(t *testing.T) {
+	m, _ := groupsModel(t)
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF2})
+	if m.currentView != configView {
+		t.Fatalf("Expected config view, got %v", m.currentView)
+	}
+
+	_, cmd := m.Update(groupsTickMsg{generation: m.groups.generation})
+	if cmd != nil {
+		t.Error("Expected auto-refresh to stop after leaving the view")
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// offsetResetForm holds the offset reset form of the consumer groups screen.
+// The form is open while group is set. Enter first previews the plan, a
+// second Enter applies it.
+type offsetResetForm struct {
+	group    string
+	inputs   []textinput.Model // strategy, value
+	focus    int
+	strategy ResetStrategy
+	plan     []OffsetChange
+	members  int
+}
+
+type offsetPlanMsg struct {
+	group    string
+	strategy ResetStrategy
+	plan     []OffsetChange
+	members  int
+}
+
+type offsetResetDoneMsg struct {
+	status string
+}
+
+func (f *offsetResetForm) open() bool {
+	return f.group != ""
+}
+
+// parse reads the strategy from the form inputs
+func (f *offsetResetForm) parse() (ResetStrategy, error) {
+	return ParseResetStrategy(f.inputs[0].Value(), f.inputs[1].Value())
+}
+
+// openOffsetReset opens the reset form for the selected group
+func (m *model) openOffsetReset() {
+	group := m.groups.selectedGroup()
+	if group == nil {
+		return
+	}
+
+	m.groups.reset = offsetResetForm{
+		group: group.ID,
+		inputs: []textinput.Model{
+			newFormInput("earliest, latest, offset, timestamp, shift", resetEarliest),
+			newFormInput("offset, RFC 3339 time / Unix ms, or shift amount", ""),
+		},
+	}
+	m.groups.reset.inputs[0].Focus()
+}
+
+// updateOffsetReset handles keys while the reset form is open
+func (m *model) updateOffsetReset(msg tea.KeyMsg) (tea.Cmd, bool) {
+	f := &m.groups.reset
+
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.groups.reset = offsetResetForm{}
+		return nil, true
+	case key.Matches(msg, m.keys.NextField, m.keys.PrevField):
+		f.inputs[f.focus].Blur()
+		if key.Matches(msg, m.keys.NextField) {
+			f.focus = (f.focus + 1) % len(f.inputs)
+		} else {
+			f.focus = (f.focus - 1 + len(f.inputs)) % len(f.inputs)
+		}
+		f.inputs[f.focus].Focus()
+		return nil, true
+	case key.Matches(msg, m.keys.Apply):
+		if f.plan == nil {
+			return m.previewOffsetReset(), true
+		}
+		if f.members > 0 {
+			m.statusMessage = fmt.Sprintf("Group %q has %d active member(s), stop its consumers first", f.group, f.members)
+			return nil, true
+		}
+		return m.executeOffsetReset(), true
+	}
+
+	if m.keys.leavesForm(msg) {
+		return nil, false
+	}
+
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	f.plan = nil
+	return cmd, true
+}
+
+func (m *model) previewOffsetReset() tea.Cmd {
+	f := &m.groups.reset
+	strategy, err := f.parse()
+	if err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+
+	admin := m.admin
+	group := f.group
+	topic := m.config.Topic
+	return func() tea.Msg {
+		plan, err := admin.PlanOffsetReset(group, topic, strategy)
+		if err != nil {
+			return errMsg{err}
+		}
+		members, err := admin.ActiveMembers(group)
+		if err != nil {
+			return errMsg{err}
+		}
+		return offsetPlanMsg{group: group, strategy: strategy, plan: plan, members: members}
+	}
+}
+
+func (m *model) executeOffsetReset() tea.Cmd {
+	f := m.groups.reset
+	admin := m.admin
+	topic := m.config.Topic
+	return func() tea.Msg {
+		if err := admin.ResetOffsets(f.group, topic, f.plan); err != nil {
+			return errMsg{err}
+		}
+		return offsetResetDoneMsg{status: fmt.Sprintf("Reset %d partition(s) of group %q to %s", len(f.plan), f.group, f.strategy)}
+	}
+}
+
+// handleOffsetPlan stores a preview unless the form changed in the meantime
+func (m *model) handleOffsetPlan(msg offsetPlanMsg) {
+	f := &m.groups.reset
+	if f.group != msg.group {
+		return
+	}
+	if strategy, err := f.parse(); err != nil || strategy != msg.strategy {
+		return
+	}
+
+	f.strategy = msg.strategy
+	f.plan = msg.plan
+	f.members = msg.members
+	if f.members > 0 {
+		m.statusMessage = fmt.Sprintf("Group %q has %d active member(s), reset is not possible", f.group, f.members)
+	} else {
+		m.statusMessage = "Review the new offsets and press Enter to apply"
+	}
+}
+
+func (m model) renderOffsetReset() []string {
+	f := m.groups.reset
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+		MarginTop(1)
+
+	focusedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true).
+		MarginTop(1)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+
+	var rows []string
+	rows = append(rows, fieldStyle.Render(fmt.Sprintf("󰑓 Reset offsets of %s on %s", f.group, m.config.Topic)))
+
+	labels := []string{"Strategy", "Value"}
+	for i, input := range f.inputs {
+		if i == f.focus {
+			rows = append(rows, focusedStyle.Render(labels[i]+" ›"))
+		} else {
+			rows = append(rows, fieldStyle.Render(labels[i]+":"))
+		}
+		rows = append(rows, input.View())
+	}
+
+	if f.plan != nil {
+		rows = append(rows, "", dimStyle.Render(fmt.Sprintf("  %-10s %12s %12s %10s  %s", "PARTITION", "CURRENT", "NEW", "CHANGE", "RANGE")))
+		for _, change := range f.plan {
+			current, delta := "-", "-"
+			if change.Current >= 0 {
+				current = fmt.Sprint(change.Current)
+				delta = fmt.Sprintf("%+d", change.Target-change.Current)
+			}
+			rows = append(rows, fmt.Sprintf("  %-10d %12s %12d %10s  %d-%d",
+				change.Partition, current, change.Target, delta, change.Low, change.High))
+		}
+		if f.members > 0 {
+			rows = append(rows, warnStyle.Render(fmt.Sprintf("⚠ Group has %d active member(s), stop its consumers before resetting", f.members)))
+		}
+	}
+
+	apply := m.keys.Apply
+	if f.plan == nil || f.members > 0 {
+		apply.SetHelp(apply.Help().Key, "Preview")
+	}
+	rows = append(rows, "", dimStyle.Render("  "+hintView(apply, m.keys.NextField, m.keys.Cancel)))
+
+	return rows
+}
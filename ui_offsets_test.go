@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_OffsetReset_PreviewAndApply(t *testing.T) {
+	m, _ := groupsModel(t)
+	if m.groups.selectedGroup().ID != "audit" {
+		t.Fatalf("Expected audit to be selected, got %s", m.groups.selectedGroup().ID)
+	}
+
+	m, _ = pressKeys(t, m, runes("o"))
+	if !m.groups.reset.open() {
+		t.Fatal("Expected reset form to open")
+	}
+
+	// Switch the strategy from the default "earliest" to "shift"
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlU}, runes("shift"), tea.KeyMsg{Type: tea.KeyTab}, runes("-2"))
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected preview command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+
+	plan := m.groups.reset.plan
+	if len(plan) != 2 || plan[0].Target != 48 || plan[1].Target != 5 {
+		t.Fatalf("Expected targets 48 and 5, got %+v", plan)
+	}
+	if !strings.Contains(m.View(), "Enter: Apply") {
+		t.Error("Expected apply hint after preview")
+	}
+
+	m, cmd = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected reset command")
+	}
+	newModel, reload := m.Update(cmd())
+	m = newModel.(model)
+
+	if m.groups.reset.open() {
+		t.Error("Expected form to close after the reset")
+	}
+	if !strings.Contains(m.statusMessage, `Reset 2 partition(s) of group "audit"`) {
+		t.Errorf("Expected success status, got: %s", m.statusMessage)
+	}
+	if reload == nil {
+		t.Error("Expected groups reload after the reset")
+	}
+}
+
+func TestModel_OffsetReset_ActiveMembersRefused(t *testing.T) {
+	m, _ := groupsModel(t)
+
+	m, cmd := pressKeys(t, m, runes("j"))
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+
+	m, cmd = pressKeys(t, m, runes("o"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected preview command")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(model)
+
+	if m.groups.reset.members != 1 {
+		t.Fatalf("Expected 1 active member, got %d", m.groups.reset.members)
+	}
+	if !strings.Contains(m.View(), "active member") {
+		t.Error("Expected active member warning in view")
+	}
+
+	m, cmd = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected reset to be refused")
+	}
+	if !strings.Contains(m.statusMessage, "stop its consumers") {
+		t.Errorf("Expected refusal status, got: %s", m.statusMessage)
+	}
+}
+
+func TestModel_OffsetReset_EditInvalidatesPreview(t *testing.T) {
+	m, _ := groupsModel(t)
+
+	m, cmd := pressKeys(t, m, runes("o"), tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+	if m.groups.reset.plan == nil {
+		t.Fatal("Expected preview")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.groups.reset.plan != nil {
+		t.Error("Expected edit to discard the preview")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.groups.reset.open() || m.currentView != groupsView {
+		t.Error("Expected Esc to close the form and stay on the groups screen")
+	}
+}
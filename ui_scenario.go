@@ -0,0 +1,269 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// scenarioRunner runs a scenario in the background and delivers the step
+// results to the UI loop
+type scenarioRunner struct {
+	results  chan StepResult
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (r *scenarioRunner) close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+type scenarioStartedMsg struct {
+	runner   *scenarioRunner
+	scenario *Scenario
+	err      error
+}
+
+type scenarioStepMsg struct {
+	runner *scenarioRunner
+	result StepResult
+}
+
+type scenarioDoneMsg struct {
+	runner *scenarioRunner
+}
+
+// scenarioState holds the state of the scenario view
+type scenarioState struct {
+	pathInput textinput.Model
+	runner    *scenarioRunner
+	scenario  *Scenario
+	results   []StepResult
+	started   time.Time
+	elapsed   time.Duration
+}
+
+// openScenario switches to the scenario view
+func (m *model) openScenario() {
+	m.blurInputs()
+	m.setView(scenarioView)
+
+	s := &m.scenario
+	if s.pathInput.Placeholder == "" {
+		s.pathInput = newFormInput("scenarios/order-flow.yaml", "")
+	}
+	if s.runner == nil {
+		s.pathInput.Focus()
+	}
+}
+
+// startScenario loads the scenario file and runs it with the connected
+// producer
+func (m *model) startScenario() tea.Cmd {
+	path := strings.TrimSpace(m.scenario.pathInput.Value())
+	if path == "" {
+		m.statusMessage = "Enter the path of a scenario file"
+		return nil
+	}
+	m.statusMessage = fmt.Sprintf("Starting %s...", path)
+
+	config := *m.config
+	producer := m.producer
+	return func() tea.Msg {
+		scenario, err := LoadScenario(path)
+		if err != nil {
+			return scenarioStartedMsg{err: err}
+		}
+		consumer, err := NewKafkaConsumer(&config)
+		if err != nil {
+			return scenarioStartedMsg{err: err}
+		}
+		run, err := NewScenarioRun(scenario, producer, consumer, config.Topic)
+		if err != nil {
+			_ = consumer.Close()
+			return scenarioStartedMsg{err: err}
+		}
+
+		runner := &scenarioRunner{
+			results: make(chan StepResult, len(scenario.Steps)),
+			stop:    make(chan struct{}),
+		}
+		go func() {
+			run.Run(runner.stop, func(result StepResult) { runner.results <- result })
+			_ = consumer.Close()
+			close(runner.results)
+		}()
+		return scenarioStartedMsg{runner: runner, scenario: scenario}
+	}
+}
+
+// stopScenario aborts a running scenario; the remaining steps are skipped
+func (m *model) stopScenario() {
+	if m.scenario.runner != nil {
+		m.scenario.runner.close()
+	}
+}
+
+// waitForStep delivers the next step result of r
+func waitForStep(r *scenarioRunner) tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-r.results
+		if !ok {
+			return scenarioDoneMsg{runner: r}
+		}
+		return scenarioStepMsg{runner: r, result: result}
+	}
+}
+
+func (m *model) handleScenarioStarted(msg scenarioStartedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+		return nil
+	}
+	s := &m.scenario
+	s.runner = msg.runner
+	s.scenario = msg.scenario
+	s.results = nil
+	s.started = time.Now()
+	s.pathInput.Blur()
+	m.statusMessage = fmt.Sprintf("Running %s", msg.scenario.Name)
+	return waitForStep(msg.runner)
+}
+
+func (m *model) handleScenarioStep(msg scenarioStepMsg) tea.Cmd {
+	if msg.runner != m.scenario.runner {
+		return nil
+	}
+	m.scenario.results = append(m.scenario.results, msg.result)
+	return waitForStep(msg.runner)
+}
+
+func (m *model) handleScenarioDone(msg scenarioDoneMsg) {
+	s := &m.scenario
+	if msg.runner != s.runner {
+		return
+	}
+	s.runner = nil
+	s.elapsed = time.Since(s.started)
+	if m.currentView == scenarioView {
+		s.pathInput.Focus()
+	}
+	m.statusMessage = fmt.Sprintf("%s finished: %s", s.scenario.Name, scenarioSummary(s.results))
+}
+
+// updateScenario handles keys on the scenario view. Keys it does not handle
+// fall through to the global handlers.
+func (m *model) updateScenario(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := &m.scenario
+
+	if m.keys.isCommand(msg) {
+		if !key.Matches(msg, m.keys.Scenario) {
+			s.pathInput.Blur()
+		}
+		return nil, false
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.RunScenario):
+		if s.runner != nil {
+			m.statusMessage = fmt.Sprintf("A scenario is already running (%s to stop it)", m.keys.StopScenario.Help().Key)
+			return nil, true
+		}
+		return m.startScenario(), true
+	case key.Matches(msg, m.keys.StopScenario):
+		if s.runner != nil {
+			m.stopScenario()
+			m.statusMessage = "Stopping scenario..."
+		}
+		return nil, true
+	}
+
+	if s.pathInput.Focused() && !key.Matches(msg, m.keys.Quit) {
+		var cmd tea.Cmd
+		s.pathInput, cmd = s.pathInput.Update(msg)
+		return cmd, true
+	}
+	return nil, false
+}
+
+// scenarioSummary counts the results by status, e.g. "3 passed, 1 failed"
+func scenarioSummary(results []StepResult) string {
+	counts := map[string]int{}
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	var parts []string
+	for _, status := range []string{stepPassed, stepFailed, stepSkipped} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m model) renderScenarioView() string {
+	s := m.scenario
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+		MarginTop(1)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+	successStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#059669", Dark: "#6EE7B7"})
+	runningStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#D97706", Dark: "#FBBF24"})
+
+	var rows []string
+	rows = append(rows, titleStyle.Render("󰐊 Scenario"))
+	rows = append(rows, "File: "+s.pathInput.View())
+	rows = append(rows, dimStyle.Render("  "+hintView(m.keys.RunScenario, m.keys.StopScenario)))
+
+	if s.scenario == nil {
+		rows = append(rows, dimStyle.Italic(true).Render("  No scenario run yet"))
+		return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	}
+
+	rows = append(rows, fieldStyle.Render(s.scenario.Name))
+	for i, step := range s.scenario.Steps {
+		if i >= len(s.results) {
+			name := step.title(cmp.Or(s.scenario.Topic, m.config.Topic))
+			if i == len(s.results) && s.runner != nil {
+				rows = append(rows, runningStyle.Render(fmt.Sprintf("  ⋯ %s", name)))
+			} else {
+				rows = append(rows, dimStyle.Render(fmt.Sprintf("  · %s", name)))
+			}
+			continue
+		}
+
+		result := s.results[i]
+		duration := result.Duration.Round(time.Millisecond).String()
+		switch result.Status {
+		case stepPassed:
+			rows = append(rows, successStyle.Render(fmt.Sprintf("  ✓ %-30s %8s", result.Name, duration))+"  "+dimStyle.Render(result.Detail))
+		case stepFailed:
+			rows = append(rows, errorStyle.Render(fmt.Sprintf("  ✗ %-30s %8s  %v", result.Name, duration, result.Err)))
+		default:
+			rows = append(rows, dimStyle.Render(fmt.Sprintf("  - %-30s %8s", result.Name, "skipped")))
+		}
+	}
+
+	if s.runner == nil && len(s.results) > 0 {
+		rows = append(rows, fieldStyle.Render(fmt.Sprintf("%s in %s", scenarioSummary(s.results), s.elapsed.Round(time.Millisecond))))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestModel_Scenario_RequiresConnection(t *testing.T) {
+	m := initialModel(&Config{Topic: "orders"})
+	m.width = 120
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF12})
+	if m.currentView == scenarioView || !strings.Contains(m.statusMessage, "connect to Kafka first") {
+		t.Errorf("Expected the scenario view to require a connection, got view %d (%s)", m.currentView, m.statusMessage)
+	}
+}
+
+func TestModel_Scenario_Run(t *testing.T) {
+	broker := newMockCluster(t, "orders")
+	file := filepath.Join(t.TempDir(), "flow.yaml")
+	data := "name: flow\nsteps:\n  - send: {key: k, value: {id: 1}}\n  - set: {a: b}\n  - sleep: 1ms\n"
+	if err := os.WriteFile(file, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(&Config{Brokers: []string{broker.Addr()}, Topic: "orders"})
+	m.width = 120
+	m.producer = &KafkaProducer{producer: &mockSyncProducer{}, config: m.config}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF12})
+	if m.currentView != scenarioView || !m.scenario.pathInput.Focused() {
+		t.Fatalf("Expected the scenario view with a focused path input, got view %d", m.currentView)
+	}
+	if !strings.Contains(m.View(), "No scenario run yet") {
+		t.Error("Expected an empty scenario view")
+	}
+
+	m, _ = pressKeys(t, m, runes(file))
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected a command starting the scenario")
+	}
+
+	// Deliver the start, every step and the end of the run
+	for cmd != nil {
+		newModel, next := m.Update(cmd())
+		m, cmd = newModel.(model), next
+	}
+
+	if m.scenario.runner != nil || len(m.scenario.results) != 3 {
+		t.Fatalf("Expected a finished run with 3 results, got %+v", m.scenario.results)
+	}
+	view := m.View()
+	for _, want := range []string{"flow", "✓ send to orders", "orders/0@0", "✓ set a", "3 passed"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the scenario view, got:\n%s", want, view)
+		}
+	}
+	if !strings.Contains(m.statusMessage, "flow finished: 3 passed") {
+		t.Errorf("Expected summary status, got %q", m.statusMessage)
+	}
+}
+
+func TestModel_Scenario_LoadError(t *testing.T) {
+	m := initialModel(&Config{Topic: "orders"})
+	m.width = 120
+	m.producer = &KafkaProducer{producer: &mockSyncProducer{}, config: m.config}
+	m.openScenario()
+
+	m, cmd := pressKeys(t, m, runes(filepath.Join(t.TempDir(), "missing.yaml")), tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+	if m.scenario.runner != nil || !strings.Contains(m.statusMessage, "no such file") {
+		t.Errorf("Expected a load error, got %q", m.statusMessage)
+	}
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/IBM/sarama"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -29,8 +31,8 @@ func TestInitialModel(t *testing.T) {
 		t.Errorf("Expected configView, got %v", m.currentView)
 	}
 
-	if len(m.configInputs) != 7 {
-		t.Errorf("Expected 7 config inputs, got %d", len(m.configInputs))
+	if len(m.configInputs) != 21 {
+		t.Errorf("Expected 21 config inputs, got %d", len(m.configInputs))
 	}
 
 	if m.configFocus != 0 {
@@ -318,6 +320,9 @@ func TestModel_Update_MessageResult_Error(t *testing.T) {
 	if !strings.Contains(msg.Status, "Failed") {
 		t.Errorf("Expected status to contain 'Failed', got %s", msg.Status)
 	}
+	if updatedModel.statusMessage != "Error: Send failed" {
+		t.Errorf("Expected the error in the status bar, got %q", updatedModel.statusMessage)
+	}
 
 	// Check that inputs were NOT cleared on failure
 	if updatedModel.messageKeyInput.Value() == "" {
@@ -478,7 +483,9 @@ func TestModel_MessageHistory_Display(t *testing.T) {
 
 func TestModel_RenderConfigView_MTLSBadge(t *testing.T) {
 	config := &Config{
-		UseAuth: true,
+		CertFile: "/certs/client.pem",
+		KeyFile:  "/certs/client.key",
+		UseAuth:  true,
 	}
 	m := initialModel(config)
 	m.width = 100
@@ -486,7 +493,120 @@ func TestModel_RenderConfigView_MTLSBadge(t *testing.T) {
 	view := m.renderConfigView()
 
 	if !strings.Contains(view, "mTLS Enabled") {
-		t.Error("Expected 'mTLS Enabled' badge when UseAuth is true")
+		t.Error("Expected 'mTLS Enabled' badge with a client certificate")
+	}
+
+	// The badge follows the inputs, not the saved flag
+	m.configInputs[certField].SetValue("")
+	m.configInputs[keyField].SetValue("")
+	if view := m.renderConfigView(); strings.Contains(view, "mTLS Enabled") {
+		t.Error("Expected no 'mTLS Enabled' badge once the certificate is cleared")
+	}
+}
+
+func TestModel_RenderConfigView_TLSBadge(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  string
+		ca    string
+		badge string
+	}{
+		{name: "disabled", badge: "TLS Disabled"},
+		{name: "CA only", ca: "/path/to/ca.pem", badge: "TLS Enabled (server only)"},
+		{name: "system pool", mode: "on", badge: "TLS Enabled (server only)"},
+		{name: "insecure", mode: "Insecure", badge: "TLS INSECURE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := initialModel(&Config{})
+			m.width = 100
+			m.configInputs[tlsModeField].SetValue(tt.mode)
+			m.configInputs[caField].SetValue(tt.ca)
+
+			if view := m.renderConfigView(); !strings.Contains(view, tt.badge) {
+				t.Errorf("Expected %q badge, got:\n%s", tt.badge, view)
+			}
+		})
+	}
+}
+
+func TestModel_RenderConfigView_SASLBadge(t *testing.T) {
+	m := initialModel(&Config{SASLMechanism: "oauthbearer"})
+	m.width = 100
+
+	if view := m.renderConfigView(); !strings.Contains(view, "SASL OAUTHBEARER") {
+		t.Errorf("Expected SASL badge, got:\n%s", view)
+	}
+}
+
+func TestModel_ConfigView_KerberosFields(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 100
+	m.currentView = configView
+	m.configFocus = int(saslMechanismField)
+	press := func(key tea.KeyType) {
+		updated, _ := m.Update(tea.KeyMsg{Type: key})
+		m = updated.(model)
+	}
+
+	if view := m.renderConfigView(); strings.Contains(view, "Kerberos Realm") {
+		t.Error("Expected Kerberos fields to be hidden without GSSAPI")
+	}
+	press(tea.KeyTab)
+	if m.configFocus != int(keySerdeField) {
+		t.Errorf("Expected tab to skip the hidden Kerberos fields, got focus %d", m.configFocus)
+	}
+	press(tea.KeyShiftTab)
+	if m.configFocus != int(saslMechanismField) {
+		t.Errorf("Expected shift+tab to skip back to the SASL mechanism, got focus %d", m.configFocus)
+	}
+
+	m.configInputs[saslMechanismField].SetValue("gssapi")
+	if view := m.renderConfigView(); !strings.Contains(view, "Kerberos Realm") {
+		t.Error("Expected Kerberos fields for GSSAPI")
+	}
+	press(tea.KeyTab)
+	if m.configFocus != int(kerberosUsernameField) {
+		t.Errorf("Expected tab to move to the Kerberos username, got focus %d", m.configFocus)
+	}
+
+	m.configInputs[kerberosUsernameField].SetValue("svc-producer@CORP.EXAMPLE.COM")
+	m.configInputs[kerberosKeytabField].SetValue("/etc/security/producer.keytab")
+	m.applyConfigInputs()
+	if username, realm := m.config.kerberosPrincipal(); username != "svc-producer" || realm != "CORP.EXAMPLE.COM" {
+		t.Errorf("Expected principal svc-producer@CORP.EXAMPLE.COM, got %s@%s", username, realm)
+	}
+	if m.config.KerberosKeytabPath != "/etc/security/producer.keytab" {
+		t.Errorf("Expected the keytab path, got %q", m.config.KerberosKeytabPath)
+	}
+}
+
+func TestModel_ApplyConfigInputs_TLS(t *testing.T) {
+	m := initialModel(&Config{})
+	m.configInputs[tlsModeField].SetValue("insecure")
+	m.configInputs[tlsServerNameField].SetValue(" kafka.internal ")
+	m.applyConfigInputs()
+
+	if m.config.UseAuth {
+		t.Error("Expected UseAuth to be false without a client certificate")
+	}
+	if !m.config.UseTLS || !m.config.TLSInsecureSkipVerify {
+		t.Errorf("Expected insecure TLS, got UseTLS=%v insecure=%v", m.config.UseTLS, m.config.TLSInsecureSkipVerify)
+	}
+	if m.config.TLSServerName != "kafka.internal" {
+		t.Errorf("Expected server name 'kafka.internal', got %q", m.config.TLSServerName)
+	}
+
+	reloaded := initialModel(m.config)
+	if got := reloaded.configInputs[tlsModeField].Value(); got != "insecure" {
+		t.Errorf("Expected TLS mode input 'insecure', got %q", got)
+	}
+
+	m.configInputs[tlsModeField].SetValue("maybe")
+	m.validateInputs()
+	if !hasIssue(m.validation, fieldTLS, "unknown mode") {
+		t.Errorf("Expected unknown TLS mode issue, got %v", m.validation)
 	}
 }
 
@@ -590,6 +710,7 @@ func TestModel_Connect_Success(t *testing.T) {
 	homeDir := t.TempDir()
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	defer os.Setenv("HOME", origHome)
 
 	m := initialModel(&Config{
@@ -620,6 +741,7 @@ func TestModel_SaveConfig_Execute(t *testing.T) {
 	homeDir := t.TempDir()
 	origHome := os.Getenv("HOME")
 	os.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", "")
 	defer os.Setenv("HOME", origHome)
 
 	m := initialModel(&Config{
@@ -648,6 +770,12 @@ func TestModel_SaveConfig_Execute(t *testing.T) {
 	} else if !strings.Contains(successMsg.msg, "saved") {
 		t.Errorf("Expected 'saved' in message, got %s", successMsg.msg)
 	}
+
+	// A typed-in password is dropped, and the status says so
+	m.configInputs[keyPasswordField].SetValue("typed-in")
+	if msg, ok := m.saveConfig()().(successMsg); !ok || !strings.Contains(msg.msg, "without key_password") {
+		t.Errorf("Expected the status to mention the dropped password, got %v", msg)
+	}
 }
 
 func TestModel_SendMessage_NotConnected(t *testing.T) {
@@ -772,3 +900,263 @@ func TestErrMsg_Error(t *testing.T) {
 		t.Errorf("Expected 'test error', got %s", err.Error())
 	}
 }
+
+func TestModel_Update_F5_InvalidConfig(t *testing.T) {
+	m := initialModel(&Config{Topic: "test-topic"})
+	m.configInputs[brokerField].SetValue("localhost")
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF5})
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+
+	if cmd != nil {
+		t.Error("Expected no connect command for invalid config")
+	}
+
+	if !updatedModel.validation.HasErrors() {
+		t.Error("Expected validation errors to be stored on the model")
+	}
+
+	if !strings.Contains(updatedModel.statusMessage, "error") {
+		t.Errorf("Expected validation error in status, got %s", updatedModel.statusMessage)
+	}
+}
+
+func TestModel_RenderConfigView_ValidationIssues(t *testing.T) {
+	m := initialModel(&Config{})
+	m.width = 100
+	m.validation = ValidationResult{
+		{Field: fieldBrokers, Message: "broker \"localhost\": missing port"},
+		{Field: fieldKeyFile, Message: "private key is accessible by other users", Warning: true},
+	}
+
+	view := m.renderConfigView()
+
+	if !strings.Contains(view, "missing port") {
+		t.Error("Expected broker error to be rendered inline")
+	}
+
+	if !strings.Contains(view, "accessible by other users") {
+		t.Error("Expected key file warning to be rendered inline")
+	}
+}
+
+func TestModel_ReplyModeToggle(t *testing.T) {
+	m := initialModel(&Config{Topic: "requests"})
+	m.width = 120
+	m.currentView = messageView
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.awaitReply || !strings.Contains(m.statusMessage, "Set a reply topic") {
+		t.Errorf("Expected reply mode to require a reply topic, got %v (%s)", m.awaitReply, m.statusMessage)
+	}
+
+	m.config.ReplyTopic = "replies"
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !m.awaitReply || !strings.Contains(m.statusMessage, "Awaiting replies on replies (correlation-id header)") {
+		t.Errorf("Expected reply mode to be enabled, got %v (%s)", m.awaitReply, m.statusMessage)
+	}
+	if !strings.Contains(m.View(), "⇄ reply: replies") {
+		t.Error("Expected reply badge in the message view")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.awaitReply {
+		t.Error("Expected reply mode to be disabled again")
+	}
+}
+
+func TestModel_Update_MessageResult_AwaitsReply(t *testing.T) {
+	m := initialModel(&Config{Topic: "requests", ReplyTopic: "replies"})
+	m.width = 120
+	m.currentView = messageView
+	m.messageValueArea.SetValue(`{"ping":true}`)
+
+	newModel, cmd := m.Update(messageResult{partition: 0, offset: 7, watch: &ReplyWatch{id: "abc-123"}})
+	m = newModel.(model)
+	if cmd == nil {
+		t.Fatal("Expected a command waiting for the reply")
+	}
+	if len(m.messages) != 1 || !m.messages[0].AwaitingReply || m.messages[0].CorrelationID != "abc-123" {
+		t.Fatalf("Expected a request awaiting its reply, got %+v", m.messages)
+	}
+	if !strings.Contains(m.View(), "awaiting reply (abc-123)") {
+		t.Error("Expected awaiting reply line in history")
+	}
+
+	reply := ConsumedMessage{Partition: 1, Offset: 42, Value: []byte(`{"pong":true}`)}
+	newModel, _ = m.Update(replyMsg{index: 0, reply: reply, latency: 1234 * time.Microsecond})
+	m = newModel.(model)
+
+	sent := m.messages[0]
+	if sent.AwaitingReply || sent.Reply == nil || sent.ReplyLatency != 1234*time.Microsecond {
+		t.Errorf("Expected the reply to be recorded, got %+v", sent)
+	}
+	view := m.View()
+	if !strings.Contains(view, "1ms") || !strings.Contains(view, `P:1 O:42 │ {"pong":true}`) {
+		t.Errorf("Expected reply with latency next to the request, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_ReplyTimeout(t *testing.T) {
+	m := initialModel(&Config{Topic: "requests", ReplyTopic: "replies"})
+	m.width = 120
+	m.currentView = messageView
+	m.messages = []Message{{Timestamp: time.Now(), Status: "Success", CorrelationID: "abc", AwaitingReply: true}}
+
+	newModel, _ := m.Update(replyMsg{index: 0, err: fmt.Errorf("no reply with correlation-id abc on replies within 30s")})
+	m = newModel.(model)
+
+	if m.messages[0].AwaitingReply || m.messages[0].ReplyError == "" {
+		t.Errorf("Expected the timeout to be recorded, got %+v", m.messages[0])
+	}
+	if !strings.Contains(m.View(), "✗ no reply with correlation-id abc") {
+		t.Error("Expected timeout in history")
+	}
+
+	// Replies for unknown history entries are ignored
+	if _, cmd := m.Update(replyMsg{index: 5}); cmd != nil {
+		t.Error("Expected no command for an unknown history entry")
+	}
+}
+
+func TestModel_VerifyToggle(t *testing.T) {
+	m := initialModel(&Config{Topic: "events"})
+	m.width = 120
+	m.currentView = messageView
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlO})
+	if !m.verify {
+		t.Fatal("Expected verify mode to be enabled")
+	}
+	if !strings.Contains(m.View(), "✓ verify") {
+		t.Error("Expected verify badge in the message view")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.verify {
+		t.Error("Expected verify mode to be disabled again")
+	}
+}
+
+func TestModel_Update_MessageResult_Verifies(t *testing.T) {
+	broker := newFetchCluster(t)
+	m := initialModel(&Config{Brokers: []string{broker.Addr()}, Topic: "events"})
+	m.width = 120
+	m.currentView = messageView
+	m.verify = true
+
+	sent := OutgoingMessage{Topic: "events", Key: []byte("key-2"), Value: []byte(`{"n":2}`), Partition: -1}
+	newModel, cmd := m.Update(messageResult{partition: 0, offset: 2, sent: &sent})
+	m = newModel.(model)
+	if cmd == nil {
+		t.Fatal("Expected a command reading the message back")
+	}
+	if !m.messages[0].Verifying || !strings.Contains(m.View(), "verifying…") {
+		t.Error("Expected the message to be marked as verifying")
+	}
+
+	newModel, _ = m.Update(cmd())
+	m = newModel.(model)
+	if !m.messages[0].Verified || m.messages[0].Verifying {
+		t.Errorf("Expected the message to be verified, got %+v", m.messages[0])
+	}
+	if !strings.Contains(m.View(), "✓ verified") {
+		t.Error("Expected verified line in history")
+	}
+}
+
+func TestModel_Update_VerifyMismatch(t *testing.T) {
+	m := initialModel(&Config{Topic: "events"})
+	m.width = 120
+	m.currentView = messageView
+	m.messages = []Message{{Timestamp: time.Now(), Status: "Success", Offset: 4, Verifying: true}}
+
+	newModel, _ := m.Update(verifyMsg{index: 0, mismatches: []string{"value is 8 bytes, sent 7"}})
+	m = newModel.(model)
+
+	if m.messages[0].Verified || len(m.messages[0].VerifyMismatch) != 1 {
+		t.Errorf("Expected the mismatch to be recorded, got %+v", m.messages[0])
+	}
+	if !strings.Contains(m.View(), "✗ mismatch: value is 8 bytes, sent 7") {
+		t.Error("Expected mismatch in history")
+	}
+	if !strings.Contains(m.statusMessage, "O:4 differs") {
+		t.Errorf("Expected mismatch status, got %q", m.statusMessage)
+	}
+}
+
+func TestModel_SendMessage_FanOut(t *testing.T) {
+	config := &Config{Topic: "orders", FanOut: []FanOutTarget{{Topic: "orders"}, {Topic: "missing"}, {Topic: "audit"}}}
+	m := initialModel(config)
+	m.width = 120
+	m.currentView = messageView
+	m.producer = &KafkaProducer{
+		producer: &mockSyncProducer{sendMessageFunc: func(msg *sarama.ProducerMessage) (int32, int64, error) {
+			if msg.Topic == "missing" {
+				return 0, 0, sarama.ErrUnknownTopicOrPartition
+			}
+			return 0, 3, nil
+		}},
+		config: config,
+	}
+	m.messageKeyInput.SetValue("order-1")
+	m.messageValueArea.SetValue(`{"n":1}`)
+
+	if !strings.Contains(m.View(), "orders, missing, audit") {
+		t.Error("Expected the fan-out topics in the title")
+	}
+
+	newModel, _ := m.Update(m.sendMessage()())
+	m = newModel.(model)
+
+	if len(m.messages) != 3 {
+		t.Fatalf("Expected one history row per topic, got %d", len(m.messages))
+	}
+	for i, topic := range []string{"orders", "missing", "audit"} {
+		if m.messages[i].Topic != topic {
+			t.Errorf("Expected row %d for %s, got %s", i, topic, m.messages[i].Topic)
+		}
+	}
+	if m.messages[0].Status != "Success" || !strings.HasPrefix(m.messages[1].Status, "Failed") {
+		t.Errorf("Expected per-topic statuses, got %q and %q", m.messages[0].Status, m.messages[1].Status)
+	}
+	if !strings.HasPrefix(m.statusMessage, "Sent to 2 of 3 topics; missing:") {
+		t.Errorf("Expected a partial failure status, got %q", m.statusMessage)
+	}
+	if m.messageValueArea.Value() == "" {
+		t.Error("Expected the value to be kept after a failed topic")
+	}
+
+	m.awaitReply = true
+	if msg, ok := m.sendMessage()().(errMsg); !ok || !strings.Contains(msg.Error(), "fan-out") {
+		t.Errorf("Expected reply mode to be refused with fan-out topics, got %v", msg)
+	}
+}
+
+func TestModel_View_TopicSerdes(t *testing.T) {
+	config := &Config{
+		Topic:       "orders.eu",
+		KeySerde:    serdeJSON,
+		ValueSerde:  serdeJSON,
+		TopicSerdes: []TopicSerde{{Pattern: "orders.*", ValueSerde: serdeString}, {Pattern: "bad", KeySerde: "avro"}},
+	}
+	m := initialModel(config)
+	m.width = 120
+	m.validateInputs()
+
+	view := m.View()
+	if !strings.Contains(view, "orders.* matches: key json, value string") {
+		t.Error("Expected the matching mapping under the topic field")
+	}
+	if !strings.Contains(view, `topic_serdes: unknown serde "avro"`) {
+		t.Error("Expected the topic_serdes issue on the config view")
+	}
+
+	m.currentView = messageView
+	if !strings.Contains(m.View(), "key: json │ value: string") {
+		t.Error("Expected the effective serdes in the message view title")
+	}
+}
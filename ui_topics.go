@@ -0,0 +1,459 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Topic admin screen modes
+type topicAdminMode int
+
+const (
+	topicListMode topicAdminMode = iota
+	topicCreateMode
+	topicPartitionsMode
+	topicConfigMode
+	topicDeleteMode
+	topicTruncateMode
+)
+
+// maxVisibleTopics limits the topic list height on the topic admin screen
+const maxVisibleTopics = 12
+
+// topicAdminState holds the state of the topic administration screen
+type topicAdminState struct {
+	mode     topicAdminMode
+	topics   []TopicSummary
+	selected int
+	target   string
+	configs  []TopicConfigEntry
+	// watermarks are shown while the truncate form is open, truncated
+	// holds the result of the last truncation
+	watermarks []PartitionWatermarks
+	truncated  []TruncateResult
+	inputs     []textinput.Model
+	labels     []string
+	focus      int
+}
+
+type topicsLoadedMsg struct {
+	topics []TopicSummary
+}
+
+type topicConfigLoadedMsg struct {
+	configs []TopicConfigEntry
+	status  string
+}
+
+type topicAdminDoneMsg struct {
+	status string
+}
+
+type topicWatermarksMsg struct {
+	topic      string
+	watermarks []PartitionWatermarks
+}
+
+type topicTruncatedMsg struct {
+	status  string
+	results []TruncateResult
+}
+
+// selectedTopic returns the highlighted topic or nil if the list is empty
+func (s *topicAdminState) selectedTopic() *TopicSummary {
+	if s.selected < 0 || s.selected >= len(s.topics) {
+		return nil
+	}
+	return &s.topics[s.selected]
+}
+
+// openForm replaces the current inputs with a new form and focuses its first field
+func (s *topicAdminState) openForm(mode topicAdminMode, target string, labels []string, inputs []textinput.Model) {
+	s.mode = mode
+	s.target = target
+	s.labels = labels
+	s.inputs = inputs
+	s.focus = 0
+	s.truncated = nil
+	if len(s.inputs) > 0 {
+		s.inputs[0].Focus()
+	}
+}
+
+func (s *topicAdminState) closeForm() {
+	s.mode = topicListMode
+	s.target = ""
+	s.labels = nil
+	s.inputs = nil
+	s.configs = nil
+	s.watermarks = nil
+	s.focus = 0
+}
+
+func newFormInput(placeholder, value string) textinput.Model {
+	input := textinput.New()
+	input.Placeholder = placeholder
+	input.SetValue(value)
+	input.Width = 60
+	return input
+}
+
+// openTopicAdmin switches to the topic admin screen and loads the topic list
+func (m *model) openTopicAdmin() tea.Cmd {
+	m.blurInputs()
+	m.setView(topicAdminView)
+	m.topicAdmin.closeForm()
+	return m.loadTopics()
+}
+
+func (m *model) loadTopics() tea.Cmd {
+	admin := m.admin
+	return func() tea.Msg {
+		topics, err := admin.ListTopics()
+		if err != nil {
+			return errMsg{err}
+		}
+		return topicsLoadedMsg{topics: topics}
+	}
+}
+
+// updateTopicAdmin handles keys on the topic admin screen. Keys it does not
+// handle (global commands, Esc in the list) fall through to the global handlers.
+func (m *model) updateTopicAdmin(msg tea.KeyMsg) (tea.Cmd, bool) {
+	s := &m.topicAdmin
+
+	if s.mode == topicListMode {
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			if s.selected > 0 {
+				s.selected--
+			}
+			return nil, true
+		case key.Matches(msg, m.keys.Down):
+			if s.selected < len(s.topics)-1 {
+				s.selected++
+			}
+			return nil, true
+		case key.Matches(msg, m.keys.Refresh):
+			return m.loadTopics(), true
+		case key.Matches(msg, m.keys.CreateTopic):
+			s.openForm(topicCreateMode, "",
+				[]string{"Topic Name", "Partitions", "Replication Factor", "Configs (key=value, space-separated)"},
+				[]textinput.Model{
+					newFormInput("my-new-topic", ""),
+					newFormInput("1", "1"),
+					newFormInput("1", "1"),
+					newFormInput("cleanup.policy=compact retention.ms=604800000", ""),
+				})
+			return nil, true
+		}
+
+		topic := s.selectedTopic()
+		if topic == nil {
+			return nil, false
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.TopicConfigs):
+			s.openForm(topicConfigMode, topic.Name,
+				[]string{"Set configs (key=value, key= resets to default)"},
+				[]textinput.Model{newFormInput("retention.ms=86400000", "")})
+			return m.loadTopicConfig(topic.Name, ""), true
+		case key.Matches(msg, m.keys.AddPartitions):
+			s.openForm(topicPartitionsMode, topic.Name,
+				[]string{"New total partition count"},
+				[]textinput.Model{newFormInput(strconv.Itoa(int(topic.Partitions)), strconv.Itoa(int(topic.Partitions)))})
+			return nil, true
+		case key.Matches(msg, m.keys.DeleteTopic):
+			s.openForm(topicDeleteMode, topic.Name,
+				[]string{fmt.Sprintf("Type %q to confirm deletion", topic.Name)},
+				[]textinput.Model{newFormInput(topic.Name, "")})
+			return nil, true
+		case key.Matches(msg, m.keys.TruncateTopic):
+			s.openForm(topicTruncateMode, topic.Name,
+				[]string{"Partitions (comma-separated, empty for all)", "Delete records before offset (empty for all)", fmt.Sprintf("Type %q to confirm", topic.Name)},
+				[]textinput.Model{newFormInput("0,1,2", ""), newFormInput("all", ""), newFormInput(topic.Name, "")})
+			return m.loadWatermarks(topic.Name), true
+		}
+		return nil, false
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		s.closeForm()
+		return nil, true
+	case key.Matches(msg, m.keys.NextField, m.keys.PrevField):
+		s.inputs[s.focus].Blur()
+		if key.Matches(msg, m.keys.NextField) {
+			s.focus = (s.focus + 1) % len(s.inputs)
+		} else {
+			s.focus = (s.focus - 1 + len(s.inputs)) % len(s.inputs)
+		}
+		s.inputs[s.focus].Focus()
+		return nil, true
+	case key.Matches(msg, m.keys.Apply):
+		return m.submitTopicForm(), true
+	}
+
+	if m.keys.leavesForm(msg) {
+		return nil, false
+	}
+
+	var cmd tea.Cmd
+	s.inputs[s.focus], cmd = s.inputs[s.focus].Update(msg)
+	return cmd, true
+}
+
+func (m *model) loadTopicConfig(topic, status string) tea.Cmd {
+	admin := m.admin
+	return func() tea.Msg {
+		configs, err := admin.TopicConfig(topic)
+		if err != nil {
+			return errMsg{err}
+		}
+		return topicConfigLoadedMsg{configs: configs, status: status}
+	}
+}
+
+func (m *model) loadWatermarks(topic string) tea.Cmd {
+	admin := m.admin
+	return func() tea.Msg {
+		watermarks, err := admin.Watermarks(topic, nil)
+		if err != nil {
+			return errMsg{err}
+		}
+		return topicWatermarksMsg{topic: topic, watermarks: watermarks}
+	}
+}
+
+// submitTopicForm validates the open form and returns the admin command to run
+func (m *model) submitTopicForm() tea.Cmd {
+	s := &m.topicAdmin
+	admin := m.admin
+	values := make([]string, len(s.inputs))
+	for i, input := range s.inputs {
+		values[i] = strings.TrimSpace(input.Value())
+	}
+
+	switch s.mode {
+	case topicCreateMode:
+		name := values[0]
+		if name == "" {
+			m.statusMessage = "Topic name is required"
+			return nil
+		}
+		partitions, err := strconv.ParseInt(values[1], 10, 32)
+		if err != nil || partitions < 1 {
+			m.statusMessage = "Partitions must be a positive number"
+			return nil
+		}
+		replication, err := strconv.ParseInt(values[2], 10, 16)
+		if err != nil || replication < 1 {
+			m.statusMessage = "Replication factor must be a positive number"
+			return nil
+		}
+		configs, err := parseConfigPairs(strings.Fields(values[3]))
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", err)
+			return nil
+		}
+		return func() tea.Msg {
+			if err := admin.CreateTopic(name, int32(partitions), int16(replication), configs); err != nil {
+				return errMsg{err}
+			}
+			return topicAdminDoneMsg{status: fmt.Sprintf("Created topic %q", name)}
+		}
+
+	case topicPartitionsMode:
+		name := s.target
+		total, err := strconv.ParseInt(values[0], 10, 32)
+		if err != nil || total < 1 {
+			m.statusMessage = "Partition count must be a positive number"
+			return nil
+		}
+		return func() tea.Msg {
+			if err := admin.AddPartitions(name, int32(total)); err != nil {
+				return errMsg{err}
+			}
+			return topicAdminDoneMsg{status: fmt.Sprintf("Topic %q now has %d partitions", name, total)}
+		}
+
+	case topicConfigMode:
+		changes, err := parseConfigPairs(strings.Fields(values[0]))
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", err)
+			return nil
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		name := s.target
+		s.inputs[0].SetValue("")
+		reload := m.loadTopicConfig(name, fmt.Sprintf("Updated %d config(s) of %q", len(changes), name))
+		return func() tea.Msg {
+			if err := admin.AlterTopicConfig(name, changes); err != nil {
+				return errMsg{err}
+			}
+			return reload()
+		}
+
+	case topicDeleteMode:
+		name := s.target
+		if values[0] != name {
+			m.statusMessage = "Confirmation does not match the topic name"
+			return nil
+		}
+		return func() tea.Msg {
+			if err := admin.DeleteTopic(name); err != nil {
+				return errMsg{err}
+			}
+			return topicAdminDoneMsg{status: fmt.Sprintf("Deleted topic %q", name)}
+		}
+
+	case topicTruncateMode:
+		name := s.target
+		partitions, err := parsePartitionList(values[0])
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", err)
+			return nil
+		}
+		before := int64(-1)
+		if values[1] != "" {
+			if before, err = strconv.ParseInt(values[1], 10, 64); err != nil || before < 0 {
+				m.statusMessage = "Offset must be a non-negative number"
+				return nil
+			}
+		}
+		if values[2] != name {
+			m.statusMessage = "Confirmation does not match the topic name"
+			return nil
+		}
+		return func() tea.Msg {
+			results, err := admin.TruncateTopic(name, partitions, before)
+			if err != nil {
+				return errMsg{err}
+			}
+			return topicTruncatedMsg{status: fmt.Sprintf("Truncated %d partition(s) of %q", len(results), name), results: results}
+		}
+	}
+
+	return nil
+}
+
+func (m model) renderTopicAdminView() string {
+	s := m.topicAdmin
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#7C3AED", Dark: "#A78BFA"}).
+		Background(lipgloss.AdaptiveColor{Light: "#F3F4F6", Dark: "#1F2937"}).
+		Padding(1, 2).
+		MarginBottom(1)
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#6B7280", Dark: "#9CA3AF"}).
+		MarginTop(1)
+
+	focusedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true).
+		MarginTop(1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#2563EB", Dark: "#60A5FA"}).
+		Bold(true)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9CA3AF", Dark: "#6B7280"})
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#DC2626", Dark: "#FCA5A5"})
+
+	var rows []string
+	rows = append(rows, titleStyle.Render("󰓩 Topic Administration"))
+
+	if len(s.topics) == 0 {
+		rows = append(rows, dimStyle.Italic(true).Render("  No topics loaded"))
+	}
+
+	start := 0
+	if s.selected >= maxVisibleTopics {
+		start = s.selected - maxVisibleTopics + 1
+	}
+	end := min(start+maxVisibleTopics, len(s.topics))
+	for i := start; i < end; i++ {
+		topic := s.topics[i]
+		line := fmt.Sprintf("%-40s P:%-4d RF:%d", topic.Name, topic.Partitions, topic.ReplicationFactor)
+		if topic.Name == m.config.Topic {
+			line += " ● current"
+		}
+		if i == s.selected {
+			rows = append(rows, selectedStyle.Render("› "+line))
+		} else {
+			rows = append(rows, "  "+line)
+		}
+	}
+	if len(s.topics) > maxVisibleTopics {
+		rows = append(rows, dimStyle.Render(fmt.Sprintf("  %d of %d topics", end-start, len(s.topics))))
+	}
+
+	if s.mode == topicConfigMode {
+		rows = append(rows, "", fieldStyle.Render(fmt.Sprintf("󰒓 Configs of %s", s.target)))
+		for _, entry := range s.configs {
+			value := entry.Value
+			if entry.Sensitive {
+				value = "(sensitive)"
+			}
+			line := fmt.Sprintf("  %-40s %s", entry.Name, value)
+			if entry.Default {
+				rows = append(rows, dimStyle.Render(line))
+			} else {
+				rows = append(rows, line)
+			}
+		}
+	}
+
+	if s.mode == topicDeleteMode {
+		rows = append(rows, "", warnStyle.Render("⚠ This permanently deletes the topic and all of its data"))
+	}
+
+	if s.mode == topicTruncateMode {
+		rows = append(rows, "", fieldStyle.Render(fmt.Sprintf("󰆴 Truncate %s", s.target)))
+		rows = append(rows, dimStyle.Render(fmt.Sprintf("  %-10s %12s %12s %12s", "PARTITION", "LOW", "HIGH", "RECORDS")))
+		for _, mark := range s.watermarks {
+			rows = append(rows, fmt.Sprintf("  %-10d %12d %12d %12d", mark.Partition, mark.Low, mark.High, mark.High-mark.Low))
+		}
+		rows = append(rows, warnStyle.Render("⚠ Deleted records cannot be recovered"))
+	}
+
+	if len(s.truncated) > 0 {
+		rows = append(rows, "", fieldStyle.Render("Truncation result:"))
+		rows = append(rows, dimStyle.Render(fmt.Sprintf("  %-10s %12s %12s %12s", "PARTITION", "LOW BEFORE", "LOW AFTER", "HIGH")))
+		for _, result := range s.truncated {
+			rows = append(rows, fmt.Sprintf("  %-10d %12d %12d %12d", result.Partition, result.LowBefore, result.LowAfter, result.High))
+		}
+	}
+
+	for i, input := range s.inputs {
+		if i == s.focus {
+			rows = append(rows, focusedStyle.Render(s.labels[i]+" ›"))
+		} else {
+			rows = append(rows, fieldStyle.Render(s.labels[i]+":"))
+		}
+		rows = append(rows, input.View())
+	}
+
+	var help string
+	if s.mode == topicListMode {
+		help = hintView(m.keys.Up, m.keys.Down, m.keys.CreateTopic, m.keys.AddPartitions, m.keys.TopicConfigs,
+			m.keys.DeleteTopic, m.keys.TruncateTopic, m.keys.Refresh, m.keys.back())
+	} else {
+		help = hintView(m.keys.Apply, m.keys.NextField, m.keys.Cancel)
+	}
+	rows = append(rows, "", dimStyle.Render("  "+help))
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
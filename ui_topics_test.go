@@ -0,0 +1,229 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// topicAdminModel returns a connected model on the topic admin screen
+func topicAdminModel(t *testing.T, topics ...string) model {
+	t.Helper()
+
+	broker := newMockCluster(t, topics...)
+	m := initialModel(&Config{Topic: "orders"})
+	m.width = 120
+	m.connected = true
+	m.admin = newTestAdmin(t, broker)
+
+	cmd := m.openTopicAdmin()
+	newModel, _ := m.Update(cmd())
+	updatedModel, ok := newModel.(model)
+	if !ok {
+		t.Fatal("type assertion failed")
+	}
+	return updatedModel
+}
+
+func pressKeys(t *testing.T, m model, keys ...tea.KeyMsg) (model, tea.Cmd) {
+	t.Helper()
+
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var newModel tea.Model
+		newModel, cmd = m.Update(key)
+		var ok bool
+		if m, ok = newModel.(model); !ok {
+			t.Fatal("type assertion failed")
+		}
+	}
+	return m, cmd
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestModel_Update_F4_NotConnected(t *testing.T) {
+	m := initialModel(&Config{})
+
+	updatedModel, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyF4})
+
+	if cmd != nil {
+		t.Error("Expected no command when not connected")
+	}
+	if updatedModel.currentView == topicAdminView {
+		t.Error("Expected to stay out of the topic admin view")
+	}
+	if !strings.Contains(updatedModel.statusMessage, "connect") {
+		t.Errorf("Expected connection warning in status, got: %s", updatedModel.statusMessage)
+	}
+}
+
+func TestModel_TopicAdmin_ListNavigation(t *testing.T) {
+	m := topicAdminModel(t, "payments", "orders", "audit")
+
+	if m.currentView != topicAdminView {
+		t.Fatalf("Expected topic admin view, got %v", m.currentView)
+	}
+	if len(m.topicAdmin.topics) != 3 || m.topicAdmin.topics[0].Name != "audit" {
+		t.Fatalf("Expected 3 sorted topics, got %+v", m.topicAdmin.topics)
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyDown}, runes("j"), runes("j"))
+	if m.topicAdmin.selected != 2 {
+		t.Errorf("Expected selection to stop at the last topic, got %d", m.topicAdmin.selected)
+	}
+
+	m, _ = pressKeys(t, m, runes("k"))
+	if name := m.topicAdmin.selectedTopic().Name; name != "orders" {
+		t.Errorf("Expected orders to be selected, got %s", name)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Topic Administration") || !strings.Contains(view, "● current") {
+		t.Error("Expected topic list with the current topic marked")
+	}
+}
+
+func TestModel_TopicAdmin_CreateForm(t *testing.T) {
+	m := topicAdminModel(t, "orders")
+
+	m, _ = pressKeys(t, m, runes("c"))
+	if m.topicAdmin.mode != topicCreateMode {
+		t.Fatalf("Expected create mode, got %v", m.topicAdmin.mode)
+	}
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected no command without a topic name")
+	}
+	if !strings.Contains(m.statusMessage, "Topic name is required") {
+		t.Errorf("Expected validation message, got: %s", m.statusMessage)
+	}
+
+	m, cmd = pressKeys(t, m, runes("new-topic"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected create command")
+	}
+
+	newModel, reload := m.Update(cmd())
+	m = newModel.(model)
+	if m.topicAdmin.mode != topicListMode {
+		t.Error("Expected form to close after creating the topic")
+	}
+	if !strings.Contains(m.statusMessage, `Created topic "new-topic"`) {
+		t.Errorf("Expected success status, got: %s", m.statusMessage)
+	}
+	if reload == nil {
+		t.Error("Expected topic list reload")
+	}
+}
+
+func TestModel_TopicAdmin_FormFunctionKeys(t *testing.T) {
+	m := topicAdminModel(t, "orders")
+
+	m, _ = pressKeys(t, m, runes("c"), tea.KeyMsg{Type: tea.KeyF2})
+	if m.currentView != configView {
+		t.Errorf("Expected F2 to leave an open form, got view %v", m.currentView)
+	}
+}
+
+func TestModel_TopicAdmin_DeleteConfirmation(t *testing.T) {
+	m := topicAdminModel(t, "orders")
+
+	m, _ = pressKeys(t, m, runes("d"))
+	if m.topicAdmin.mode != topicDeleteMode || m.topicAdmin.target != "orders" {
+		t.Fatalf("Expected delete form for orders, got mode %v target %q", m.topicAdmin.mode, m.topicAdmin.target)
+	}
+	if !strings.Contains(m.View(), "permanently deletes") {
+		t.Error("Expected deletion warning in view")
+	}
+
+	m, cmd := pressKeys(t, m, runes("order"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil {
+		t.Error("Expected no command when confirmation does not match")
+	}
+	if !strings.Contains(m.statusMessage, "does not match") {
+		t.Errorf("Expected mismatch status, got: %s", m.statusMessage)
+	}
+
+	_, cmd = pressKeys(t, m, runes("s"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected delete command")
+	}
+	if msg, ok := cmd().(topicAdminDoneMsg); !ok || !strings.Contains(msg.status, "Deleted") {
+		t.Errorf("Expected topicAdminDoneMsg, got %#v", msg)
+	}
+}
+
+func TestModel_TopicAdmin_ConfigForm(t *testing.T) {
+	m := topicAdminModel(t, "orders")
+
+	m, cmd := pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.topicAdmin.mode != topicConfigMode || cmd == nil {
+		t.Fatal("Expected config form with a load command")
+	}
+
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+	if len(m.topicAdmin.configs) == 0 {
+		t.Fatal("Expected topic configs to be loaded")
+	}
+	if !strings.Contains(m.View(), "retention.ms") {
+		t.Error("Expected configs in view")
+	}
+
+	m, _ = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.topicAdmin.mode != topicListMode || m.topicAdmin.configs != nil {
+		t.Error("Expected Esc to close the config form")
+	}
+	if m.currentView != topicAdminView {
+		t.Error("Expected to stay on the topic admin screen")
+	}
+}
+
+func TestModel_TopicAdmin_Truncate(t *testing.T) {
+	m := initialModel(&Config{Topic: "orders"})
+	m.width = 120
+	m.connected = true
+	m.admin = newTestAdmin(t, newGroupsCluster(t))
+	m.topicAdmin.topics = []TopicSummary{{Name: "orders", Partitions: 2, ReplicationFactor: 1}}
+	m.currentView = topicAdminView
+
+	m, cmd := pressKeys(t, m, runes("t"))
+	if m.topicAdmin.mode != topicTruncateMode || cmd == nil {
+		t.Fatal("Expected truncate form with a watermarks command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(model)
+	if len(m.topicAdmin.watermarks) != 2 {
+		t.Fatalf("Expected watermarks of 2 partitions, got %d", len(m.topicAdmin.watermarks))
+	}
+	if !strings.Contains(m.View(), "cannot be recovered") {
+		t.Error("Expected truncation warning in view")
+	}
+
+	m, cmd = pressKeys(t, m, runes("0"), tea.KeyMsg{Type: tea.KeyTab}, runes("30"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !strings.Contains(m.statusMessage, "does not match") {
+		t.Fatalf("Expected confirmation error, got: %s", m.statusMessage)
+	}
+
+	m, cmd = pressKeys(t, m, tea.KeyMsg{Type: tea.KeyTab}, runes("orders"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Expected truncate command")
+	}
+	newModel, _ = m.Update(cmd())
+	m = newModel.(model)
+
+	if m.topicAdmin.mode != topicListMode {
+		t.Error("Expected form to close after truncating")
+	}
+	if len(m.topicAdmin.truncated) != 1 || m.topicAdmin.truncated[0].LowBefore != 10 {
+		t.Errorf("Expected result for partition 0, got %+v", m.topicAdmin.truncated)
+	}
+	if !strings.Contains(m.View(), "LOW AFTER") {
+		t.Error("Expected truncation result in view")
+	}
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	krb5config "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+)
+
+// Config field names used in validation results (match the JSON tags)
+const (
+	fieldBrokers     = "brokers"
+	fieldTopic       = "topic"
+	fieldCertFile    = "cert_file"
+	fieldKeyFile     = "key_file"
+	fieldCAFile      = "ca_file"
+	fieldKeyPassword = "key_password"
+	fieldTLS         = "tls"
+	fieldTLSServer   = "tls_server_name"
+	fieldKeySerde    = "key_serde"
+	fieldValueSerde  = "value_serde"
+
+	fieldReplyTopic        = "reply_topic"
+	fieldCorrelationHeader = "correlation_header"
+	fieldReplyTimeout      = "reply_timeout"
+
+	fieldSASLMechanism     = "sasl_mechanism"
+	fieldOAuthTokenURL     = "oauth_token_url"
+	fieldOAuthClientID     = "oauth_client_id"
+	fieldOAuthClientSecret = "oauth_client_secret"
+
+	fieldKerberosUsername = "kerberos_username"
+	fieldKerberosRealm    = "kerberos_realm"
+	fieldKerberosPassword = "kerberos_password"
+	fieldKerberosKeytab   = "kerberos_keytab_path"
+	fieldKerberosConfig   = "kerberos_config_path"
+	fieldKerberosService  = "kerberos_service_name"
+
+	fieldFanOut          = "fan_out"
+	fieldTopicSerdes     = "topic_serdes"
+	fieldTransformScript = "transform_script"
+	fieldKeymap          = "keymap"
+)
+
+// certExpiryWarning is how long before expiry a certificate starts producing warnings
+const certExpiryWarning = 14 * 24 * time.Hour
+
+// knownSerdes lists the serde names understood by the producer
+var knownSerdes = []string{serdeString, serdeJSON, serdeByteArray}
+
+var topicNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// ValidationIssue describes a single problem found in a Config field
+type ValidationIssue struct {
+	Field   string
+	Message string
+	Warning bool
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// ValidationResult holds all issues found by ValidateConfig
+type ValidationResult []ValidationIssue
+
+// HasErrors reports whether the result contains anything other than warnings
+func (r ValidationResult) HasErrors() bool {
+	return r.ErrorCount() > 0
+}
+
+// ErrorCount returns the number of issues that are not warnings
+func (r ValidationResult) ErrorCount() int {
+	count := 0
+	for _, issue := range r {
+		if !issue.Warning {
+			count++
+		}
+	}
+	return count
+}
+
+// ForField returns the issues reported for the given field
+func (r ValidationResult) ForField(field string) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, issue := range r {
+		if issue.Field == field {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+type validator struct {
+	result ValidationResult
+}
+
+func (v *validator) errorf(field, format string, args ...interface{}) {
+	v.result = append(v.result, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(field, format string, args ...interface{}) {
+	v.result = append(v.result, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// ValidateConfig checks the configuration for problems that would otherwise
+// only surface as terse sarama errors when connecting
+func ValidateConfig(config *Config) ValidationResult {
+	v := &validator{}
+
+	v.validateBrokers(config.Brokers)
+	v.validateTopic(config.Topic)
+	v.validateSerde(fieldKeySerde, config.KeySerde)
+	v.validateSerde(fieldValueSerde, config.ValueSerde)
+	v.validateTLSFiles(config)
+	v.validateTLSOptions(config)
+	v.validateSASL(config)
+	v.validateReply(config)
+	v.validateFanOut(config.FanOut)
+	v.validateTopicSerdes(config.TopicSerdes)
+	v.validateTransform(config.TransformScript)
+	v.validateKeymap(config.Keymap)
+
+	for _, field := range config.plainSecrets() {
+		v.warnf(field, "plain secrets are not saved and will be missing after a restart, use a reference such as env:VAR, file:/path, cmd:... or keyring:service/user")
+	}
+
+	return v.result
+}
+
+func (v *validator) validateBrokers(brokers []string) {
+	if len(brokers) == 0 || (len(brokers) == 1 && strings.TrimSpace(brokers[0]) == "") {
+		v.errorf(fieldBrokers, "at least one broker is required")
+		return
+	}
+
+	for _, broker := range brokers {
+		broker = strings.TrimSpace(broker)
+		if broker == "" {
+			v.errorf(fieldBrokers, "empty broker address (check for stray commas)")
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(broker)
+		if err != nil {
+			if strings.Contains(err.Error(), "missing port") {
+				v.errorf(fieldBrokers, "broker %q: missing port (expected host:port)", broker)
+			} else {
+				v.errorf(fieldBrokers, "broker %q: invalid address (expected host:port)", broker)
+			}
+			continue
+		}
+
+		if host == "" {
+			v.errorf(fieldBrokers, "broker %q: missing host", broker)
+		}
+
+		portNum, err := strconv.Atoi(port)
+		if err != nil || portNum < 1 || portNum > 65535 {
+			v.errorf(fieldBrokers, "broker %q: port must be a number between 1 and 65535", broker)
+		}
+	}
+}
+
+func (v *validator) validateTopic(topic string) {
+	switch {
+	case topic == "":
+		v.errorf(fieldTopic, "topic is required")
+	case topic == "." || topic == "..":
+		v.errorf(fieldTopic, "topic cannot be %q", topic)
+	case len(topic) > 249:
+		v.errorf(fieldTopic, "topic name is longer than 249 characters")
+	case !topicNamePattern.MatchString(topic):
+		v.errorf(fieldTopic, "topic may only contain ASCII letters, digits, '.', '_' and '-'")
+	}
+}
+
+func (v *validator) validateReply(config *Config) {
+	if config.ReplyTopic != "" {
+		if !topicNamePattern.MatchString(config.ReplyTopic) {
+			v.errorf(fieldReplyTopic, "topic may only contain ASCII letters, digits, '.', '_' and '-'")
+		} else if config.ReplyTopic == config.Topic {
+			// The request itself carries the correlation header
+			v.errorf(fieldReplyTopic, "reply topic must differ from the request topic")
+		}
+	}
+
+	if strings.TrimSpace(config.CorrelationHeader) != config.CorrelationHeader {
+		v.errorf(fieldCorrelationHeader, "header name must not start or end with spaces")
+	}
+
+	if config.ReplyTimeout != "" {
+		timeout, err := time.ParseDuration(config.ReplyTimeout)
+		if err != nil || timeout <= 0 {
+			v.errorf(fieldReplyTimeout, "invalid timeout %q (expected a duration like 30s or 1m)", config.ReplyTimeout)
+		}
+	}
+}
+
+func (v *validator) validateFanOut(targets []FanOutTarget) {
+	seen := map[string]bool{}
+	for _, target := range targets {
+		switch {
+		case !topicNamePattern.MatchString(target.Topic):
+			v.errorf(fieldFanOut, "topic %q may only contain ASCII letters, digits, '.', '_' and '-'", target.Topic)
+		case seen[target.Topic]:
+			v.errorf(fieldFanOut, "topic %q is listed twice", target.Topic)
+		}
+		seen[target.Topic] = true
+
+		v.validateSerde(fieldFanOut, target.KeySerde)
+		v.validateSerde(fieldFanOut, target.ValueSerde)
+		if _, err := target.render("", "", ""); err != nil {
+			v.errorf(fieldFanOut, "value template of %s: %v", target.Topic, err)
+		}
+	}
+}
+
+func (v *validator) validateTopicSerdes(mappings []TopicSerde) {
+	for _, mapping := range mappings {
+		if expr, ok := strings.CutPrefix(mapping.Pattern, "/"); ok && strings.HasSuffix(expr, "/") {
+			if _, err := regexp.Compile(strings.TrimSuffix(expr, "/")); err != nil {
+				v.errorf(fieldTopicSerdes, "invalid regular expression %s: %v", mapping.Pattern, err)
+			}
+		} else if _, err := path.Match(mapping.Pattern, ""); err != nil || mapping.Pattern == "" {
+			v.errorf(fieldTopicSerdes, "invalid pattern %q (expected a topic name, a glob or /regexp/)", mapping.Pattern)
+		}
+
+		v.validateSerde(fieldTopicSerdes, mapping.KeySerde)
+		v.validateSerde(fieldTopicSerdes, mapping.ValueSerde)
+	}
+}
+
+func (v *validator) validateTransform(script string) {
+	if script == "" {
+		return
+	}
+	if _, err := LoadTransformer(script); err != nil {
+		v.errorf(fieldTransformScript, "%v", err)
+	}
+}
+
+func (v *validator) validateKeymap(overrides map[string][]string) {
+	names := keyActionNames()
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		if !slices.Contains(names, name) {
+			v.errorf(fieldKeymap, "unknown action %q (expected one of %s)", name, strings.Join(names, ", "))
+		}
+	}
+
+	keys := newKeyMap(overrides)
+	boundTo := make(map[string]string)
+	for _, action := range keys.actions() {
+		if !action.binding.Enabled() {
+			if action.binding == &keys.Quit {
+				v.errorf(fieldKeymap, "quit must have at least one key")
+			}
+			continue
+		}
+		for _, name := range action.binding.Keys() {
+			switch {
+			case name == "":
+				v.errorf(fieldKeymap, "%s has an empty key", action.name)
+				continue
+			case action.screen:
+				continue // checked per sub-screen below
+			case utf8.RuneCountInString(name) == 1:
+				v.warnf(fieldKeymap, "%s is bound to %q, which can no longer be typed into input fields", action.name, name)
+			}
+			if other, ok := boundTo[name]; ok {
+				v.errorf(fieldKeymap, "key %q is bound to both %s and %s", name, other, action.name)
+			}
+			boundTo[name] = action.name
+		}
+	}
+
+	// The global commands work on every sub-screen as well
+	for _, screen := range keyScreens {
+		boundTo := make(map[string]keyAction)
+		for _, action := range keys.actions() {
+			if !action.command && !slices.Contains(screen.actions, action.name) {
+				continue
+			}
+			for _, name := range action.binding.Keys() {
+				if name == "" || !action.binding.Enabled() {
+					continue
+				}
+				if action.screen && screen.inputs && utf8.RuneCountInString(name) == 1 {
+					v.warnf(fieldKeymap, "%s is bound to %q, which can no longer be typed into input fields", action.name, name)
+				}
+				// Two global actions are reported above
+				if other, ok := boundTo[name]; ok && (other.screen || action.screen) {
+					v.errorf(fieldKeymap, "key %q is bound to both %s and %s on the %s screen", name, other.name, action.name, screen.name)
+				}
+				boundTo[name] = action
+			}
+		}
+	}
+}
+
+func (v *validator) validateSerde(field, serde string) {
+	if serde == "" {
+		return // empty means the default serde
+	}
+	for _, known := range knownSerdes {
+		if serde == known {
+			return
+		}
+	}
+	v.errorf(field, "unknown serde %q (expected one of %s)", serde, strings.Join(knownSerdes, ", "))
+}
+
+func (v *validator) validateTLSFiles(config *Config) {
+	files := []struct {
+		field string
+		path  string
+	}{
+		{fieldCertFile, config.CertFile},
+		{fieldKeyFile, config.KeyFile},
+		{fieldCAFile, config.CAFile},
+	}
+
+	// The CA is optional: without it the system cert pool is used
+	keystore := config.CertFile != "" && isKeystore(config.CertFile)
+	switch {
+	case config.CertFile == "" && (config.KeyFile != "" || config.UseAuth):
+		v.errorf(fieldCertFile, "required for mTLS (the client key needs its certificate)")
+	case config.CertFile != "" && config.KeyFile == "" && !keystore:
+		v.errorf(fieldKeyFile, "required for mTLS (the client certificate needs its key)")
+	case config.CertFile != "" && !config.UseAuth:
+		v.errorf(fieldCertFile, "use_auth is off, so the client certificate would not be presented")
+	}
+	if config.CAFile != "" && !config.tlsEnabled() {
+		v.errorf(fieldCAFile, "TLS is disabled, set use_tls to verify the brokers with this CA")
+	}
+
+	readable := make(map[string]bool)
+	for _, f := range files {
+		if f.path != "" {
+			readable[f.field] = v.checkFile(f.field, f.path)
+		}
+	}
+
+	if keystore {
+		if readable[fieldCertFile] {
+			v.checkKeyPermissions(fieldCertFile, config.CertFile)
+			v.checkKeystore(config)
+		}
+	} else {
+		if readable[fieldKeyFile] {
+			v.checkKeyPermissions(fieldKeyFile, config.KeyFile)
+		}
+
+		var leaf *x509.Certificate
+		if readable[fieldCertFile] {
+			leaf = v.checkCertificates(fieldCertFile, config.CertFile)
+		}
+
+		if leaf != nil && readable[fieldKeyFile] {
+			if password, ok := v.keyPassword(config); ok {
+				if _, err := loadClientCertificate(config.CertFile, config.KeyFile, password); err != nil {
+					v.keyError(config, err)
+				}
+			}
+		}
+	}
+
+	if readable[fieldCAFile] {
+		data, err := os.ReadFile(config.CAFile)
+		if err == nil && certificateFormat(data) != formatPEM {
+			v.checkTruststore(config)
+		} else {
+			v.checkCertificates(fieldCAFile, config.CAFile)
+		}
+	}
+}
+
+// validateTLSOptions checks the settings that apply to TLS with and without
+// a client certificate
+func (v *validator) validateTLSOptions(config *Config) {
+	if config.TLSServerName != "" {
+		if !config.tlsEnabled() {
+			v.warnf(fieldTLSServer, "has no effect while TLS is disabled")
+		} else if strings.ContainsAny(config.TLSServerName, ":/ ") {
+			v.errorf(fieldTLSServer, "%q must be a host name without port or scheme", config.TLSServerName)
+		}
+	}
+	if config.TLSInsecureSkipVerify {
+		if !config.tlsEnabled() {
+			v.warnf(fieldTLS, "tls_insecure_skip_verify has no effect while TLS is disabled")
+		} else {
+			v.warnf(fieldTLS, "certificate verification is disabled, anyone on the network can impersonate the brokers; use it only with dev clusters")
+		}
+	}
+}
+
+// validateSASL checks the SASL mechanism and its credentials
+func (v *validator) validateSASL(config *Config) {
+	mechanism := config.saslMechanism()
+	switch mechanism {
+	case "":
+		return
+	case saslOAuthBearer:
+		v.validateOAuth(config)
+	case saslGSSAPI:
+		v.validateKerberos(config)
+	default:
+		v.errorf(fieldSASLMechanism, "unknown mechanism %q, expected one of %s", config.SASLMechanism, strings.Join(saslMechanisms, ", "))
+		return
+	}
+
+	if !config.tlsEnabled() {
+		v.warnf(fieldSASLMechanism, "%s credentials are sent without TLS", mechanism)
+	}
+}
+
+// validateOAuth checks the client-credentials settings of OAUTHBEARER
+func (v *validator) validateOAuth(config *Config) {
+	if config.OAuthTokenURL == "" {
+		v.errorf(fieldOAuthTokenURL, "required for %s", saslOAuthBearer)
+	} else if u, err := url.Parse(config.OAuthTokenURL); err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		v.errorf(fieldOAuthTokenURL, "%q is not an http(s) URL", config.OAuthTokenURL)
+	} else if u.Scheme == "http" {
+		v.warnf(fieldOAuthTokenURL, "the client secret is sent unencrypted, use https")
+	}
+
+	if config.OAuthClientID == "" {
+		v.errorf(fieldOAuthClientID, "required for %s", saslOAuthBearer)
+	}
+	v.validateSecret(fieldOAuthClientSecret, config.OAuthClientSecret, true)
+}
+
+// validateKerberos checks the GSSAPI login: principal, krb5.conf and either
+// a keytab or a password
+func (v *validator) validateKerberos(config *Config) {
+	username, realm := config.kerberosPrincipal()
+	if username == "" {
+		v.errorf(fieldKerberosUsername, "required for %s", saslGSSAPI)
+	}
+	if realm == "" {
+		v.errorf(fieldKerberosRealm, "required for %s (or use user@REALM as username)", saslGSSAPI)
+	}
+	if strings.ContainsAny(config.KerberosServiceName, "/@ ") {
+		v.errorf(fieldKerberosService, "%q must be a bare service name such as kafka", config.KerberosServiceName)
+	}
+
+	krb5Path := config.kerberosConfigPath()
+	if v.checkFile(fieldKerberosConfig, krb5Path) {
+		if _, err := krb5config.Load(krb5Path); err != nil {
+			v.errorf(fieldKerberosConfig, "%s is not a valid krb5.conf: %v", krb5Path, err)
+		}
+	}
+
+	switch {
+	case config.KerberosKeytabPath != "":
+		if v.checkFile(fieldKerberosKeytab, config.KerberosKeytabPath) {
+			v.checkKeyPermissions(fieldKerberosKeytab, config.KerberosKeytabPath)
+			if _, err := keytab.Load(config.KerberosKeytabPath); err != nil {
+				v.errorf(fieldKerberosKeytab, "not a valid keytab: %v", err)
+			}
+		}
+		if config.KerberosPassword != "" {
+			v.warnf(fieldKerberosPassword, "ignored, the keytab is used to log in")
+		}
+	case config.KerberosPassword != "":
+		v.validateSecret(fieldKerberosPassword, config.KerberosPassword, true)
+	default:
+		v.errorf(fieldKerberosPassword, "set a keytab or a password for %s", saslGSSAPI)
+	}
+}
+
+// validateSecret checks a secret field. References are checked with
+// checkSecretRef and resolved only when connecting.
+func (v *validator) validateSecret(field, value string, required bool) {
+	switch {
+	case value == "":
+		if required {
+			v.errorf(field, "required, e.g. env:VAR, file:/path, cmd:... or keyring:service/user")
+		}
+	case isSecretRef(value):
+		if err := checkSecretRef(value); err != nil {
+			v.errorf(field, "%v", err)
+		}
+	}
+}
+
+// keyPassword resolves the key password, reporting references that cannot
+// be resolved on the password field. A cmd: or keyring: reference is only
+// checked, and the checks that need the password are skipped.
+func (v *validator) keyPassword(config *Config) (string, bool) {
+	if resolvedOnConnect(config.KeyPassword) {
+		if err := checkSecretRef(config.KeyPassword); err != nil {
+			v.reportSecret(fmt.Errorf("invalid key password: %w", err))
+		}
+		return "", false
+	}
+	password, err := config.keyPassword()
+	if err != nil {
+		v.reportSecret(err)
+		return "", false
+	}
+	return password, true
+}
+
+// reportSecret reports a key password reference that cannot be resolved,
+// once even when both the key and the truststore need it
+func (v *validator) reportSecret(err error) {
+	for _, issue := range v.result.ForField(fieldKeyPassword) {
+		if issue.Message == err.Error() {
+			return
+		}
+	}
+	v.errorf(fieldKeyPassword, "%v", err)
+}
+
+// keyError reports why the client key could not be loaded, pointing at the
+// password field when that is what is missing
+func (v *validator) keyError(config *Config, err error) {
+	switch {
+	case errors.Is(err, errPasswordRequired):
+		v.errorf(fieldKeyPassword, "the key is encrypted, enter its password or set $%s", config.keyPasswordEnv())
+	case isKeystore(config.CertFile):
+		v.errorf(fieldCertFile, "%v", err)
+	default:
+		v.errorf(fieldKeyFile, "cannot be used with the client certificate: %v", err)
+	}
+}
+
+// checkKeystore loads the client certificate from a PKCS#12 or JKS keystore
+func (v *validator) checkKeystore(config *Config) {
+	password, ok := v.keyPassword(config)
+	if !ok {
+		return
+	}
+	cert, err := loadClientCertificate(config.CertFile, "", password)
+	if err != nil {
+		v.keyError(config, err)
+		return
+	}
+	v.checkExpiry(fieldCertFile, []*x509.Certificate{cert.Leaf})
+}
+
+// checkTruststore loads the certificates of a PKCS#12 or JKS truststore
+func (v *validator) checkTruststore(config *Config) {
+	password, ok := os.Getenv(config.truststorePasswordEnv()), true
+	if password == "" {
+		password, ok = v.keyPassword(config)
+	}
+	if !ok {
+		return
+	}
+	if _, err := loadCACertificates(config.CAFile, password); err != nil {
+		v.errorf(fieldCAFile, "%v", err)
+	}
+}
+
+// checkFile verifies that path is an existing, readable regular file
+func (v *validator) checkFile(field, path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			v.errorf(field, "file does not exist: %s", path)
+		} else {
+			v.errorf(field, "cannot access file: %v", err)
+		}
+		return false
+	}
+
+	if info.IsDir() {
+		v.errorf(field, "%s is a directory, expected a file", path)
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsPermission(err) {
+			v.errorf(field, "file is not readable by the current user: %s", path)
+		} else {
+			v.errorf(field, "cannot open file: %v", err)
+		}
+		return false
+	}
+	_ = f.Close()
+
+	return true
+}
+
+func (v *validator) checkKeyPermissions(field, path string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		v.warnf(field, "private key is accessible by other users (mode %04o), consider chmod 600", perm)
+	}
+}
+
+// checkCertificates parses every PEM certificate in path and reports
+// expired or soon-to-expire ones. It returns the first certificate found.
+func (v *validator) checkCertificates(field, path string) *x509.Certificate {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		v.errorf(field, "cannot read file: %v", err)
+		return nil
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			v.errorf(field, "invalid certificate: %v", err)
+			return nil
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		v.errorf(field, "no PEM certificate found in %s", path)
+		return nil
+	}
+
+	v.checkExpiry(field, certs)
+	return certs[0]
+}
+
+// checkExpiry reports expired or soon-to-expire certificates
+func (v *validator) checkExpiry(field string, certs []*x509.Certificate) {
+	now := time.Now()
+	for _, cert := range certs {
+		name := cert.Subject.String()
+		if name == "" {
+			name = "certificate"
+		}
+
+		switch {
+		case now.After(cert.NotAfter):
+			v.errorf(field, "%s expired on %s", name, cert.NotAfter.Format(time.DateOnly))
+		case now.Before(cert.NotBefore):
+			v.errorf(field, "%s is not valid until %s", name, cert.NotBefore.Format(time.DateOnly))
+		case cert.NotAfter.Sub(now) < certExpiryWarning:
+			v.warnf(field, "%s expires on %s", name, cert.NotAfter.Format(time.DateOnly))
+		}
+	}
+
+}
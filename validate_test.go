@@ -0,0 +1,658 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// writeTestCertificate generates a self-signed certificate and key valid
+// between notBefore and notAfter and writes them as PEM files into dir
+func writeTestCertificate(t *testing.T, dir, name string, notBefore, notAfter time.Time) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+".key")
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+func hasIssue(result ValidationResult, field, substr string) bool {
+	for _, issue := range result.ForField(field) {
+		if strings.Contains(issue.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateConfig_Valid(t *testing.T) {
+	config := &Config{
+		Brokers:    []string{"localhost:9092", "broker-2.example.com:9093"},
+		Topic:      "orders.v1_test-topic",
+		KeySerde:   "string",
+		ValueSerde: "json",
+	}
+
+	result := ValidateConfig(config)
+	if len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_Brokers(t *testing.T) {
+	tests := []struct {
+		name    string
+		brokers []string
+		want    string
+	}{
+		{"no brokers", nil, "at least one broker"},
+		{"blank broker", []string{""}, "at least one broker"},
+		{"stray comma", []string{"localhost:9092", ""}, "stray commas"},
+		{"missing port", []string{"localhost"}, "missing port"},
+		{"non-numeric port", []string{"localhost:kafka"}, "port must be a number"},
+		{"port out of range", []string{"localhost:70000"}, "port must be a number"},
+		{"missing host", []string{":9092"}, "missing host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateConfig(&Config{Brokers: tt.brokers, Topic: testTopic})
+			if !hasIssue(result, fieldBrokers, tt.want) {
+				t.Errorf("Expected brokers issue containing %q, got %v", tt.want, result)
+			}
+			if !result.HasErrors() {
+				t.Error("Expected broker issue to be an error")
+			}
+		})
+	}
+}
+
+func TestValidateConfig_Topic(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{"", "required"},
+		{"..", "cannot be"},
+		{"bad topic", "may only contain"},
+		{strings.Repeat("a", 250), "longer than 249"},
+	}
+
+	for _, tt := range tests {
+		result := ValidateConfig(&Config{Brokers: []string{"localhost:9092"}, Topic: tt.topic})
+		if !hasIssue(result, fieldTopic, tt.want) {
+			t.Errorf("topic %q: expected issue containing %q, got %v", tt.topic, tt.want, result)
+		}
+	}
+}
+
+func TestValidateConfig_UnknownSerde(t *testing.T) {
+	config := &Config{
+		Brokers:    []string{"localhost:9092"},
+		Topic:      testTopic,
+		KeySerde:   "avro",
+		ValueSerde: "json",
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldKeySerde, `unknown serde "avro"`) {
+		t.Errorf("Expected unknown key serde issue, got %v", result)
+	}
+	if len(result.ForField(fieldValueSerde)) != 0 {
+		t.Errorf("Expected no value serde issues, got %v", result.ForField(fieldValueSerde))
+	}
+}
+
+func TestValidateConfig_MissingTLSFiles(t *testing.T) {
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: filepath.Join(t.TempDir(), "missing.pem"),
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldCertFile, "does not exist") {
+		t.Errorf("Expected missing cert file issue, got %v", result)
+	}
+	if !hasIssue(result, fieldKeyFile, "required for mTLS") {
+		t.Errorf("Expected key file required issue, got %v", result)
+	}
+	if len(result.ForField(fieldCAFile)) != 0 {
+		t.Errorf("Expected the CA file to be optional, got %v", result)
+	}
+}
+
+func TestValidateConfig_TLSOptions(t *testing.T) {
+	caFile, keyFile := writeTestCertificate(t, t.TempDir(), "ca", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+
+	tests := []struct {
+		name    string
+		config  Config
+		field   string
+		message string
+	}{
+		{name: "key without certificate", config: Config{KeyFile: keyFile}, field: fieldCertFile, message: "required for mTLS"},
+		{name: "certificate without key", config: Config{CertFile: caFile, UseAuth: true}, field: fieldKeyFile, message: "required for mTLS"},
+		{name: "certificate without use_auth", config: Config{CertFile: caFile, KeyFile: keyFile, UseTLS: true}, field: fieldCertFile, message: "would not be presented"},
+		{name: "CA without TLS", config: Config{CAFile: caFile}, field: fieldCAFile, message: "TLS is disabled"},
+		{name: "insecure", config: Config{UseTLS: true, TLSInsecureSkipVerify: true}, field: fieldTLS, message: "verification is disabled"},
+		{name: "insecure without TLS", config: Config{TLSInsecureSkipVerify: true}, field: fieldTLS, message: "no effect"},
+		{name: "server name with port", config: Config{UseTLS: true, TLSServerName: "kafka:9093"}, field: fieldTLSServer, message: "without port"},
+		{name: "server name without TLS", config: Config{TLSServerName: "kafka"}, field: fieldTLSServer, message: "no effect"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Brokers = []string{"localhost:9092"}
+			tt.config.Topic = testTopic
+			result := ValidateConfig(&tt.config)
+			if !hasIssue(result, tt.field, tt.message) {
+				t.Errorf("Expected %s issue containing %q, got %v", tt.field, tt.message, result)
+			}
+		})
+	}
+
+	t.Run("server only", func(t *testing.T) {
+		result := ValidateConfig(&Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, UseTLS: true, CAFile: caFile, TLSServerName: "kafka.internal"})
+		if len(result) != 0 {
+			t.Errorf("Expected server-only TLS to be valid, got %v", result)
+		}
+	})
+}
+
+func TestValidateConfig_ValidCertificates(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "client", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		CAFile:   certFile,
+		UseAuth:  true,
+	}
+
+	result := ValidateConfig(config)
+	if len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_ExpiredCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "expired", time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		UseAuth:  true,
+		KeyFile:  keyFile,
+		CAFile:   certFile,
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldCertFile, "expired on") {
+		t.Errorf("Expected expired certificate issue, got %v", result)
+	}
+}
+
+func TestValidateConfig_ExpiringSoonIsWarning(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "soon", time.Now().Add(-time.Hour), time.Now().Add(72*time.Hour))
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		UseAuth:  true,
+		KeyFile:  keyFile,
+		CAFile:   certFile,
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldCertFile, "expires on") {
+		t.Errorf("Expected expiry warning, got %v", result)
+	}
+	if result.HasErrors() {
+		t.Errorf("Expected only warnings, got %v", result)
+	}
+}
+
+func TestValidateConfig_KeyPairMismatch(t *testing.T) {
+	dir := t.TempDir()
+	validity := time.Now().Add(365 * 24 * time.Hour)
+	certFile, _ := writeTestCertificate(t, dir, "first", time.Now().Add(-time.Hour), validity)
+	_, otherKey := writeTestCertificate(t, dir, "second", time.Now().Add(-time.Hour), validity)
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		UseAuth:  true,
+		KeyFile:  otherKey,
+		CAFile:   certFile,
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldKeyFile, "cannot be used with the client certificate") {
+		t.Errorf("Expected key mismatch issue, got %v", result)
+	}
+}
+
+func TestValidateConfig_InvalidCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "client", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, []byte("invalid ca"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		UseAuth:  true,
+		KeyFile:  keyFile,
+		CAFile:   caFile,
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldCAFile, "no PEM certificate") {
+		t.Errorf("Expected invalid CA issue, got %v", result)
+	}
+}
+
+func TestValidateConfig_KeyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions are not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "client", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+	if err := os.Chmod(keyFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    testTopic,
+		CertFile: certFile,
+		UseAuth:  true,
+		KeyFile:  keyFile,
+		CAFile:   certFile,
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldKeyFile, "accessible by other users") {
+		t.Errorf("Expected key permission warning, got %v", result)
+	}
+	if result.HasErrors() {
+		t.Errorf("Expected permissions to only produce a warning, got %v", result)
+	}
+}
+
+func TestValidateConfig_Reply(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		field  string
+		issue  string
+	}{
+		{name: "same topic", config: Config{ReplyTopic: testTopic}, field: fieldReplyTopic, issue: "must differ from the request topic"},
+		{name: "invalid topic", config: Config{ReplyTopic: "bad topic"}, field: fieldReplyTopic, issue: "may only contain"},
+		{name: "header spaces", config: Config{CorrelationHeader: " id"}, field: fieldCorrelationHeader, issue: "spaces"},
+		{name: "invalid timeout", config: Config{ReplyTimeout: "soon"}, field: fieldReplyTimeout, issue: `invalid timeout "soon"`},
+		{name: "negative timeout", config: Config{ReplyTimeout: "-1s"}, field: fieldReplyTimeout, issue: "invalid timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			config.Brokers = []string{"localhost:9092"}
+			config.Topic = testTopic
+
+			result := ValidateConfig(&config)
+			if !hasIssue(result, tt.field, tt.issue) {
+				t.Errorf("Expected %s issue %q, got %v", tt.field, tt.issue, result)
+			}
+		})
+	}
+
+	valid := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, ReplyTopic: "replies", CorrelationHeader: "x-request-id", ReplyTimeout: "5s"}
+	if result := ValidateConfig(valid); len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_TransformScript(t *testing.T) {
+	config := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, TransformScript: writeScript(t, "def transform(msg)\n")}
+	if result := ValidateConfig(config); !hasIssue(result, fieldTransformScript, "want ':'") {
+		t.Errorf("Expected a transform_script syntax error, got %v", result)
+	}
+
+	config.TransformScript = writeScript(t, "def transform(msg):\n    pass\n")
+	if result := ValidateConfig(config); len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_FanOut(t *testing.T) {
+	tests := []struct {
+		name   string
+		target FanOutTarget
+		issue  string
+	}{
+		{name: "invalid topic", target: FanOutTarget{Topic: "bad topic"}, issue: "may only contain"},
+		{name: "duplicate topic", target: FanOutTarget{Topic: "audit"}, issue: `"audit" is listed twice`},
+		{name: "unknown serde", target: FanOutTarget{Topic: "other", ValueSerde: "avro"}, issue: `unknown serde "avro"`},
+		{name: "undefined variable", target: FanOutTarget{Topic: "other", ValueTemplate: "{{order}}"}, issue: `undefined variable "order"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, FanOut: []FanOutTarget{{Topic: "audit"}, tt.target}}
+			if result := ValidateConfig(config); !hasIssue(result, fieldFanOut, tt.issue) {
+				t.Errorf("Expected fan_out issue %q, got %v", tt.issue, result)
+			}
+		})
+	}
+
+	valid := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, FanOut: []FanOutTarget{
+		{Topic: "audit", KeySerde: serdeString, ValueTemplate: `{"id":"{{fanout_id}}","order":{{value}},"at":"{{now}}"}`},
+		{Topic: "orders"},
+	}}
+	if result := ValidateConfig(valid); len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_Keymap(t *testing.T) {
+	tests := []struct {
+		name    string
+		keymap  map[string][]string
+		issue   string
+		warning bool
+	}{
+		{name: "unknown action", keymap: map[string][]string{"launch": {"ctrl+l"}}, issue: `unknown action "launch"`},
+		{name: "conflict with a default", keymap: map[string][]string{"connect": {"f2"}}, issue: `key "f2" is bound to both switch and connect`},
+		{name: "quit unbound", keymap: map[string][]string{"quit": {}}, issue: "quit must have at least one key"},
+		{name: "empty key", keymap: map[string][]string{"save": {""}}, issue: "save has an empty key"},
+		{name: "printable key", keymap: map[string][]string{"save": {"s"}}, issue: "can no longer be typed", warning: true},
+		{name: "sub-screen conflict", keymap: map[string][]string{"refresh": {"c"}}, issue: `key "c" is bound to both refresh and create_topic on the topics screen`},
+		{name: "sub-screen shadows a command", keymap: map[string][]string{"clear": {"f5"}}, issue: `key "f5" is bound to both connect and clear on the consume screen`},
+		{name: "printable form key", keymap: map[string][]string{"cancel": {"q"}}, issue: `cancel is bound to "q"`, warning: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, Keymap: tt.keymap}
+			result := ValidateConfig(config)
+			if !hasIssue(result, fieldKeymap, tt.issue) {
+				t.Fatalf("Expected keymap issue %q, got %v", tt.issue, result)
+			}
+			if result.HasErrors() == tt.warning {
+				t.Errorf("Expected warning=%v, got %v", tt.warning, result)
+			}
+		})
+	}
+
+	valid := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, Keymap: map[string][]string{
+		"switch":  {"ctrl+n"},
+		"connect": {"ctrl+k", "f2"},
+		"format":  {},
+		"clear":   {"x"},
+	}}
+	if result := ValidateConfig(valid); len(result) != 0 {
+		t.Errorf("Expected no issues, got %v", result)
+	}
+}
+
+func TestValidateConfig_TopicSerdes(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping TopicSerde
+		issue   string
+	}{
+		{name: "empty pattern", mapping: TopicSerde{KeySerde: serdeString}, issue: "invalid pattern"},
+		{name: "invalid glob", mapping: TopicSerde{Pattern: "orders.[", KeySerde: serdeString}, issue: `invalid pattern "orders.["`},
+		{name: "invalid regexp", mapping: TopicSerde{Pattern: "/(orders/"}, issue: "invalid regular expression /(orders/"},
+		{name: "unknown serde", mapping: TopicSerde{Pattern: "orders", ValueSerde: "avro"}, issue: `unknown serde "avro"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, TopicSerdes: []TopicSerde{tt.mapping}}
+			if result := ValidateConfig(config); !hasIssue(result, fieldTopicSerdes, tt.issue) {
+				t.Errorf("Expected topic_serdes issue %q, got %v", tt.issue, result)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_Keystores(t *testing.T) {
+	key, cert := newTestIdentity(t)
+	p12, err := pkcs12.Modern.Encode(key, cert, nil, testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedDER, err := pkcs8.MarshalPrivateKey(key, []byte(testPassword), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := writeTestFile(t, "client.pem", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	encryptedKeyFile := writeTestFile(t, "client.key", pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encryptedDER}))
+	truststore := writeTestFile(t, "truststore.jks", encodeJKS(t, "changeit", nil, nil, []*x509.Certificate{cert}))
+
+	t.Setenv(defaultTruststorePasswordEnv, "changeit")
+
+	keystore := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, CertFile: writeTestFile(t, "client.p12", p12), CAFile: truststore, KeyPassword: testPassword, UseAuth: true}
+	if result := ValidateConfig(keystore); result.HasErrors() {
+		t.Errorf("Expected a PKCS#12 keystore without key file to be valid, got %v", result)
+	}
+
+	keystore.KeyPassword = "wrong"
+	if result := ValidateConfig(keystore); !hasIssue(result, fieldCertFile, "PKCS#12") {
+		t.Errorf("Expected a keystore password error, got %v", result)
+	}
+
+	encrypted := &Config{Brokers: []string{"localhost:9092"}, Topic: testTopic, CertFile: certFile, KeyFile: encryptedKeyFile, CAFile: truststore, UseAuth: true}
+	if result := ValidateConfig(encrypted); !hasIssue(result, fieldKeyPassword, "$"+defaultKeyPasswordEnv) {
+		t.Errorf("Expected a missing password issue, got %v", result)
+	}
+
+	t.Setenv(defaultKeyPasswordEnv, testPassword)
+	if result := ValidateConfig(encrypted); result.HasErrors() {
+		t.Errorf("Expected the password from the environment to be used, got %v", result)
+	}
+}
+
+func TestValidateConfig_UnresolvedKeyPassword(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), "client", time.Now().Add(-time.Hour), time.Now().Add(365*24*time.Hour))
+	config := &Config{
+		Brokers:     []string{"localhost:9092"},
+		Topic:       testTopic,
+		CertFile:    certFile,
+		KeyFile:     keyFile,
+		UseAuth:     true,
+		KeyPassword: "env:KAFKA_TEST_MISSING_PASSWORD",
+	}
+
+	result := ValidateConfig(config)
+	if !hasIssue(result, fieldKeyPassword, "KAFKA_TEST_MISSING_PASSWORD is not set") {
+		t.Errorf("Expected unresolved key password issue, got %v", result)
+	}
+
+	config.KeyPassword = "typed-in"
+	if result := ValidateConfig(config); !hasIssue(result, fieldKeyPassword, "not saved") || result.HasErrors() {
+		t.Errorf("Expected a warning that the plain password is not saved, got %v", result)
+	}
+}
+
+func TestValidateConfig_SASL(t *testing.T) {
+	oauth := Config{
+		SASLMechanism:     saslOAuthBearer,
+		UseTLS:            true,
+		OAuthTokenURL:     "https://auth.example.com/token",
+		OAuthClientID:     "producer",
+		OAuthClientSecret: "env:KAFKA_TEST_OAUTH_SECRET",
+	}
+	t.Setenv("KAFKA_TEST_OAUTH_SECRET", "s3cret")
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		field   string
+		message string
+	}{
+		{name: "unknown mechanism", modify: func(c *Config) { c.SASLMechanism = "NTLM" }, field: fieldSASLMechanism, message: "unknown mechanism"},
+		{name: "without TLS", modify: func(c *Config) { c.UseTLS = false }, field: fieldSASLMechanism, message: "without TLS"},
+		{name: "missing token URL", modify: func(c *Config) { c.OAuthTokenURL = "" }, field: fieldOAuthTokenURL, message: "required"},
+		{name: "invalid token URL", modify: func(c *Config) { c.OAuthTokenURL = "auth.example.com/token" }, field: fieldOAuthTokenURL, message: "not an http(s) URL"},
+		{name: "plain http token URL", modify: func(c *Config) { c.OAuthTokenURL = "http://auth.local/token" }, field: fieldOAuthTokenURL, message: "use https"},
+		{name: "missing client ID", modify: func(c *Config) { c.OAuthClientID = "" }, field: fieldOAuthClientID, message: "required"},
+		{name: "missing secret", modify: func(c *Config) { c.OAuthClientSecret = "" }, field: fieldOAuthClientSecret, message: "required"},
+		{name: "plain secret", modify: func(c *Config) { c.OAuthClientSecret = "s3cret" }, field: fieldOAuthClientSecret, message: "not saved"},
+		{name: "unresolved secret", modify: func(c *Config) { c.OAuthClientSecret = "env:KAFKA_TEST_MISSING_SECRET" }, field: fieldOAuthClientSecret, message: "is not set"},
+		{name: "empty command", modify: func(c *Config) { c.OAuthClientSecret = "cmd:" }, field: fieldOAuthClientSecret, message: "empty command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := oauth
+			config.Brokers = []string{"localhost:9092"}
+			config.Topic = testTopic
+			tt.modify(&config)
+
+			result := ValidateConfig(&config)
+			if !hasIssue(result, tt.field, tt.message) {
+				t.Errorf("Expected %s issue containing %q, got %v", tt.field, tt.message, result)
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		config := oauth
+		config.Brokers = []string{"localhost:9092"}
+		config.Topic = testTopic
+		if result := ValidateConfig(&config); len(result) != 0 {
+			t.Errorf("Expected no issues, got %v", result)
+		}
+	})
+}
+
+func TestValidateConfig_Kerberos(t *testing.T) {
+	dir := t.TempDir()
+	krb5Conf := writeTestFile(t, "krb5.conf", []byte("[libdefaults]\n  default_realm = CORP.EXAMPLE.COM\n"))
+	invalidKeytab := filepath.Join(dir, "invalid.keytab")
+	if err := os.WriteFile(invalidKeytab, []byte("not a keytab"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kt := keytab.New()
+	if err := kt.AddEntry("svc-producer", "CORP.EXAMPLE.COM", "s3cret", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96); err != nil {
+		t.Fatal(err)
+	}
+	data, err := kt.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keytabFile := filepath.Join(dir, "producer.keytab")
+	if err := os.WriteFile(keytabFile, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kerberos := Config{
+		Brokers:            []string{"localhost:9092"},
+		Topic:              testTopic,
+		UseTLS:             true,
+		SASLMechanism:      saslGSSAPI,
+		KerberosUsername:   "svc-producer@CORP.EXAMPLE.COM",
+		KerberosKeytabPath: keytabFile,
+		KerberosConfigPath: krb5Conf,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		field   string
+		message string
+	}{
+		{name: "missing username", modify: func(c *Config) { c.KerberosUsername = "" }, field: fieldKerberosUsername, message: "required"},
+		{name: "missing realm", modify: func(c *Config) { c.KerberosUsername = "svc-producer" }, field: fieldKerberosRealm, message: "required"},
+		{name: "missing krb5.conf", modify: func(c *Config) { c.KerberosConfigPath = filepath.Join(dir, "missing.conf") }, field: fieldKerberosConfig, message: "does not exist"},
+		{name: "invalid keytab", modify: func(c *Config) { c.KerberosKeytabPath = invalidKeytab }, field: fieldKerberosKeytab, message: "not a valid keytab"},
+		{name: "no credentials", modify: func(c *Config) { c.KerberosKeytabPath = "" }, field: fieldKerberosPassword, message: "set a keytab or a password"},
+		{name: "password ignored", modify: func(c *Config) { c.KerberosPassword = "env:KAFKA_TEST_KERBEROS_PASSWORD" }, field: fieldKerberosPassword, message: "ignored"},
+		{name: "service principal", modify: func(c *Config) { c.KerberosServiceName = "kafka/broker1" }, field: fieldKerberosService, message: "bare service name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := kerberos
+			tt.modify(&config)
+
+			result := ValidateConfig(&config)
+			if !hasIssue(result, tt.field, tt.message) {
+				t.Errorf("Expected %s issue containing %q, got %v", tt.field, tt.message, result)
+			}
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		config := kerberos
+		if result := ValidateConfig(&config); len(result) != 0 {
+			t.Errorf("Expected no issues, got %v", result)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// FetchRecord reads the single record at offset of a topic partition
+func (c *KafkaConsumer) FetchRecord(topic string, partition int32, offset int64) (ConsumedMessage, error) {
+	var record *ConsumedMessage
+	r := FetchRange{Partitions: []int32{partition}, StartOffset: offset, EndOffset: offset + 1}
+	err := c.Fetch(topic, r, func(msg ConsumedMessage) error {
+		if msg.Offset == offset {
+			record = &msg
+		}
+		return errStopFetch
+	})
+	if err != nil {
+		return ConsumedMessage{}, err
+	}
+	if record == nil {
+		return ConsumedMessage{}, fmt.Errorf("no record at %s/%d offset %d", topic, partition, offset)
+	}
+	return *record, nil
+}
+
+// VerifyRecord reads back the record that was sent to topic at
+// partition/offset and compares it with sent. It returns the differences,
+// none if key, value and headers match byte for byte.
+func VerifyRecord(consumer *KafkaConsumer, topic string, partition int32, offset int64, sent OutgoingMessage) ([]string, error) {
+	record, err := consumer.FetchRecord(topic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+	return compareRecord(sent, record), nil
+}
+
+// compareRecord lists the differences between a sent message and the record
+// read back from the broker
+func compareRecord(sent OutgoingMessage, got ConsumedMessage) []string {
+	var diffs []string
+
+	if diff := compareBytes("key", sent.Key, got.Key); diff != "" {
+		diffs = append(diffs, diff)
+	}
+	if diff := compareBytes("value", sent.Value, got.Value); diff != "" {
+		diffs = append(diffs, diff)
+	}
+
+	for i := 0; i < max(len(sent.Headers), len(got.Headers)); i++ {
+		switch {
+		case i >= len(got.Headers):
+			diffs = append(diffs, fmt.Sprintf("header %q missing", sent.Headers[i].Key))
+		case i >= len(sent.Headers):
+			diffs = append(diffs, fmt.Sprintf("unexpected header %q", got.Headers[i].Key))
+		case sent.Headers[i].Key != got.Headers[i].Key:
+			diffs = append(diffs, fmt.Sprintf("header %d is %q, sent %q", i+1, got.Headers[i].Key, sent.Headers[i].Key))
+		default:
+			name := fmt.Sprintf("header %q", sent.Headers[i].Key)
+			if diff := compareBytes(name, []byte(sent.Headers[i].Value), []byte(got.Headers[i].Value)); diff != "" {
+				diffs = append(diffs, diff)
+			}
+		}
+	}
+
+	return diffs
+}
+
+// compareBytes describes how got differs from sent, or returns "" if they
+// are identical. A null field differs from an empty one.
+func compareBytes(name string, sent, got []byte) string {
+	switch {
+	case sent == nil && got == nil:
+		return ""
+	case sent == nil:
+		return fmt.Sprintf("%s is %d bytes, sent null", name, len(got))
+	case got == nil:
+		return fmt.Sprintf("%s is null, sent %d bytes", name, len(sent))
+	case bytes.Equal(sent, got):
+		return ""
+	case len(sent) != len(got):
+		return fmt.Sprintf("%s is %d bytes, sent %d", name, len(got), len(sent))
+	}
+
+	for i := range sent {
+		if sent[i] != got[i] {
+			return fmt.Sprintf("%s differs at byte %d (0x%02x, sent 0x%02x)", name, i, got[i], sent[i])
+		}
+	}
+	return ""
+}
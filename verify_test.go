@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKafkaConsumer_FetchRecord(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	record, err := consumer.FetchRecord("events", 0, 2)
+	if err != nil {
+		t.Fatalf("FetchRecord() error = %v", err)
+	}
+	if record.Offset != 2 || string(record.Key) != "key-2" || string(record.Value) != `{"n":2}` {
+		t.Errorf("Expected key-2 {\"n\":2} at offset 2, got %+v", record)
+	}
+
+	if _, err := consumer.FetchRecord("events", 0, 9); err == nil || !strings.Contains(err.Error(), "no record at events/0 offset 9") {
+		t.Errorf("Expected missing record error, got %v", err)
+	}
+}
+
+func TestVerifyRecord(t *testing.T) {
+	consumer := newTestConsumer(t, newFetchCluster(t))
+
+	mismatches, err := VerifyRecord(consumer, "events", 0, 3, OutgoingMessage{Key: []byte("key-3"), Value: []byte(`{"n":3}`)})
+	if err != nil {
+		t.Fatalf("VerifyRecord() error = %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("Expected the record to match, got %v", mismatches)
+	}
+
+	mismatches, err = VerifyRecord(consumer, "events", 0, 3, OutgoingMessage{Key: []byte("key-3"), Value: []byte(`{"n":4}`)})
+	if err != nil {
+		t.Fatalf("VerifyRecord() error = %v", err)
+	}
+	if len(mismatches) != 1 || mismatches[0] != "value differs at byte 5 (0x33, sent 0x34)" {
+		t.Errorf("Expected a value mismatch, got %v", mismatches)
+	}
+}
+
+func TestCompareRecord(t *testing.T) {
+	tests := []struct {
+		name string
+		sent OutgoingMessage
+		got  ConsumedMessage
+		want []string
+	}{
+		{
+			name: "identical",
+			sent: OutgoingMessage{Key: []byte("k"), Value: []byte("v"), Headers: []MessageHeader{{Key: "h", Value: "1"}}},
+			got:  ConsumedMessage{Key: []byte("k"), Value: []byte("v"), Headers: []MessageHeader{{Key: "h", Value: "1"}}},
+		},
+		{
+			name: "null key",
+			sent: OutgoingMessage{Value: []byte("v")},
+			got:  ConsumedMessage{Key: []byte{}, Value: []byte("v")},
+			want: []string{"key is 0 bytes, sent null"},
+		},
+		{
+			name: "value length",
+			sent: OutgoingMessage{Value: []byte(`{"a":1}`)},
+			got:  ConsumedMessage{Value: []byte(`{"a": 1}`)},
+			want: []string{"value is 8 bytes, sent 7"},
+		},
+		{
+			name: "headers",
+			sent: OutgoingMessage{Value: []byte("v"), Headers: []MessageHeader{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}},
+			got:  ConsumedMessage{Value: []byte("v"), Headers: []MessageHeader{{Key: "a", Value: "x"}}},
+			want: []string{`header "a" differs at byte 0 (0x78, sent 0x31)`, `header "b" missing`},
+		},
+		{
+			name: "added header",
+			sent: OutgoingMessage{Value: []byte("v")},
+			got:  ConsumedMessage{Value: []byte("v"), Headers: []MessageHeader{{Key: "trace", Value: "1"}}},
+			want: []string{`unexpected header "trace"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := compareRecord(tt.sent, tt.got)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}